@@ -9,7 +9,9 @@ import (
 	"github.com/base/base-bench/benchmark/config"
 	"github.com/base/base-bench/benchmark/flags"
 	"github.com/base/base-bench/runner"
+	"github.com/base/base-bench/runner/export"
 	"github.com/base/base-bench/runner/importer"
+	"github.com/base/base-bench/runner/migrate"
 	"github.com/urfave/cli/v2"
 
 	opservice "github.com/ethereum-optimism/optimism/op-service"
@@ -46,6 +48,21 @@ func main() {
 			Description: "Import benchmark runs from local metadata.json or remote URL into existing output metadata.json. Use --src-tag and --dest-tag to apply tags to runs, or use interactive mode.",
 			ArgsUsage:   "[metadata-file-or-url]",
 		},
+		{
+			Name:        "export",
+			Flags:       cliapp.ProtectFlags(flags.ExportFlags),
+			Action:      ExportMain(Version),
+			Usage:       "export per-block metrics as a table",
+			Description: "Joins each run's test config in the output dir's metadata.json with its per-block metrics and writes one row per run, role and block.",
+		},
+		{
+			Name:        "migrate",
+			Flags:       cliapp.ProtectFlags(flags.MigrateFlags),
+			Action:      MigrateMain(Version),
+			Usage:       "split aggregate metadata into per-run metadata.json files",
+			Description: "Writes <output-dir>/<outputDir>/metadata.json for every run in the given aggregate metadata files (default: <output-dir>/metadata/*.json and <output-dir>/metadata.json).",
+			ArgsUsage:   "[metadata-file...]",
+		},
 	}
 	app.Flags = flags.Flags
 	app.Version = opservice.FormatVersion(Version, GitCommit, GitDate, "")
@@ -185,3 +202,52 @@ func ImportMain(version string) cli.ActionFunc {
 		return nil
 	}
 }
+
+func ExportMain(version string) cli.ActionFunc {
+	return func(cliCtx *cli.Context) error {
+		cfg := config.NewExportCmdConfig(cliCtx)
+		if err := cfg.Check(); err != nil {
+			return fmt.Errorf("invalid CLI flags: %w", err)
+		}
+
+		l := oplog.NewLogger(oplog.AppOut(cliCtx), oplog.DefaultCLIConfig())
+		oplog.SetGlobalLogHandler(l.Handler())
+
+		result, err := export.NewService(cfg, l).Export()
+		if err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+
+		fmt.Printf("✅ Export completed successfully!\n")
+		fmt.Printf("   • File: %s\n", result.File)
+		fmt.Printf("   • Runs: %d\n", result.Runs)
+		fmt.Printf("   • Rows: %d\n", result.Rows)
+		fmt.Printf("   • Columns: %d\n", result.Columns)
+
+		return nil
+	}
+}
+
+func MigrateMain(version string) cli.ActionFunc {
+	return func(cliCtx *cli.Context) error {
+		cfg := config.NewMigrateCmdConfig(cliCtx)
+		if err := cfg.Check(); err != nil {
+			return fmt.Errorf("invalid CLI flags: %w", err)
+		}
+
+		l := oplog.NewLogger(oplog.AppOut(cliCtx), oplog.DefaultCLIConfig())
+		oplog.SetGlobalLogHandler(l.Handler())
+
+		result, err := migrate.NewService(cfg, l).Migrate(cliCtx.Context)
+		if err != nil {
+			return fmt.Errorf("migrate failed: %w", err)
+		}
+
+		fmt.Printf("✅ Migration completed successfully!\n")
+		fmt.Printf("   • Written: %d runs\n", result.Written)
+		fmt.Printf("   • Already migrated: %d runs\n", result.Existing)
+		fmt.Printf("   • Skipped: %d runs\n", result.Skipped)
+
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/base/base-bench/benchmark/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// ExportFormatCSV writes one comma-separated row per run, role and block.
+const ExportFormatCSV = "csv"
+
+// ExportCmdConfig holds configuration for the export command
+type ExportCmdConfig struct {
+	outputDir string
+	file      string
+	format    string
+}
+
+// NewExportCmdConfig creates a new export command configuration from CLI context
+func NewExportCmdConfig(cliCtx *cli.Context) *ExportCmdConfig {
+	return &ExportCmdConfig{
+		outputDir: cliCtx.String(flags.OutputDirFlagName),
+		file:      cliCtx.String(flags.ExportFileFlagName),
+		format:    cliCtx.String(flags.ExportFormatFlagName),
+	}
+}
+
+// OutputDir returns the benchmark output directory to export
+func (c *ExportCmdConfig) OutputDir() string {
+	return c.outputDir
+}
+
+// File returns the file to write the export to
+func (c *ExportCmdConfig) File() string {
+	if c.file == "" {
+		return path.Join(c.outputDir, "metrics."+c.format)
+	}
+	return c.file
+}
+
+// Format returns the export format
+func (c *ExportCmdConfig) Format() string {
+	return c.format
+}
+
+// Check validates the export configuration
+func (c *ExportCmdConfig) Check() error {
+	if c.outputDir == "" {
+		return fmt.Errorf("output directory is required")
+	}
+	if c.format != ExportFormatCSV {
+		return fmt.Errorf("unsupported export format %q (supported: %s)", c.format, ExportFormatCSV)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/base/base-bench/benchmark/flags"
+	"github.com/urfave/cli/v2"
+)
+
+// LegacyMetadataDir is the directory of timestamped aggregate metadata files
+// that predates the per-run layout.
+const LegacyMetadataDir = "metadata"
+
+// MigrateCmdConfig holds configuration for the migrate command
+type MigrateCmdConfig struct {
+	outputDir     string
+	metadataFiles []string
+	overwrite     bool
+}
+
+// NewMigrateCmdConfig creates a new migrate command configuration from CLI context
+func NewMigrateCmdConfig(cliCtx *cli.Context) *MigrateCmdConfig {
+	return &MigrateCmdConfig{
+		outputDir:     cliCtx.String(flags.OutputDirFlagName),
+		metadataFiles: cliCtx.Args().Slice(),
+		overwrite:     cliCtx.Bool(flags.MigrateOverwriteFlagName),
+	}
+}
+
+// OutputDir returns the benchmark output directory to migrate
+func (c *MigrateCmdConfig) OutputDir() string {
+	return c.outputDir
+}
+
+// MetadataFiles returns the aggregate metadata files to split, oldest first.
+// Without arguments these are the files in <output-dir>/metadata/ followed by
+// <output-dir>/metadata.json, when they exist.
+func (c *MigrateCmdConfig) MetadataFiles() ([]string, error) {
+	if len(c.metadataFiles) > 0 {
+		return c.metadataFiles, nil
+	}
+
+	files, err := filepath.Glob(path.Join(c.outputDir, LegacyMetadataDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legacy metadata files: %w", err)
+	}
+	sort.Strings(files)
+
+	aggregate := path.Join(c.outputDir, "metadata.json")
+	if _, err := os.Stat(aggregate); err == nil {
+		files = append(files, aggregate)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no metadata files found in %s", c.outputDir)
+	}
+	return files, nil
+}
+
+// Overwrite returns whether existing per-run metadata files are replaced
+func (c *MigrateCmdConfig) Overwrite() bool {
+	return c.overwrite
+}
+
+// Check validates the migrate configuration
+func (c *MigrateCmdConfig) Check() error {
+	if c.outputDir == "" {
+		return fmt.Errorf("output directory is required")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package flags
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	ExportFileFlagName   = "file"
+	ExportFormatFlagName = "format"
+)
+
+var (
+	ExportFileFlag = &cli.StringFlag{
+		Name:  ExportFileFlagName,
+		Usage: "File to write the export to (default: <output-dir>/metrics.<format>)",
+	}
+
+	ExportFormatFlag = &cli.StringFlag{
+		Name:  ExportFormatFlagName,
+		Usage: "Export format (csv)",
+		Value: "csv",
+	}
+)
+
+// ExportFlags contains the list of flags for the export command
+var ExportFlags = []cli.Flag{
+	OutputDirFlag,
+	ExportFileFlag,
+	ExportFormatFlag,
+}
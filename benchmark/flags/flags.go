@@ -27,6 +27,12 @@ const (
 	MachineRegionFlagName     = "machine-region"
 	FileSystemFlagName        = "file-system"
 	ParallelTxBatchesFlagName = "parallel-tx-batches"
+	MetricsPortFlagName       = "metrics-port"
+	ResultSinkFlagName        = "result-sink"
+	ResultSinkTokenFlagName   = "result-sink-token"
+	S3EndpointFlagName        = "s3-endpoint"
+	S3RegionFlagName          = "s3-region"
+	EngineTraceFlagName       = "engine-trace"
 )
 
 // TxFuzz defaults
@@ -115,6 +121,44 @@ var (
 		Value:   4,
 		EnvVars: prefixEnvVars("PARALLEL_TX_BATCHES"),
 	}
+
+	MetricsPortFlag = &cli.IntFlag{
+		Name:    MetricsPortFlagName,
+		Usage:   "Port to serve live benchmark progress on /metrics (0 disables)",
+		Value:   0,
+		EnvVars: prefixEnvVars("METRICS_PORT"),
+	}
+
+	ResultSinkFlag = &cli.StringFlag{
+		Name:    ResultSinkFlagName,
+		Usage:   "Where to publish each finished run: a directory, s3://bucket/prefix or an http(s):// URL (empty disables)",
+		EnvVars: prefixEnvVars("RESULT_SINK"),
+	}
+
+	ResultSinkTokenFlag = &cli.StringFlag{
+		Name:    ResultSinkTokenFlagName,
+		Usage:   "Bearer token sent with uploads to an http(s) result sink",
+		EnvVars: prefixEnvVars("RESULT_SINK_TOKEN"),
+	}
+
+	S3EndpointFlag = &cli.StringFlag{
+		Name:    S3EndpointFlagName,
+		Usage:   "S3-compatible endpoint for s3:// result sinks, e.g. http://localhost:9000 for MinIO",
+		Value:   "https://s3.amazonaws.com",
+		EnvVars: prefixEnvVars("S3_ENDPOINT"),
+	}
+
+	S3RegionFlag = &cli.StringFlag{
+		Name:    S3RegionFlagName,
+		Usage:   "Region of the bucket of an s3:// result sink",
+		EnvVars: prefixEnvVars("S3_REGION"),
+	}
+
+	EngineTraceFlag = &cli.BoolFlag{
+		Name:    EngineTraceFlagName,
+		Usage:   "Route engine API calls through a tracing proxy and record their upstream timings",
+		EnvVars: prefixEnvVars("ENGINE_TRACE"),
+	}
 )
 
 // Flags contains the list of configuration options available to the binary.
@@ -133,6 +177,12 @@ var RunFlags = []cli.Flag{
 	MachineRegionFlag,
 	FileSystemFlag,
 	ParallelTxBatchesFlag,
+	MetricsPortFlag,
+	ResultSinkFlag,
+	ResultSinkTokenFlag,
+	S3EndpointFlag,
+	S3RegionFlag,
+	EngineTraceFlag,
 }
 
 func init() {
@@ -0,0 +1,22 @@
+package flags
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	MigrateOverwriteFlagName = "overwrite"
+)
+
+var (
+	MigrateOverwriteFlag = &cli.BoolFlag{
+		Name:  MigrateOverwriteFlagName,
+		Usage: "Replace per-run metadata.json files that already exist",
+	}
+)
+
+// MigrateFlags contains the list of flags for the migrate command
+var MigrateFlags = []cli.Flag{
+	OutputDirFlag,
+	MigrateOverwriteFlag,
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.13;
+
+import {ERC721} from "@openzeppelin/contracts/token/ERC721/ERC721.sol";
+
+/// @notice Open-mint ERC-721 collection used by the composite payload.
+contract BenchNFT is ERC721 {
+    uint256 public nextTokenId;
+
+    constructor() ERC721("Bench NFT", "BNFT") {}
+
+    function mint(address to) external returns (uint256 tokenId) {
+        tokenId = nextTokenId++;
+        _mint(to, tokenId);
+    }
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.13;
+
+import {IERC20} from "@openzeppelin/contracts/token/ERC20/IERC20.sol";
+
+/// @notice Uniswap-v2-style constant product pool used by the composite
+/// payload. Swaps pull the input token from the caller, pay out with a 0.3%
+/// fee, update reserves and accumulate the price oracle like a v2 pair.
+contract BenchPair {
+    address public immutable token0;
+    address public immutable token1;
+
+    uint112 private reserve0;
+    uint112 private reserve1;
+    uint32 private blockTimestampLast;
+
+    uint256 public price0CumulativeLast;
+    uint256 public price1CumulativeLast;
+
+    event Swap(address indexed sender, uint256 amountIn, uint256 amountOut, bool zeroForOne, address indexed to);
+    event Sync(uint112 reserve0, uint112 reserve1);
+
+    constructor(address _token0, address _token1) {
+        token0 = _token0;
+        token1 = _token1;
+    }
+
+    function getReserves() public view returns (uint112, uint112, uint32) {
+        return (reserve0, reserve1, blockTimestampLast);
+    }
+
+    function getAmountOut(uint256 amountIn, uint256 reserveIn, uint256 reserveOut) public pure returns (uint256) {
+        uint256 amountInWithFee = amountIn * 997;
+        return (amountInWithFee * reserveOut) / (reserveIn * 1000 + amountInWithFee);
+    }
+
+    function swap(bool zeroForOne, uint256 amountIn, uint256 minAmountOut, address to) external returns (uint256 amountOut) {
+        require(amountIn > 0, "BenchPair: zero input");
+        (address tokenIn, address tokenOut) = zeroForOne ? (token0, token1) : (token1, token0);
+        (uint256 reserveIn, uint256 reserveOut) = zeroForOne ? (reserve0, reserve1) : (reserve1, reserve0);
+
+        amountOut = getAmountOut(amountIn, reserveIn, reserveOut);
+        require(amountOut >= minAmountOut && amountOut > 0, "BenchPair: insufficient output");
+
+        require(IERC20(tokenIn).transferFrom(msg.sender, address(this), amountIn), "BenchPair: transfer in failed");
+        require(IERC20(tokenOut).transfer(to, amountOut), "BenchPair: transfer out failed");
+
+        _update(IERC20(token0).balanceOf(address(this)), IERC20(token1).balanceOf(address(this)));
+        emit Swap(msg.sender, amountIn, amountOut, zeroForOne, to);
+    }
+
+    /// @notice Matches reserves to balances, used after seeding liquidity.
+    function sync() external {
+        _update(IERC20(token0).balanceOf(address(this)), IERC20(token1).balanceOf(address(this)));
+    }
+
+    function _update(uint256 balance0, uint256 balance1) private {
+        require(balance0 <= type(uint112).max && balance1 <= type(uint112).max, "BenchPair: overflow");
+        uint32 blockTimestamp = uint32(block.timestamp % 2 ** 32);
+        unchecked {
+            uint32 timeElapsed = blockTimestamp - blockTimestampLast;
+            if (timeElapsed > 0 && reserve0 != 0 && reserve1 != 0) {
+                price0CumulativeLast += (uint256(reserve1) << 112) / reserve0 * timeElapsed;
+                price1CumulativeLast += (uint256(reserve0) << 112) / reserve1 * timeElapsed;
+            }
+        }
+        reserve0 = uint112(balance0);
+        reserve1 = uint112(balance1);
+        blockTimestampLast = blockTimestamp;
+        emit Sync(reserve0, reserve1);
+    }
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.13;
+
+import {ERC20} from "@openzeppelin/contracts/token/ERC20/ERC20.sol";
+
+/// @notice ERC-20 used by the composite payload. Anyone can mint, and the
+/// deployer can register trusted spenders (AMM pools) that may move holder
+/// balances without a per-holder approval.
+contract BenchToken is ERC20 {
+    address public immutable deployer;
+    mapping(address => bool) public trustedSpender;
+
+    constructor() ERC20("Bench Token", "BENCH") {
+        deployer = msg.sender;
+    }
+
+    function mint(address to, uint256 amount) external {
+        _mint(to, amount);
+    }
+
+    function mintBatch(address[] calldata to, uint256 amount) external {
+        for (uint256 i = 0; i < to.length; i++) {
+            _mint(to[i], amount);
+        }
+    }
+
+    function setTrustedSpender(address spender, bool trusted) external {
+        require(msg.sender == deployer, "BenchToken: not deployer");
+        trustedSpender[spender] = trusted;
+    }
+
+    function _spendAllowance(address owner, address spender, uint256 value) internal override {
+        if (trustedSpender[spender]) {
+            return;
+        }
+        super._spendAllowance(owner, spender, value);
+    }
+}
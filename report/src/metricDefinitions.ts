@@ -24,6 +24,27 @@ export const CHART_CONFIG = {
     description: "Shows the median time taken for new payload",
     unit: "ns",
   },
+  "latency/engine/update_fork_choice": {
+    type: "line",
+    title: "Update Fork Choice (Engine Trace)",
+    description:
+      "Shows the median update fork choice time measured by the engine trace proxy",
+    unit: "ns",
+  },
+  "latency/engine/get_payload": {
+    type: "line",
+    title: "Get Payload (Engine Trace)",
+    description:
+      "Shows the median get payload time measured by the engine trace proxy",
+    unit: "ns",
+  },
+  "latency/engine/new_payload": {
+    type: "line",
+    title: "New Payload (Engine Trace)",
+    description:
+      "Shows the median new payload time measured by the engine trace proxy",
+    unit: "ns",
+  },
   "chain/inserts.50-percentile": {
     type: "line",
     title: "Inserts",
@@ -130,6 +151,12 @@ export const CHART_CONFIG = {
     description: "Shows the number of transactions per block",
     unit: "count",
   },
+  "transactions/deposits_per_block": {
+    type: "line",
+    title: "Deposits per Block",
+    description: "Shows the number of user deposit transactions per block",
+    unit: "count",
+  },
   "gas/per_block": {
     type: "line",
     title: "Gas Per Block",
@@ -143,6 +170,24 @@ export const CHART_CONFIG = {
     unit: "s",
     aliases: ["reth_sync_execution_execution_duration"],
   },
+  reth_sync_execution_execution_duration_quantile_0_5: {
+    type: "line",
+    title: "Reth Sync Execution Duration p50",
+    description: "p50 time taken for execution during reth sync",
+    unit: "s",
+  },
+  reth_sync_execution_execution_duration_quantile_0_95: {
+    type: "line",
+    title: "Reth Sync Execution Duration p95",
+    description: "p95 time taken for execution during reth sync",
+    unit: "s",
+  },
+  reth_sync_execution_execution_duration_quantile_0_99: {
+    type: "line",
+    title: "Reth Sync Execution Duration p99",
+    description: "p99 time taken for execution during reth sync",
+    unit: "s",
+  },
   reth_sync_block_validation_state_root_duration_avg: {
     type: "line",
     title: "Reth Sync Block Validation State Root Duration",
@@ -406,6 +451,7 @@ const CHART_CONFIG_ORDER: (keyof typeof CHART_CONFIG)[] = [
   "latency/send_txs",
   "gas/per_block",
   "transactions/per_block",
+  "transactions/deposits_per_block",
   "chain/inserts.50-percentile",
   "chain/account/reads.50-percentile",
   "chain/storage/reads.50-percentile",
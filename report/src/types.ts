@@ -87,6 +87,14 @@ export interface BenchmarkRun {
       gasPerSecond: number;
       newPayload: number;
     };
+    proofProgramMetrics?: {
+      duration: number;
+      durationPerBlob?: number;
+      l2Blocks: number;
+      l1Blocks: number;
+      dataBlobs: number;
+      fillerBlobs: number;
+    };
   } | null;
 }
 
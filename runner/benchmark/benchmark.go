@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/base/base-bench/runner/network/types"
 	"github.com/ethereum/go-ethereum/core"
@@ -91,13 +93,25 @@ func applyParam(params *types.RunParams, k string, v interface{}) error {
 		params.LoadTestConfigOverrides = overrides
 	case "consensus_timing":
 		if vStr, ok := v.(string); ok {
-			if vStr != "" && vStr != types.ConsensusTimingModePreventLateFCU && vStr != types.ConsensusTimingModeBaseConsensus {
+			if vStr != "" && !slices.Contains(types.ConsensusTimingModes, vStr) {
 				return fmt.Errorf("invalid consensus timing %s", v)
 			}
 			params.ConsensusTimingMode = vStr
 		} else {
 			return fmt.Errorf("invalid consensus timing %s", v)
 		}
+	case "build_window":
+		if d, ok := durationParam(v); ok && d > 0 {
+			params.BuildWindow = d
+		} else {
+			return fmt.Errorf("invalid build window %v", v)
+		}
+	case "timing_jitter":
+		if d, ok := durationParam(v); ok && d > 0 {
+			params.TimingJitter = d
+		} else {
+			return fmt.Errorf("invalid timing jitter %v", v)
+		}
 	case "env":
 		if vStr, ok := v.(string); ok {
 			entries := strings.Split(vStr, ";")
@@ -118,6 +132,105 @@ func applyParam(params *types.RunParams, k string, v interface{}) error {
 		} else {
 			return fmt.Errorf("invalid num blocks %s", v)
 		}
+	case "duration":
+		if d, ok := durationParam(v); ok && d > 0 {
+			params.Duration = d
+		} else {
+			return fmt.Errorf("invalid duration %v", v)
+		}
+	case "warmup_blocks":
+		if vInt, ok := v.(int); ok && vInt >= 0 {
+			params.WarmupBlocks = vInt
+		} else {
+			return fmt.Errorf("invalid warmup blocks %v", v)
+		}
+	case "warmup_duration":
+		if d, ok := durationParam(v); ok && d > 0 {
+			params.WarmupDuration = d
+		} else {
+			return fmt.Errorf("invalid warmup duration %v", v)
+		}
+	case "steady_state_window":
+		if vInt, ok := v.(int); ok && vInt >= 2 {
+			params.SteadyStateWindow = vInt
+		} else {
+			return fmt.Errorf("invalid steady state window %v, must be at least 2 blocks", v)
+		}
+	case "steady_state_tolerance":
+		if f, ok := floatParam(v); ok && f > 0 {
+			params.SteadyStateTolerance = f
+		} else {
+			return fmt.Errorf("invalid steady state tolerance %v", v)
+		}
+	case "blobs_per_block":
+		if vInt, ok := v.(int); ok && vInt >= 0 {
+			params.BlobsPerBlock = uint64(vInt)
+		} else {
+			return fmt.Errorf("invalid blobs per block %v", v)
+		}
+	case "l1_origin_interval":
+		if vInt, ok := v.(int); ok && vInt > 0 {
+			params.L1OriginInterval = uint64(vInt)
+		} else {
+			return fmt.Errorf("invalid l1 origin interval %v", v)
+		}
+	case "eip1559_denominator":
+		if n, ok := uintParam(v, math.MaxUint32); ok && n > 0 {
+			denominator := uint32(n)
+			params.FeeParams.EIP1559Denominator = &denominator
+		} else {
+			return fmt.Errorf("invalid eip1559 denominator %v", v)
+		}
+	case "eip1559_elasticity":
+		if n, ok := uintParam(v, math.MaxUint32); ok && n > 0 {
+			elasticity := uint32(n)
+			params.FeeParams.EIP1559Elasticity = &elasticity
+		} else {
+			return fmt.Errorf("invalid eip1559 elasticity %v", v)
+		}
+	case "min_base_fee":
+		if n, ok := uintParam(v, math.MaxUint64); ok {
+			if n >= types.PayloadGasFeeCap {
+				return fmt.Errorf("min base fee %d must be below the payload workers' fee cap of %d wei", n, types.PayloadGasFeeCap)
+			}
+			params.FeeParams.MinBaseFee = &n
+		} else {
+			return fmt.Errorf("invalid min base fee %v", v)
+		}
+	case "l1_base_fee_scalar":
+		if n, ok := uintParam(v, math.MaxUint32); ok {
+			scalar := uint32(n)
+			params.FeeParams.BaseFeeScalar = &scalar
+		} else {
+			return fmt.Errorf("invalid l1 base fee scalar %v", v)
+		}
+	case "l1_blob_base_fee_scalar":
+		if n, ok := uintParam(v, math.MaxUint32); ok {
+			scalar := uint32(n)
+			params.FeeParams.BlobBaseFeeScalar = &scalar
+		} else {
+			return fmt.Errorf("invalid l1 blob base fee scalar %v", v)
+		}
+	case "operator_fee_scalar":
+		if n, ok := uintParam(v, math.MaxUint32); ok {
+			scalar := uint32(n)
+			params.FeeParams.OperatorFeeScalar = &scalar
+		} else {
+			return fmt.Errorf("invalid operator fee scalar %v", v)
+		}
+	case "operator_fee_constant":
+		if n, ok := uintParam(v, math.MaxUint64); ok {
+			params.FeeParams.OperatorFeeConstant = &n
+		} else {
+			return fmt.Errorf("invalid operator fee constant %v", v)
+		}
+	case "da_footprint_gas_scalar":
+		if n, ok := uintParam(v, math.MaxUint16); ok {
+			scalar := uint16(n)
+			params.FeeParams.DAFootprintGasScalar = &scalar
+		} else {
+			return fmt.Errorf("invalid da footprint gas scalar %v", v)
+		}
 	case "node_args":
 		// either a list of strings or a string (separated by spaces)
 		if vStr, ok := v.(string); ok {
@@ -140,6 +253,46 @@ func applyParam(params *types.RunParams, k string, v interface{}) error {
 	return nil
 }
 
+// uintParam returns v as an unsigned integer if it is a YAML integer between
+// zero and max.
+func uintParam(v interface{}, max uint64) (uint64, bool) {
+	switch n := v.(type) {
+	case int:
+		if n >= 0 && uint64(n) <= max {
+			return uint64(n), true
+		}
+	case uint64:
+		if n <= max {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// floatParam returns v as a float if it is a YAML number.
+func floatParam(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// durationParam returns v as a duration if it is a string like "500ms".
+func durationParam(v interface{}) (time.Duration, bool) {
+	vStr, ok := v.(string)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(vStr)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
 func applyParamGroup(params *types.RunParams, value interface{}) error {
 	// A params value groups multiple assignments into one matrix dimension,
 	// preserving relationships between values that should vary together.
@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/base/base-bench/runner/metrics"
 	"github.com/base/base-bench/runner/payload"
+	"github.com/base/base-bench/runner/soak"
 )
 
 type BenchmarkRole string
@@ -171,10 +173,19 @@ type FlashblocksConfig struct {
 const DefaultFlashblocksBlockTime = "250"
 const DefaultBlockTime = "1s"
 
+// TimestampResolutionSecond and TimestampResolutionMillisecond are the
+// supported units of payload timestamps. Millisecond timestamps are only valid
+// for chains whose config uses them, and allow sub-second block times.
+const (
+	TimestampResolutionSecond      = "1s"
+	TimestampResolutionMillisecond = "1ms"
+)
+
 type BenchmarkConfig struct {
 	Name                string               `yaml:"name"`
 	Description         *string              `yaml:"description"`
 	BlockTime           *string              `yaml:"block_time"`
+	TimestampResolution *string              `yaml:"timestamp_resolution"`
 	Flashblocks         *FlashblocksConfig   `yaml:"flashblocks"`
 	Benchmarks          []TestDefinition     `yaml:"benchmarks"`
 	TransactionPayloads []payload.Definition `yaml:"payloads"`
@@ -189,6 +200,22 @@ func (bc *BenchmarkConfig) GetBlockTime() (time.Duration, error) {
 	return time.ParseDuration(raw)
 }
 
+// GetTimestampResolution returns the configured unit of payload timestamps, or
+// whole seconds by default.
+func (bc *BenchmarkConfig) GetTimestampResolution() (time.Duration, error) {
+	if bc.TimestampResolution == nil || *bc.TimestampResolution == "" {
+		return time.Second, nil
+	}
+	switch *bc.TimestampResolution {
+	case TimestampResolutionSecond:
+		return time.Second, nil
+	case TimestampResolutionMillisecond:
+		return time.Millisecond, nil
+	default:
+		return 0, fmt.Errorf("invalid timestamp_resolution %q, must be %s or %s", *bc.TimestampResolution, TimestampResolutionSecond, TimestampResolutionMillisecond)
+	}
+}
+
 // FlashblocksBlockTime returns the configured flashblocks block time, or the default.
 func (bc *BenchmarkConfig) FlashblocksBlockTime() string {
 	if bc.Flashblocks != nil && bc.Flashblocks.BlockTime != "" {
@@ -220,6 +247,15 @@ type TestDefinition struct {
 	Roles        []BenchmarkRole      `yaml:"roles"`
 	Variables    []Param              `yaml:"variables"`
 	ProofProgram *ProofProgramOptions `yaml:"proof_program"`
+	// Scrape selects additional client metrics and enables archiving of
+	// complete Prometheus scrapes.
+	Scrape *metrics.ScrapeConfig `yaml:"scrape"`
+	// Search, if set, replaces each run with a saturation search for the
+	// highest gas limit the client sustains.
+	Search *SearchDefinition `yaml:"search"`
+	// Soak, if set, samples the state growth of the sequencer and reports how
+	// block latencies trend with it.
+	Soak *soak.Config `yaml:"soak"`
 }
 
 func (bc *TestDefinition) Check() error {
@@ -237,15 +273,63 @@ func (bc *TestDefinition) Check() error {
 		return err
 	}
 
+	if bc.Scrape != nil {
+		if err := bc.Scrape.Check(); err != nil {
+			return fmt.Errorf("invalid scrape config: %w", err)
+		}
+	}
+
 	for _, b := range bc.Variables {
 		err := b.Check()
 		if err != nil {
 			return err
 		}
 	}
+
+	if bc.setsParam("duration") && bc.setsParam("num_blocks") {
+		return errors.New("duration cannot be combined with num_blocks")
+	}
+
+	if bc.Soak != nil {
+		if err := bc.Soak.Check(); err != nil {
+			return fmt.Errorf("invalid soak config: %w", err)
+		}
+	}
+
+	if bc.Search != nil {
+		if err := bc.Search.Check(); err != nil {
+			return fmt.Errorf("invalid search config: %w", err)
+		}
+		if bc.setsParam("gas_limit") {
+			return errors.New("search cannot be combined with a gas_limit variable")
+		}
+	}
 	return nil
 }
 
+// setsParam returns true if a variable sets paramType, directly or in a
+// params group.
+func (bc *TestDefinition) setsParam(paramType string) bool {
+	for _, b := range bc.Variables {
+		if b.ParamType == paramType {
+			return true
+		}
+		if b.ParamType != "params" {
+			continue
+		}
+		for _, value := range append([]interface{}{b.Value}, b.Values...) {
+			group, err := normalizeStringKeyMap(value)
+			if err != nil {
+				continue
+			}
+			if _, ok := group[paramType]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (bc *TestDefinition) ExecutionMode() (BenchmarkExecutionMode, error) {
 	return BenchmarkExecutionModeFromRoles(bc.Roles)
 }
@@ -1,10 +1,13 @@
 package benchmark
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/base/base-bench/runner/metrics"
 	"github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/soak"
 )
 
 type ThresholdConfig struct {
@@ -19,6 +22,11 @@ type TestPlan struct {
 	Snapshot     *SnapshotDefinition
 	ProofProgram *ProofProgramOptions
 	Thresholds   *ThresholdConfig
+	Scrape       *metrics.ScrapeConfig
+	// Search, if set, runs each test run as a saturation search.
+	Search *SearchDefinition
+	// Soak, if set, samples the state growth of the sequencer during each run.
+	Soak *soak.Config
 	// Mode is normalized from the YAML roles field. The sequencer phase is
 	// always part of a test plan; Mode only controls whether validator replay runs.
 	Mode BenchmarkExecutionMode
@@ -50,12 +58,26 @@ func NewTestPlanFromConfig(c TestDefinition, testFileName string, config *Benchm
 		}
 	}
 
+	if proofProgram != nil {
+		for _, run := range testRuns {
+			if run.Params.PayloadTimestampResolution() != time.Second {
+				return nil, errors.New("proof_program requires whole-second block timestamps")
+			}
+			if payloadHasDeposits(run.Params.PayloadID, config) {
+				return nil, fmt.Errorf("payload %s has deposits, which the proof_program cannot derive", run.Params.PayloadID)
+			}
+		}
+	}
+
 	return &TestPlan{
 		Runs:         testRuns,
 		Datadir:      c.Datadir,
 		Snapshot:     c.Snapshot,
 		ProofProgram: proofProgram,
 		Thresholds:   c.Metrics,
+		Scrape:       c.Scrape,
+		Search:       c.Search,
+		Soak:         c.Soak,
 		Mode:         mode,
 	}, nil
 }
@@ -66,6 +88,10 @@ func ResolveTestRunsFromMatrix(c TestDefinition, testFileName string, config *Be
 	if err != nil {
 		return nil, fmt.Errorf("invalid block_time: %w", err)
 	}
+	timestampResolution, err := config.GetTimestampResolution()
+	if err != nil {
+		return nil, err
+	}
 
 	seenParams := make(map[string]bool)
 
@@ -128,6 +154,12 @@ func ResolveTestRunsFromMatrix(c TestDefinition, testFileName string, config *Be
 		}
 
 		params.BlockTime = blockTime
+		if timestampResolution != time.Second {
+			params.TimestampResolution = timestampResolution
+		}
+		if err := params.CheckBlockTime(); err != nil {
+			return nil, fmt.Errorf("invalid block_time: %w", err)
+		}
 		params.Name = config.Name
 		if config.Description != nil {
 			params.Description = *config.Description
@@ -137,6 +169,12 @@ func ResolveTestRunsFromMatrix(c TestDefinition, testFileName string, config *Be
 			params.Tags = *c.Tags
 		}
 		params.ConsensusTimingMode = consensusTimingMode(params, c, config)
+		if err := params.CheckConsensusTiming(); err != nil {
+			return nil, fmt.Errorf("invalid consensus timing: %w", err)
+		}
+		if err := params.CheckRunLength(); err != nil {
+			return nil, fmt.Errorf("invalid run length: %w", err)
+		}
 
 		testParams[i] = TestRun{
 			ID:          id,
@@ -180,6 +218,15 @@ func consensusTimingMode(params *types.RunParams, definition TestDefinition, con
 	return types.ConsensusTimingModePreventLateFCU
 }
 
+func payloadHasDeposits(payloadID string, config *BenchmarkConfig) bool {
+	for _, transactionPayload := range config.TransactionPayloads {
+		if transactionPayload.ID == payloadID && transactionPayload.Deposits != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func isSnapshotLoadTest(payloadID string, definition TestDefinition, config *BenchmarkConfig) bool {
 	if definition.Snapshot == nil || definition.Snapshot.Command == "" {
 		return false
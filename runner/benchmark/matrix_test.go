@@ -7,6 +7,7 @@ import (
 	"github.com/base/base-bench/runner/benchmark"
 	"github.com/base/base-bench/runner/network/types"
 	"github.com/base/base-bench/runner/payload"
+	"github.com/base/base-bench/runner/payload/deposit"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
@@ -469,6 +470,103 @@ func TestResolveTestRunsFromMatrixRejectsInvalidConsensusTiming(t *testing.T) {
 	require.ErrorContains(t, err, "invalid consensus timing")
 }
 
+func TestResolveTestRunsFromMatrixSupportsTimingStrategies(t *testing.T) {
+	var config benchmark.BenchmarkConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: timing strategies
+block_time: 2s
+payloads:
+  - id: transfer-only
+    type: transfer-only
+benchmarks:
+  - variables:
+      - type: payload
+        value: transfer-only
+      - type: params
+        values:
+          - consensus_timing: fixed-window
+            build_window: 500ms
+          - consensus_timing: asap
+          - consensus_timing: jittered
+            timing_jitter: 150ms
+`), &config))
+
+	runs, err := benchmark.ResolveTestRunsFromMatrix(config.Benchmarks[0], "benchmark.yml", &config)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	require.Equal(t, types.ConsensusTimingModeFixedWindow, runs[0].Params.ConsensusTimingMode)
+	require.Equal(t, 500*time.Millisecond, runs[0].Params.BuildWindow)
+	require.Equal(t, int64(500), runs[0].Params.ToConfig()["BuildWindowMilliseconds"])
+	require.Equal(t, types.ConsensusTimingModeASAP, runs[1].Params.ConsensusTimingMode)
+	require.Equal(t, types.ConsensusTimingModeJittered, runs[2].Params.ConsensusTimingMode)
+	require.Equal(t, 150*time.Millisecond, runs[2].Params.TimingJitter)
+}
+
+func TestResolveTestRunsFromMatrixRejectsMismatchedTimingParams(t *testing.T) {
+	blockTime := "2s"
+	config := &benchmark.BenchmarkConfig{Name: "benchmark", BlockTime: &blockTime}
+
+	for _, variables := range [][]benchmark.Param{
+		{{ParamType: "consensus_timing", Value: types.ConsensusTimingModeFixedWindow}},
+		{{ParamType: "consensus_timing", Value: types.ConsensusTimingModeJittered}},
+		{{ParamType: "consensus_timing", Value: types.ConsensusTimingModeJittered}, {ParamType: "timing_jitter", Value: "2s"}},
+		{{ParamType: "build_window", Value: "500ms"}},
+	} {
+		_, err := benchmark.ResolveTestRunsFromMatrix(benchmark.TestDefinition{Variables: variables}, "benchmark.yml", config)
+		require.ErrorContains(t, err, "invalid consensus timing")
+	}
+
+	_, err := benchmark.ResolveTestRunsFromMatrix(benchmark.TestDefinition{
+		Variables: []benchmark.Param{{ParamType: "build_window", Value: "soon"}},
+	}, "benchmark.yml", config)
+	require.ErrorContains(t, err, "invalid build window")
+}
+
+func TestResolveTestRunsFromMatrixSupportsBlobsPerBlock(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{
+		Name: "proof program blobs",
+		TransactionPayloads: []payload.Definition{
+			{
+				ID:   "transfer-only",
+				Type: "transfer-only",
+			},
+		},
+	}
+	definition := benchmark.TestDefinition{
+		Variables: []benchmark.Param{
+			{
+				ParamType: "payload",
+				Value:     "transfer-only",
+			},
+			{
+				ParamType: "blobs_per_block",
+				Values:    []interface{}{1, 6},
+			},
+		},
+	}
+
+	runs, err := benchmark.ResolveTestRunsFromMatrix(definition, "proof-program-blobs.yml", config)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.ElementsMatch(t, []uint64{1, 6}, []uint64{runs[0].Params.BlobsPerBlock, runs[1].Params.BlobsPerBlock})
+	require.Equal(t, runs[0].Params.BlobsPerBlock, runs[0].Params.ToConfig()["BlobsPerBlock"])
+}
+
+func TestResolveTestRunsFromMatrixRejectsNegativeBlobsPerBlock(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{Name: "benchmark"}
+	definition := benchmark.TestDefinition{
+		Variables: []benchmark.Param{
+			{
+				ParamType: "blobs_per_block",
+				Value:     -1,
+			},
+		},
+	}
+
+	_, err := benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+	require.ErrorContains(t, err, "invalid blobs per block")
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
@@ -476,3 +574,295 @@ func stringPtr(s string) *string {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestResolveTestRunsFromMatrixSubSecondBlockTime(t *testing.T) {
+	definition := benchmark.TestDefinition{
+		Variables: []benchmark.Param{
+			{
+				ParamType: "node_type",
+				Value:     "base-reth-node",
+			},
+		},
+	}
+
+	config := &benchmark.BenchmarkConfig{Name: "benchmark", BlockTime: stringPtr("200ms")}
+	_, err := benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+	require.ErrorContains(t, err, "sub-second block times require timestamp_resolution: 1ms")
+
+	config.TimestampResolution = stringPtr(benchmark.TimestampResolutionMillisecond)
+	runs, err := benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, 200*time.Millisecond, runs[0].Params.BlockTime)
+	require.Equal(t, time.Millisecond, runs[0].Params.PayloadTimestampResolution())
+	require.Equal(t, int64(1), runs[0].Params.ToConfig()["TimestampResolutionMilliseconds"])
+
+	config.BlockTime = stringPtr("250500us")
+	_, err = benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+	require.ErrorContains(t, err, "not a multiple of the timestamp resolution")
+
+	config.TimestampResolution = stringPtr("1us")
+	_, err = benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+	require.ErrorContains(t, err, "invalid timestamp_resolution")
+}
+
+func TestNewTestPlanFromConfigRejectsProofProgramWithMillisecondTimestamps(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{
+		Name:                "test",
+		TimestampResolution: stringPtr(benchmark.TimestampResolutionMillisecond),
+	}
+	definition := benchmark.TestDefinition{
+		ProofProgram: &benchmark.ProofProgramOptions{
+			Enabled: boolPtr(true),
+		},
+		Variables: []benchmark.Param{
+			{
+				ParamType: "node_type",
+				Value:     "builder",
+			},
+		},
+	}
+
+	_, err := benchmark.ResolveTestRunsFromMatrix(definition, "config.yml", config)
+	require.NoError(t, err)
+
+	_, err = benchmark.NewTestPlanFromConfig(definition, "config.yml", config)
+	require.ErrorContains(t, err, "proof_program requires whole-second block timestamps")
+}
+
+func TestNewTestPlanFromConfigRejectsProofProgramWithDeposits(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{
+		Name: "test",
+		TransactionPayloads: []payload.Definition{
+			{
+				ID:       "transfers",
+				Type:     "transfer-only",
+				Deposits: &deposit.Definition{Transfers: 10},
+			},
+		},
+	}
+	definition := benchmark.TestDefinition{
+		ProofProgram: &benchmark.ProofProgramOptions{
+			Enabled: boolPtr(true),
+		},
+		Variables: []benchmark.Param{
+			{
+				ParamType: "payload",
+				Value:     "transfers",
+			},
+			{
+				ParamType: "node_type",
+				Value:     "builder",
+			},
+		},
+	}
+
+	_, err := benchmark.NewTestPlanFromConfig(definition, "config.yml", config)
+	require.ErrorContains(t, err, "payload transfers has deposits")
+
+	definition.ProofProgram = nil
+	_, err = benchmark.NewTestPlanFromConfig(definition, "config.yml", config)
+	require.NoError(t, err)
+}
+
+func TestResolveTestRunsFromMatrixWithFeeParams(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{Name: "benchmark"}
+	definition := benchmark.TestDefinition{
+		Variables: []benchmark.Param{
+			{
+				ParamType: "params",
+				Values: []interface{}{
+					map[string]interface{}{"eip1559_denominator": 250, "eip1559_elasticity": 6},
+					map[string]interface{}{"min_base_fee": 0, "da_footprint_gas_scalar": 400},
+				},
+			},
+			{
+				ParamType: "l1_base_fee_scalar",
+				Value:     2000,
+			},
+		},
+	}
+
+	runs, err := benchmark.ResolveTestRunsFromMatrix(definition, "fees.yml", config)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+
+	byDenominator := make(map[uint32]types.Fees)
+	for _, run := range runs {
+		fees := run.Params.FeeParams.Resolve()
+		byDenominator[fees.EIP1559Denominator] = fees
+		require.Equal(t, uint32(2000), run.Params.ToConfig()["BaseFeeScalar"])
+	}
+
+	custom := byDenominator[250]
+	require.Equal(t, uint32(6), custom.EIP1559Elasticity)
+	require.Equal(t, types.DefaultFees.MinBaseFee, custom.MinBaseFee)
+	require.Equal(t, uint32(2000), custom.BaseFeeScalar)
+
+	defaults := byDenominator[types.DefaultFees.EIP1559Denominator]
+	require.Equal(t, uint64(0), defaults.MinBaseFee)
+	require.Equal(t, uint16(400), defaults.DAFootprintGasScalar)
+	require.Equal(t, types.DefaultFees.BlobBaseFeeScalar, defaults.BlobBaseFeeScalar)
+}
+
+func TestResolveTestRunsFromMatrixRejectsInvalidFeeParams(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{Name: "benchmark"}
+	for paramType, value := range map[string]interface{}{
+		"eip1559_denominator":     0,
+		"eip1559_elasticity":      -1,
+		"da_footprint_gas_scalar": 70_000,
+		"min_base_fee":            "1 gwei",
+	} {
+		definition := benchmark.TestDefinition{
+			Variables: []benchmark.Param{{ParamType: paramType, Value: value}},
+		}
+
+		_, err := benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+		require.Error(t, err, paramType)
+	}
+
+	definition := benchmark.TestDefinition{
+		Variables: []benchmark.Param{{ParamType: "min_base_fee", Value: types.PayloadGasFeeCap}},
+	}
+	_, err := benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+	require.ErrorContains(t, err, "fee cap")
+}
+
+func TestNewTestPlanFromConfigRejectsSearchWithGasLimit(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{Name: "test"}
+	definition := benchmark.TestDefinition{
+		Search: &benchmark.SearchDefinition{
+			MinGasLimit: 30_000_000,
+			MaxGasLimit: 500_000_000,
+		},
+		Variables: []benchmark.Param{
+			{
+				ParamType: "payload",
+				Value:     "simple",
+			},
+			{
+				ParamType: "params",
+				Values: []interface{}{
+					map[string]interface{}{"gas_limit": 400_000_000},
+				},
+			},
+		},
+	}
+
+	_, err := benchmark.NewTestPlanFromConfig(definition, "config.yml", config)
+	require.ErrorContains(t, err, "search cannot be combined with a gas_limit variable")
+
+	definition.Variables = definition.Variables[:1]
+	plan, err := benchmark.NewTestPlanFromConfig(definition, "config.yml", config)
+	require.NoError(t, err)
+	require.Equal(t, definition.Search, plan.Search)
+
+	metadata := benchmark.RunGroupFromTestPlans([]benchmark.TestPlan{*plan}, nil)
+	require.Equal(t, "30000000-500000000", metadata.Runs[0].TestConfig["SearchGasLimits"])
+	require.NotContains(t, metadata.Runs[0].TestConfig, "GasLimit")
+}
+
+func TestResolveTestRunsFromMatrixSupportsDurationAndWarmup(t *testing.T) {
+	var config benchmark.BenchmarkConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: soak
+block_time: 2s
+benchmarks:
+  - variables:
+      - type: payload
+        value: transfer-only
+      - type: params
+        values:
+          - duration: 30m
+            warmup_duration: 5m
+            steady_state_window: 20
+            steady_state_tolerance: 0.05
+          - duration: 1m
+            warmup_blocks: 10
+`), &config))
+
+	runs, err := benchmark.ResolveTestRunsFromMatrix(config.Benchmarks[0], "benchmark.yml", &config)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+
+	soak := runs[0].Params
+	require.Equal(t, 30*time.Minute, soak.Duration)
+	require.Equal(t, 5*time.Minute, soak.WarmupDuration)
+	require.Equal(t, 20, soak.SteadyStateWindow)
+	require.Equal(t, 0.05, soak.SteadyStateTolerance)
+	require.True(t, soak.HasSteadyStateDetection())
+	require.Equal(t, 900+150, soak.PlannedBlocks())
+	require.Equal(t, 1800.0, soak.ToConfig()["DurationSeconds"])
+
+	short := runs[1].Params
+	require.Equal(t, 10, short.WarmupBlocks)
+	require.False(t, short.HasSteadyStateDetection())
+	require.Equal(t, 30+10, short.PlannedBlocks())
+	require.True(t, short.PacesBlocksByBlockTime())
+
+	short.ConsensusTimingMode = types.ConsensusTimingModeASAP
+	require.False(t, short.PacesBlocksByBlockTime())
+}
+
+func TestResolveTestRunsFromMatrixRejectsInvalidRunLength(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{Name: "benchmark"}
+	for name, values := range map[string]map[string]interface{}{
+		"negative warmup":              {"warmup_blocks": -1},
+		"unparsable duration":          {"duration": "forever"},
+		"window without tolerance":     {"warmup_blocks": 10, "steady_state_window": 5},
+		"tolerance without window":     {"warmup_blocks": 10, "steady_state_tolerance": 0.1},
+		"steady state without warm-up": {"steady_state_window": 5, "steady_state_tolerance": 0.1},
+		"window too small":             {"warmup_blocks": 10, "steady_state_window": 1, "steady_state_tolerance": 0.1},
+	} {
+		definition := benchmark.TestDefinition{
+			Variables: []benchmark.Param{{ParamType: "params", Values: []interface{}{values}}},
+		}
+
+		_, err := benchmark.ResolveTestRunsFromMatrix(definition, "benchmark.yml", config)
+		require.Error(t, err, name)
+	}
+}
+
+func TestNewTestPlanFromConfigRejectsDurationWithNumBlocks(t *testing.T) {
+	config := &benchmark.BenchmarkConfig{Name: "test"}
+	definition := benchmark.TestDefinition{
+		Variables: []benchmark.Param{
+			{ParamType: "payload", Value: "simple"},
+			{ParamType: "num_blocks", Value: 10},
+			{ParamType: "duration", Value: "10m"},
+		},
+	}
+
+	_, err := benchmark.NewTestPlanFromConfig(definition, "config.yml", config)
+	require.ErrorContains(t, err, "duration cannot be combined with num_blocks")
+}
+
+func TestNewTestPlanFromConfigWithSoak(t *testing.T) {
+	var config benchmark.BenchmarkConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: soak
+benchmarks:
+  - soak:
+      sample_interval: 300
+      count_state: true
+    variables:
+      - type: payload
+        value: transfer-only
+      - type: duration
+        value: 4h
+`), &config))
+
+	plan, err := benchmark.NewTestPlanFromConfig(config.Benchmarks[0], "soak.yml", &config)
+	require.NoError(t, err)
+	require.NotNil(t, plan.Soak)
+	require.Equal(t, 300, *plan.Soak.SampleInterval)
+	require.True(t, plan.Soak.CountState)
+
+	metadata := benchmark.RunGroupFromTestPlans([]benchmark.TestPlan{*plan}, nil)
+	require.Equal(t, true, metadata.Runs[0].TestConfig["Soak"])
+
+	interval := 0
+	config.Benchmarks[0].Soak.SampleInterval = &interval
+	_, err = benchmark.NewTestPlanFromConfig(config.Benchmarks[0], "soak.yml", &config)
+	require.ErrorContains(t, err, "invalid soak config")
+}
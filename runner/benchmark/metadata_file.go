@@ -0,0 +1,121 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+	"github.com/pkg/errors"
+)
+
+// MetadataFile is an aggregate metadata.json that several processes may
+// update at once, such as two runs sharing an output directory or an
+// import-runs next to a run. Updates hold an exclusive lock on a sibling
+// .lock file and replace the file atomically, so readers that do not lock
+// see either the previous or the new document, never a partial one.
+type MetadataFile struct {
+	path string
+	lock *flock.Flock
+}
+
+// NewMetadataFile returns the metadata file at path.
+func NewMetadataFile(path string) *MetadataFile {
+	return &MetadataFile{
+		path: path,
+		lock: flock.New(path + ".lock"),
+	}
+}
+
+// Path returns the path of the metadata file.
+func (f *MetadataFile) Path() string {
+	return f.path
+}
+
+// Read returns the current metadata. A missing or empty file is an empty
+// group.
+func (f *MetadataFile) Read() (*RunGroup, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return &RunGroup{Runs: []Run{}}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read metadata file")
+	}
+	return decodeMetadata(data)
+}
+
+// Update applies fn to the current metadata and writes the result, holding
+// the lock from the read to the write. Nothing is written if fn returns an
+// error.
+func (f *MetadataFile) Update(fn func(metadata *RunGroup) error) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create metadata directory")
+	}
+	if err := f.lock.Lock(); err != nil {
+		return errors.Wrap(err, "failed to lock metadata file")
+	}
+	defer func() {
+		_ = f.lock.Unlock()
+	}()
+
+	metadata, err := f.Read()
+	if err != nil {
+		return err
+	}
+	if err := fn(metadata); err != nil {
+		return err
+	}
+	return f.write(metadata)
+}
+
+// write replaces the metadata file with a fully written and synced
+// temporary file. It must be called with the lock held.
+func (f *MetadataFile) write(metadata *RunGroup) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(metadata); err != nil {
+		return errors.Wrap(err, "failed to encode metadata")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary metadata file")
+	}
+	defer func() {
+		// no-op once the file has been renamed into place
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write metadata file")
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to sync metadata file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close metadata file")
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return errors.Wrap(err, "failed to set metadata file permissions")
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return errors.Wrap(err, "failed to replace metadata file")
+	}
+	return nil
+}
+
+func decodeMetadata(data []byte) (*RunGroup, error) {
+	metadata := &RunGroup{Runs: []Run{}}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return metadata, nil
+	}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, errors.Wrap(err, "failed to decode metadata file")
+	}
+	return metadata, nil
+}
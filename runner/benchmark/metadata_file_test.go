@@ -0,0 +1,83 @@
+package benchmark
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataFileReadMissingOrEmpty(t *testing.T) {
+	dir := t.TempDir()
+	f := NewMetadataFile(path.Join(dir, "metadata.json"))
+
+	metadata, err := f.Read()
+	require.NoError(t, err)
+	require.Empty(t, metadata.Runs)
+
+	require.NoError(t, os.WriteFile(f.Path(), nil, 0644))
+	metadata, err = f.Read()
+	require.NoError(t, err)
+	require.Empty(t, metadata.Runs)
+
+	require.NoError(t, os.WriteFile(f.Path(), []byte(`{"runs": [`), 0644))
+	_, err = f.Read()
+	require.Error(t, err)
+}
+
+func TestMetadataFileConcurrentUpdates(t *testing.T) {
+	dir := t.TempDir()
+	metadataPath := path.Join(dir, "metadata.json")
+
+	const writers = 16
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// separate instances lock like separate processes would
+			f := NewMetadataFile(metadataPath)
+			err := f.Update(func(metadata *RunGroup) error {
+				metadata.Runs = append(metadata.Runs, Run{ID: fmt.Sprintf("run-%d", i)})
+				return nil
+			})
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	metadata, err := NewMetadataFile(metadataPath).Read()
+	require.NoError(t, err)
+	require.Len(t, metadata.Runs, writers)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.ElementsMatch(t, []string{"metadata.json", "metadata.json.lock"}, names)
+}
+
+func TestMetadataFileUpdateErrorKeepsFile(t *testing.T) {
+	f := NewMetadataFile(path.Join(t.TempDir(), "metadata.json"))
+	require.NoError(t, f.Update(func(metadata *RunGroup) error {
+		metadata.Runs = append(metadata.Runs, Run{ID: "a"})
+		return nil
+	}))
+
+	err := f.Update(func(metadata *RunGroup) error {
+		metadata.Runs = nil
+		return errors.New("merge failed")
+	})
+	require.ErrorContains(t, err, "merge failed")
+
+	metadata, err := f.Read()
+	require.NoError(t, err)
+	require.Len(t, metadata.Runs, 1)
+	require.Equal(t, "a", metadata.Runs[0].ID)
+}
@@ -1,9 +1,11 @@
 package benchmark
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/soak"
 )
 
 type RunResult struct {
@@ -11,8 +13,16 @@ type RunResult struct {
 	Complete         bool                       `json:"complete"`
 	SequencerMetrics *types.SequencerKeyMetrics `json:"sequencerMetrics,omitempty"`
 	ValidatorMetrics *types.ValidatorKeyMetrics `json:"validatorMetrics,omitempty"`
-	ClientVersion    string                     `json:"clientVersion,omitempty"`
-	Artifacts        map[string]string          `json:"artifacts,omitempty"`
+	// ProofProgramMetrics is set when the benchmark ran the proof program.
+	ProofProgramMetrics *types.ProofProgramKeyMetrics `json:"proofProgramMetrics,omitempty"`
+	ClientVersion       string                        `json:"clientVersion,omitempty"`
+	Artifacts           map[string]string             `json:"artifacts,omitempty"`
+	// Warmup is set when the run had a warm-up phase excluded from the metrics.
+	Warmup *types.WarmupSummary `json:"warmup,omitempty"`
+	// Search is set when the run is a saturation search.
+	Search *SearchResult `json:"search,omitempty"`
+	// Soak is set when the run sampled the state growth of the sequencer.
+	Soak *soak.Summary `json:"soak,omitempty"`
 }
 
 // MachineInfo contains information about the machine running the benchmark
@@ -51,6 +61,42 @@ func (runs *RunGroup) AddResult(testIdx int, runResult RunResult) {
 	runs.Runs[testIdx].Result = &runResult
 }
 
+// RunMetadataFileName is the name of the per-run metadata document that the
+// report data contract expects at <outputDir>/metadata.json.
+const RunMetadataFileName = "metadata.json"
+
+// NewRunMetadata returns the per-run metadata document for a run. It always
+// holds exactly one run.
+func NewRunMetadata(run Run) RunGroup {
+	return RunGroup{
+		Runs: []Run{run},
+	}
+}
+
+// Validate checks that the run has every field the report data contract
+// requires.
+func (r *Run) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("run is missing an id")
+	}
+	if r.OutputDir == "" {
+		return fmt.Errorf("run %s is missing an outputDir", r.ID)
+	}
+	if r.SourceFile == "" {
+		return fmt.Errorf("run %s is missing a sourceFile", r.ID)
+	}
+	if r.TestName == "" {
+		return fmt.Errorf("run %s is missing a testName", r.ID)
+	}
+	if benchmarkRun, _ := r.TestConfig[BenchmarkRunTag].(string); benchmarkRun == "" {
+		return fmt.Errorf("run %s is missing testConfig.%s", r.ID, BenchmarkRunTag)
+	}
+	if r.CreatedAt == nil || r.CreatedAt.IsZero() {
+		return fmt.Errorf("run %s is missing createdAt", r.ID)
+	}
+	return nil
+}
+
 const (
 	BenchmarkRunTag           = "BenchmarkRun"
 	LoadTestResultArtifactKey = "loadTestResult"
@@ -59,6 +105,13 @@ const (
 	LoadTestTimestampLayout   = "2006-01-02-15-04-05"
 )
 
+// SequencerEngineTraceArtifactKey and ValidatorEngineTraceArtifactKey are the
+// artifact keys of the engine API traces recorded with --engine-trace.
+const (
+	SequencerEngineTraceArtifactKey = "sequencerEngineTrace"
+	ValidatorEngineTraceArtifactKey = "validatorEngineTrace"
+)
+
 func RunGroupFromTestPlans(testPlans []TestPlan, machineInfo *MachineInfo) RunGroup {
 	now := time.Now()
 	metadata := RunGroup{
@@ -71,6 +124,14 @@ func RunGroupFromTestPlans(testPlans []TestPlan, machineInfo *MachineInfo) RunGr
 			if !testPlan.Mode.IsDefault() {
 				testConfig["Roles"] = testPlan.Mode.RolesString()
 			}
+			if testPlan.Soak != nil {
+				testConfig["Soak"] = true
+			}
+			if testPlan.Search != nil {
+				// the gas limit is only known once the search finished
+				delete(testConfig, "GasLimit")
+				testConfig["SearchGasLimits"] = fmt.Sprintf("%d-%d", testPlan.Search.MinGasLimit, testPlan.Search.MaxGasLimit)
+			}
 
 			metadata.Runs = append(metadata.Runs, Run{
 				ID:              params.ID,
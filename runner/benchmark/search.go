@@ -0,0 +1,202 @@
+package benchmark
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultSearchIterations is the number of runs of a saturation search if
+	// not configured.
+	DefaultSearchIterations = 6
+	// DefaultSearchMinBlockFill is the fraction of the gas limit the
+	// sequencer's blocks must use for a gas limit to count as sustained if not
+	// configured.
+	DefaultSearchMinBlockFill = 0.9
+
+	// minSearchGasLimitStep ends a search early once the bounds are closer
+	// than this, as further runs would not change the result meaningfully.
+	minSearchGasLimitStep = 1_000_000
+
+	// SearchResultArtifactKey and SearchResultFileName are the artifact key
+	// and file name of the convergence history of a saturation search.
+	SearchResultArtifactKey = "search"
+	SearchResultFileName    = "search.json"
+)
+
+// SearchDefinition is the user-facing YAML configuration of a saturation
+// search, which binary-searches the gas limit of each run of a benchmark for
+// the highest one the client sustains. MinGasLimit is assumed sustainable and
+// MaxGasLimit unsustainable.
+type SearchDefinition struct {
+	MinGasLimit uint64 `yaml:"min_gas_limit"`
+	MaxGasLimit uint64 `yaml:"max_gas_limit"`
+	// Iterations is the maximum number of runs of the search.
+	Iterations *int `yaml:"iterations"`
+	// MinBlockFill is the fraction of the gas limit the sequencer's blocks
+	// must use on average, below which the builder did not keep up.
+	MinBlockFill *float64 `yaml:"min_block_fill"`
+}
+
+func (s *SearchDefinition) Check() error {
+	if s.MinGasLimit == 0 {
+		return errors.New("min_gas_limit is required")
+	}
+	if s.MaxGasLimit <= s.MinGasLimit {
+		return fmt.Errorf("max_gas_limit %d must be greater than min_gas_limit %d", s.MaxGasLimit, s.MinGasLimit)
+	}
+	if s.Iterations != nil && *s.Iterations <= 0 {
+		return fmt.Errorf("iterations must be positive, got %d", *s.Iterations)
+	}
+	if s.MinBlockFill != nil && (*s.MinBlockFill <= 0 || *s.MinBlockFill > 1) {
+		return fmt.Errorf("min_block_fill must be in (0, 1], got %v", *s.MinBlockFill)
+	}
+	return nil
+}
+
+func (s *SearchDefinition) iterations() int {
+	if s.Iterations == nil {
+		return DefaultSearchIterations
+	}
+	return *s.Iterations
+}
+
+func (s *SearchDefinition) minBlockFill() float64 {
+	if s.MinBlockFill == nil {
+		return DefaultSearchMinBlockFill
+	}
+	return *s.MinBlockFill
+}
+
+// SearchStep is one run of a saturation search.
+type SearchStep struct {
+	GasLimit  uint64 `json:"gasLimit"`
+	Sustained bool   `json:"sustained"`
+	// Reason explains why the gas limit was not sustained.
+	Reason string `json:"reason,omitempty"`
+	// GasPerSecond is the gas the chain processed per second of block time.
+	GasPerSecond float64 `json:"gasPerSecond"`
+	// BlockFill is the average fraction of the gas limit the sequencer's
+	// blocks used.
+	BlockFill float64 `json:"blockFill"`
+	// BuildLatency is the 95th percentile of the time in seconds the
+	// sequencer spent in its forkchoiceUpdated and getPayload calls for a
+	// block.
+	BuildLatency float64 `json:"buildLatency"`
+	// NewPayloadLatency is the 95th percentile of the validator newPayload
+	// latency in seconds.
+	NewPayloadLatency float64 `json:"newPayloadLatency,omitempty"`
+	// OutputDir is the output directory of the run, relative to the output
+	// directory of the search.
+	OutputDir string `json:"outputDir"`
+}
+
+// SearchResult is the outcome of a saturation search.
+type SearchResult struct {
+	// MaxGasLimit is the highest sustained gas limit, zero if none was.
+	MaxGasLimit uint64 `json:"maxGasLimit"`
+	// MaxGasPerSecond is the gas per second processed at MaxGasLimit.
+	MaxGasPerSecond float64      `json:"maxGasPerSecond"`
+	Steps           []SearchStep `json:"steps"`
+}
+
+// SaturationSearch binary-searches the gas limit between the bounds of a
+// SearchDefinition. Each run at the gas limit returned by Next is evaluated
+// with Record, which narrows the bounds.
+type SaturationSearch struct {
+	definition   SearchDefinition
+	blockTime    time.Duration
+	runValidator bool
+
+	low   uint64
+	high  uint64
+	steps []SearchStep
+	best  int
+}
+
+// NewSaturationSearch creates a search for runs with the given block time.
+// The validator latency is only checked if the runs include the validator.
+func NewSaturationSearch(definition SearchDefinition, blockTime time.Duration, runValidator bool) *SaturationSearch {
+	return &SaturationSearch{
+		definition:   definition,
+		blockTime:    blockTime,
+		runValidator: runValidator,
+		low:          definition.MinGasLimit,
+		high:         definition.MaxGasLimit,
+		best:         -1,
+	}
+}
+
+// Next returns the gas limit of the next run, or false if the search is done.
+func (s *SaturationSearch) Next() (uint64, bool) {
+	if len(s.steps) >= s.definition.iterations() || s.high-s.low < minSearchGasLimitStep {
+		return 0, false
+	}
+	return s.low + (s.high-s.low)/2, true
+}
+
+// Record evaluates the run at gasLimit, whose output is in outputDir. result
+// is ignored if runErr is set.
+func (s *SaturationSearch) Record(gasLimit uint64, outputDir string, result *RunResult, runErr error) SearchStep {
+	step := SearchStep{GasLimit: gasLimit, OutputDir: outputDir}
+
+	switch {
+	case runErr != nil:
+		step.Reason = fmt.Sprintf("run failed: %v", runErr)
+	case result == nil || result.SequencerMetrics == nil:
+		step.Reason = "run has no sequencer metrics"
+	case s.runValidator && result.ValidatorMetrics == nil:
+		step.Reason = "run has no validator metrics"
+	default:
+		gasPerBlock := result.SequencerMetrics.AverageGasPerBlock
+		step.GasPerSecond = gasPerBlock / s.blockTime.Seconds()
+		step.BlockFill = gasPerBlock / float64(gasLimit)
+		step.BuildLatency = result.SequencerMetrics.BuildLatencyP95
+		if result.ValidatorMetrics != nil {
+			step.NewPayloadLatency = result.ValidatorMetrics.NewPayloadLatencyP95
+		}
+
+		if minFill := s.definition.minBlockFill(); step.BlockFill < minFill {
+			step.Reason = fmt.Sprintf("blocks were %.1f%% full, below %.1f%%", step.BlockFill*100, minFill*100)
+		} else if step.BuildLatency > s.blockTime.Seconds() {
+			step.Reason = fmt.Sprintf("p95 build latency %.3fs misses the block time %s", step.BuildLatency, s.blockTime)
+		} else if s.runValidator && step.NewPayloadLatency > s.blockTime.Seconds() {
+			step.Reason = fmt.Sprintf("p95 newPayload latency %.3fs misses the block time %s", step.NewPayloadLatency, s.blockTime)
+		} else {
+			step.Sustained = true
+		}
+	}
+
+	if step.Sustained {
+		s.low = gasLimit
+		if s.best < 0 || gasLimit > s.steps[s.best].GasLimit {
+			s.best = len(s.steps)
+		}
+	} else {
+		s.high = gasLimit
+	}
+	s.steps = append(s.steps, step)
+	return step
+}
+
+// Best returns the run at the highest sustained gas limit, or false if no
+// gas limit was sustained.
+func (s *SaturationSearch) Best() (SearchStep, bool) {
+	if s.best < 0 {
+		return SearchStep{}, false
+	}
+	return s.steps[s.best], true
+}
+
+// Result returns the outcome and convergence history of the search.
+func (s *SaturationSearch) Result() SearchResult {
+	result := SearchResult{
+		Steps: append([]SearchStep(nil), s.steps...),
+	}
+	if best, ok := s.Best(); ok {
+		result.MaxGasLimit = best.GasLimit
+		result.MaxGasPerSecond = best.GasPerSecond
+	}
+	return result
+}
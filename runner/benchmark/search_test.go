@@ -0,0 +1,123 @@
+package benchmark_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/base/base-bench/runner/benchmark"
+	"github.com/base/base-bench/runner/network/types"
+	"github.com/stretchr/testify/require"
+)
+
+func searchRunResult(gasPerBlock float64, newPayloadLatency float64) *benchmark.RunResult {
+	return &benchmark.RunResult{
+		Success:          true,
+		SequencerMetrics: &types.SequencerKeyMetrics{AverageGasPerBlock: gasPerBlock},
+		ValidatorMetrics: &types.ValidatorKeyMetrics{NewPayloadLatencyP95: newPayloadLatency},
+	}
+}
+
+func TestSaturationSearchConverges(t *testing.T) {
+	iterations := 8
+	search := benchmark.NewSaturationSearch(benchmark.SearchDefinition{
+		MinGasLimit: 16_000_000,
+		MaxGasLimit: 144_000_000,
+		Iterations:  &iterations,
+	}, time.Second, true)
+
+	// the client fills blocks up to 100M gas, and newPayload exceeds the block
+	// time above 80M gas
+	var gasLimits []uint64
+	for {
+		gasLimit, ok := search.Next()
+		if !ok {
+			break
+		}
+		gasLimits = append(gasLimits, gasLimit)
+
+		gasPerBlock := float64(min(gasLimit, 100_000_000))
+		latency := 0.5
+		if gasLimit > 80_000_000 {
+			latency = 1.5
+		}
+		search.Record(gasLimit, "", searchRunResult(gasPerBlock, latency), nil)
+	}
+
+	require.Equal(t, []uint64{80_000_000, 112_000_000, 96_000_000, 88_000_000, 84_000_000, 82_000_000, 81_000_000, 80_500_000}, gasLimits)
+
+	result := search.Result()
+	require.Equal(t, uint64(80_000_000), result.MaxGasLimit)
+	require.Equal(t, 80e6, result.MaxGasPerSecond)
+	require.Len(t, result.Steps, 8)
+	require.True(t, result.Steps[0].Sustained)
+	require.False(t, result.Steps[1].Sustained)
+	require.Contains(t, result.Steps[1].Reason, "newPayload latency")
+}
+
+func TestSaturationSearchStopsAtMinimumStep(t *testing.T) {
+	search := benchmark.NewSaturationSearch(benchmark.SearchDefinition{
+		MinGasLimit: 10_000_000,
+		MaxGasLimit: 12_000_000,
+	}, time.Second, false)
+
+	gasLimit, ok := search.Next()
+	require.True(t, ok)
+	require.Equal(t, uint64(11_000_000), gasLimit)
+	search.Record(gasLimit, "step-00", searchRunResult(float64(gasLimit), 0), nil)
+
+	_, ok = search.Next()
+	require.False(t, ok)
+	best, ok := search.Best()
+	require.True(t, ok)
+	require.Equal(t, "step-00", best.OutputDir)
+}
+
+func TestSaturationSearchUnsustainedRuns(t *testing.T) {
+	search := benchmark.NewSaturationSearch(benchmark.SearchDefinition{
+		MinGasLimit: 10_000_000,
+		MaxGasLimit: 100_000_000,
+	}, 2*time.Second, false)
+
+	step := search.Record(55_000_000, "", searchRunResult(11_000_000, 0), nil)
+	require.False(t, step.Sustained)
+	require.Equal(t, 0.2, step.BlockFill)
+	require.Equal(t, 5.5e6, step.GasPerSecond)
+	require.Contains(t, step.Reason, "20.0% full")
+
+	late := searchRunResult(50_000_000, 0)
+	// the average is well within the block time, but the slowest blocks miss it
+	late.SequencerMetrics.AverageFCULatency = 0.1
+	late.SequencerMetrics.AverageGetPayloadLatency = 0.4
+	late.SequencerMetrics.BuildLatencyP95 = 2.25
+	step = search.Record(50_000_000, "", late, nil)
+	require.False(t, step.Sustained)
+	require.Equal(t, 2.25, step.BuildLatency)
+	require.Contains(t, step.Reason, "misses the block time")
+
+	step = search.Record(30_000_000, "", nil, errors.New("node crashed"))
+	require.False(t, step.Sustained)
+	require.Equal(t, "run failed: node crashed", step.Reason)
+
+	_, ok := search.Best()
+	require.False(t, ok)
+	require.Zero(t, search.Result().MaxGasLimit)
+
+	gasLimit, ok := search.Next()
+	require.True(t, ok)
+	require.Equal(t, uint64(20_000_000), gasLimit)
+}
+
+func TestSearchDefinitionCheck(t *testing.T) {
+	iterations := 0
+	fill := 1.5
+	for _, definition := range []benchmark.SearchDefinition{
+		{MaxGasLimit: 100},
+		{MinGasLimit: 100, MaxGasLimit: 100},
+		{MinGasLimit: 100, MaxGasLimit: 200, Iterations: &iterations},
+		{MinGasLimit: 100, MaxGasLimit: 200, MinBlockFill: &fill},
+	} {
+		require.Error(t, definition.Check())
+	}
+	require.NoError(t, (&benchmark.SearchDefinition{MinGasLimit: 100, MaxGasLimit: 200}).Check())
+}
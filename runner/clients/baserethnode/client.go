@@ -157,7 +157,10 @@ func (r *BaseRethNodeClient) Run(ctx context.Context, cfg *types.RuntimeConfig)
 	}
 
 	r.client = ethclient.NewClient(rpcClient)
-	r.metricsCollector = newMetricsCollector(r.logger, r.client, int(r.metricsPort))
+	r.metricsCollector, err = newMetricsCollector(r.logger, int(r.metricsPort), r.options.Scrape, r.options.MetricsPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create metrics collector")
+	}
 
 	err = common.WaitForRPC(ctx, r.client)
 	if err != nil {
@@ -220,6 +223,11 @@ func (r *BaseRethNodeClient) AuthClient() client.RPC {
 	return r.authClient
 }
 
+// AuthURL returns the URL of the JWT-authenticated engine API.
+func (r *BaseRethNodeClient) AuthURL() string {
+	return fmt.Sprintf("http://127.0.0.1:%d", r.authRPCPort)
+}
+
 func (r *BaseRethNodeClient) MetricsPort() int {
 	return int(r.metricsPort)
 }
@@ -263,6 +271,11 @@ func (r *BaseRethNodeClient) SupportsFlashblocks() bool {
 	return true
 }
 
+// SupportsMillisecondTimestamps returns true as base-reth-node supports millisecond block timestamps.
+func (r *BaseRethNodeClient) SupportsMillisecondTimestamps() bool {
+	return true
+}
+
 func (r *BaseRethNodeClient) FlashblocksWsURL() string {
 	return ""
 }
@@ -2,7 +2,6 @@ package builder
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/ethereum-optimism/optimism/op-service/client"
@@ -63,9 +62,9 @@ func (r *BuilderClient) Run(ctx context.Context, cfg *types.RuntimeConfig) error
 		return err
 	}
 
-	r.metricsCollector = newMetricsCollector(r.logger, r.elClient.Client(), int(r.elClient.MetricsPort()))
-	if r.metricsCollector == nil {
-		return errors.New("failed to create metrics collector")
+	r.metricsCollector, err = newMetricsCollector(r.logger, int(r.elClient.MetricsPort()), r.options.Scrape, r.options.MetricsPath)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics collector: %w", err)
 	}
 
 	// Create flashblocks client
@@ -106,6 +105,11 @@ func (r *BuilderClient) AuthClient() client.RPC {
 	return r.elClient.AuthClient()
 }
 
+// AuthURL returns the URL of the JWT-authenticated engine API.
+func (r *BuilderClient) AuthURL() string {
+	return r.elClient.AuthURL()
+}
+
 func (r *BuilderClient) MetricsPort() int {
 	return r.elClient.MetricsPort()
 }
@@ -132,6 +136,11 @@ func (r *BuilderClient) SupportsFlashblocks() bool {
 	return false
 }
 
+// SupportsMillisecondTimestamps returns true as the builder supports millisecond block timestamps.
+func (r *BuilderClient) SupportsMillisecondTimestamps() bool {
+	return true
+}
+
 // FlashblocksWsURL returns the local WebSocket URL of the flashblocks server
 // hosted by the builder.
 func (r *BuilderClient) FlashblocksWsURL() string {
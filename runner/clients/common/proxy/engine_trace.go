@@ -0,0 +1,265 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// EngineTrace is one engine API call as seen by the EngineTraceProxy.
+type EngineTrace struct {
+	Method string    `json:"method"`
+	Start  time.Time `json:"start"`
+	// Duration is the time from sending the request upstream until its
+	// response was read, excluding the caller's JSON encoding and decoding.
+	Duration      time.Duration `json:"durationNs"`
+	RequestBytes  int           `json:"requestBytes"`
+	ResponseBytes int           `json:"responseBytes"`
+	HTTPStatus    int           `json:"httpStatus"`
+	// Status is the payload status of forkchoiceUpdated and newPayload
+	// responses, e.g. VALID or SYNCING.
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Phases are the sub-phase durations in milliseconds the client reported
+	// in a Server-Timing response header, if any.
+	Phases map[string]float64 `json:"phases,omitempty"`
+}
+
+// EngineTraceProxy forwards auth RPC requests to an execution client and
+// records the timing of every engine_* call. JWT auth headers are passed
+// through, so callers authenticate to the proxy as they would to the client.
+type EngineTraceProxy struct {
+	log         log.Logger
+	upstreamURL string
+	client      *http.Client
+	server      *http.Server
+	listener    net.Listener
+
+	mu     sync.Mutex
+	traces []EngineTrace
+	last   map[string]EngineTrace
+}
+
+// NewEngineTraceProxy creates a proxy in front of the auth RPC at
+// upstreamURL.
+func NewEngineTraceProxy(log log.Logger, upstreamURL string) *EngineTraceProxy {
+	return &EngineTraceProxy{
+		log:         log,
+		upstreamURL: upstreamURL,
+		// engine calls are sequential, keeping one connection alive avoids
+		// measuring connection setup
+		client: &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 1}},
+		last:   make(map[string]EngineTrace),
+	}
+}
+
+// Run starts serving on a free local port.
+func (p *EngineTraceProxy) Run() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	p.listener = listener
+	p.server = &http.Server{Handler: http.HandlerFunc(p.handleRequest)}
+
+	go func() {
+		if err := p.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			p.log.Error("Engine trace proxy error", "err", err)
+		}
+	}()
+	return nil
+}
+
+// URL returns the URL to send auth RPC requests to.
+func (p *EngineTraceProxy) URL() string {
+	return fmt.Sprintf("http://%s", p.listener.Addr())
+}
+
+// Stop stops the proxy.
+func (p *EngineTraceProxy) Stop() {
+	if p.server != nil {
+		if err := p.server.Close(); err != nil {
+			p.log.Error("Error closing engine trace proxy", "err", err)
+		}
+	}
+	p.client.CloseIdleConnections()
+}
+
+// Traces returns the engine calls recorded so far, in order.
+func (p *EngineTraceProxy) Traces() []EngineTrace {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]EngineTrace(nil), p.traces...)
+}
+
+// LastDuration returns the upstream duration of the last call of method.
+func (p *EngineTraceProxy) LastDuration(method string) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	trace, ok := p.last[method]
+	return trace.Duration, ok
+}
+
+// WriteFile writes the recorded engine calls to path as JSON lines.
+func (p *EngineTraceProxy) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create engine trace file: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, trace := range p.Traces() {
+		if err := enc.Encode(trace); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to write engine trace: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write engine trace: %w", err)
+	}
+	return f.Close()
+}
+
+func (p *EngineTraceProxy) handleRequest(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		return
+	}
+
+	// batches are forwarded without tracing, the engine API is never batched
+	var request rpcRequest
+	traced := len(body) > 0 && body[0] != '[' && json.Unmarshal(body, &request) == nil && strings.HasPrefix(request.Method, "engine_")
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, p.upstreamURL, bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, "Error creating request", http.StatusInternalServerError)
+		return
+	}
+	req.Header = r.Header.Clone()
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if traced {
+			p.record(EngineTrace{Method: request.Method, Start: start, Duration: time.Since(start), RequestBytes: len(body), Error: err.Error()})
+		}
+		http.Error(w, "Error forwarding request", http.StatusBadGateway)
+		return
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	duration := time.Since(start)
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		p.log.Error("Error closing response body", "err", closeErr)
+	}
+	if err != nil {
+		http.Error(w, "Error reading response body", http.StatusBadGateway)
+		return
+	}
+
+	if traced {
+		trace := EngineTrace{
+			Method:        request.Method,
+			Start:         start,
+			Duration:      duration,
+			RequestBytes:  len(body),
+			ResponseBytes: len(respBody),
+			HTTPStatus:    resp.StatusCode,
+			Phases:        parseServerTiming(resp.Header.Values("Server-Timing")),
+		}
+		trace.Status, trace.Error = engineResponseStatus(respBody)
+		p.record(trace)
+	}
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := w.Write(respBody); err != nil {
+		p.log.Error("Error writing response body", "err", err)
+	}
+}
+
+func (p *EngineTraceProxy) record(trace EngineTrace) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.traces = append(p.traces, trace)
+	p.last[trace.Method] = trace
+}
+
+// engineResponseStatus returns the payload status and the error of an engine
+// API response.
+func engineResponseStatus(body []byte) (string, string) {
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", ""
+	}
+	if resp.Error != nil {
+		return "", fmt.Sprintf("%d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	// forkchoiceUpdated nests the status, newPayload returns it directly and
+	// other results have none
+	var result struct {
+		Status        string `json:"status"`
+		PayloadStatus struct {
+			Status string `json:"status"`
+		} `json:"payloadStatus"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return "", ""
+	}
+	if result.PayloadStatus.Status != "" {
+		return result.PayloadStatus.Status, ""
+	}
+	return result.Status, ""
+}
+
+// parseServerTiming parses Server-Timing header values such as
+// "build;dur=12.5, seal;dur=0.8" into durations in milliseconds. Metrics
+// without a duration are skipped.
+func parseServerTiming(values []string) map[string]float64 {
+	var phases map[string]float64
+	for _, value := range values {
+		for _, metric := range strings.Split(value, ",") {
+			parts := strings.Split(metric, ";")
+			name := strings.TrimSpace(parts[0])
+			if name == "" {
+				continue
+			}
+			for _, param := range parts[1:] {
+				key, raw, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || key != "dur" {
+					continue
+				}
+				dur, err := strconv.ParseFloat(strings.Trim(raw, `"`), 64)
+				if err != nil {
+					continue
+				}
+				if phases == nil {
+					phases = make(map[string]float64)
+				}
+				phases[name] = dur
+			}
+		}
+	}
+	return phases
+}
@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func TestEngineTraceProxyRecordsEngineCalls(t *testing.T) {
+	var authHeader string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		w.Header().Set("Server-Timing", "build;dur=12.5, seal;dur=0.75, cache")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"payloadStatus":{"status":"VALID"},"payloadId":"0x01"}}`))
+	}))
+	defer upstream.Close()
+
+	p := NewEngineTraceProxy(log.New(), upstream.URL)
+	if err := p.Run(); err != nil {
+		t.Fatalf("run proxy: %v", err)
+	}
+	defer p.Stop()
+
+	post := func(body string) {
+		req, err := http.NewRequest(http.MethodPost, p.URL(), strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("create request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("send request: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+		_ = resp.Body.Close()
+	}
+
+	fcu := `{"jsonrpc":"2.0","id":1,"method":"engine_forkchoiceUpdatedV3","params":[]}`
+	post(fcu)
+	post(`{"jsonrpc":"2.0","id":2,"method":"eth_chainId","params":[]}`)
+
+	if authHeader != "Bearer token" {
+		t.Fatalf("expected auth header to be forwarded, got %q", authHeader)
+	}
+
+	traces := p.Traces()
+	if len(traces) != 1 {
+		t.Fatalf("expected only the engine call to be traced, got %d traces", len(traces))
+	}
+	trace := traces[0]
+	if trace.Method != "engine_forkchoiceUpdatedV3" || trace.Status != "VALID" || trace.HTTPStatus != http.StatusOK {
+		t.Fatalf("unexpected trace %+v", trace)
+	}
+	if trace.RequestBytes != len(fcu) || trace.ResponseBytes == 0 {
+		t.Fatalf("unexpected sizes %d/%d", trace.RequestBytes, trace.ResponseBytes)
+	}
+	if trace.Phases["build"] != 12.5 || trace.Phases["seal"] != 0.75 || len(trace.Phases) != 2 {
+		t.Fatalf("unexpected phases %v", trace.Phases)
+	}
+	if d, ok := p.LastDuration("engine_forkchoiceUpdatedV3"); !ok || d != trace.Duration {
+		t.Fatalf("expected last duration %s, got %s", trace.Duration, d)
+	}
+	if _, ok := p.LastDuration("engine_getPayloadV4"); ok {
+		t.Fatal("expected no duration for an uncalled method")
+	}
+
+	path := filepath.Join(t.TempDir(), "engine-trace.jsonl")
+	if err := p.WriteFile(path); err != nil {
+		t.Fatalf("write traces: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read traces: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 1 {
+		t.Fatalf("expected 1 line, got %d", lines)
+	}
+}
+
+func TestEngineResponseStatus(t *testing.T) {
+	tests := []struct {
+		body   string
+		status string
+		err    string
+	}{
+		{`{"result":{"status":"SYNCING","latestValidHash":null}}`, "SYNCING", ""},
+		{`{"result":{"payloadStatus":{"status":"INVALID"}}}`, "INVALID", ""},
+		{`{"result":["engine_newPayloadV4"]}`, "", ""},
+		{`{"error":{"code":-38001,"message":"Unknown payload"}}`, "", "-38001: Unknown payload"},
+	}
+	for _, tt := range tests {
+		status, err := engineResponseStatus([]byte(tt.body))
+		if status != tt.status || err != tt.err {
+			t.Fatalf("%s: expected %q/%q, got %q/%q", tt.body, tt.status, tt.err, status, err)
+		}
+	}
+}
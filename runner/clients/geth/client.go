@@ -164,7 +164,10 @@ func (g *GethClient) Run(ctx context.Context, cfg *types.RuntimeConfig) error {
 	}
 
 	g.client = ethclient.NewClient(rpcClient)
-	g.metricsCollector = newMetricsCollector(g.logger, g.client, int(g.metricsPort))
+	g.metricsCollector, err = newMetricsCollector(g.logger, int(g.metricsPort), g.options.Scrape, g.options.MetricsPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create metrics collector")
+	}
 
 	err = common.WaitForRPC(ctx, g.client)
 	if err != nil {
@@ -225,6 +228,11 @@ func (g *GethClient) AuthClient() client.RPC {
 	return g.authClient
 }
 
+// AuthURL returns the URL of the JWT-authenticated engine API.
+func (g *GethClient) AuthURL() string {
+	return fmt.Sprintf("http://127.0.0.1:%d", g.authRPCPort)
+}
+
 func (r *GethClient) MetricsPort() int {
 	return int(r.metricsPort)
 }
@@ -281,6 +289,11 @@ func (g *GethClient) SupportsFlashblocks() bool {
 	return false
 }
 
+// SupportsMillisecondTimestamps returns false as geth only supports whole-second block timestamps.
+func (g *GethClient) SupportsMillisecondTimestamps() bool {
+	return false
+}
+
 func (g *GethClient) FlashblocksWsURL() string {
 	return ""
 }
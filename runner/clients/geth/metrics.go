@@ -4,27 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"github.com/base/base-bench/runner/metrics"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// metricsCollector reads geth's JSON metrics endpoint, which already reports
+// percentiles, instead of using the shared Prometheus collector.
 type metricsCollector struct {
 	log         log.Logger
-	client      *ethclient.Client
 	metrics     []metrics.BlockMetrics
 	metricsPort int
+	selection   *metrics.MetricSelection
+	archive     *metrics.ScrapeArchive
 }
 
-func newMetricsCollector(log log.Logger, client *ethclient.Client, metricsPort int) metrics.Collector {
+func newMetricsCollector(log log.Logger, metricsPort int, scrape *metrics.ScrapeConfig, metricsDir string) (metrics.Collector, error) {
+	selection, err := metrics.NewMetricSelection(scrape)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric selection: %w", err)
+	}
+
 	return &metricsCollector{
 		log:         log,
-		client:      client,
 		metricsPort: metricsPort,
 		metrics:     make([]metrics.BlockMetrics, 0),
-	}
+		selection:   selection,
+		archive:     metrics.NewScrapeArchive(scrape, metricsDir),
+	}, nil
 }
 
 func (g *metricsCollector) GetMetricTypes() map[string]bool {
@@ -50,34 +57,51 @@ func (g *metricsCollector) GetMetricsEndpoint() string {
 	return fmt.Sprintf("http://127.0.0.1:%d/debug/metrics", g.metricsPort)
 }
 
+// GetPrometheusEndpoint returns geth's Prometheus exposition endpoint, which
+// is only scraped when archiving is enabled.
+func (g *metricsCollector) GetPrometheusEndpoint() string {
+	return fmt.Sprintf("http://127.0.0.1:%d/debug/metrics/prometheus", g.metricsPort)
+}
+
 func (g *metricsCollector) GetMetrics() []metrics.BlockMetrics {
 	return g.metrics
 }
 
-func (g *metricsCollector) Collect(ctx context.Context, metrics *metrics.BlockMetrics) error {
-	resp, err := http.Get(g.GetMetricsEndpoint())
+func (g *metricsCollector) Collect(ctx context.Context, m *metrics.BlockMetrics) error {
+	body, _, err := metrics.FetchMetrics(ctx, g.GetMetricsEndpoint())
 	if err != nil {
-		return fmt.Errorf("failed to get metrics: %w", err)
+		return err
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
 
 	var metricsData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&metricsData); err != nil {
+	if err := json.Unmarshal(body, &metricsData); err != nil {
 		return fmt.Errorf("failed to decode metrics: %w", err)
 	}
 
 	metricTypes := g.GetMetricTypes()
 	for name, value := range metricsData {
-		if !metricTypes[name] {
+		if !metricTypes[name] && !g.selection.Matches(name, nil) {
 			continue
 		}
 		if v, ok := value.(float64); ok {
-			metrics.AddExecutionMetric(name, v)
+			m.AddExecutionMetric(name, v)
 		}
 	}
 
-	g.metrics = append(g.metrics, *metrics.Copy())
+	if g.archive != nil {
+		if err := g.archiveScrape(ctx, m.BlockNumber); err != nil {
+			g.log.Warn("failed to archive metrics scrape", "error", err)
+		}
+	}
+
+	g.metrics = append(g.metrics, *m.Copy())
 	return nil
 }
+
+func (g *metricsCollector) archiveScrape(ctx context.Context, blockNumber uint64) error {
+	body, contentType, err := metrics.FetchMetrics(ctx, g.GetPrometheusEndpoint())
+	if err != nil {
+		return err
+	}
+	return g.archive.Write(blockNumber, contentType, body)
+}
@@ -170,7 +170,10 @@ func (r *RethClient) Run(ctx context.Context, cfg *types.RuntimeConfig) error {
 	}
 
 	r.client = ethclient.NewClient(rpcClient)
-	r.metricsCollector = newMetricsCollector(r.logger, r.client, int(r.metricsPort))
+	r.metricsCollector, err = newMetricsCollector(r.logger, int(r.metricsPort), r.options.Scrape, r.options.MetricsPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create metrics collector")
+	}
 
 	err = common.WaitForRPC(ctx, r.client)
 	if err != nil {
@@ -233,6 +236,11 @@ func (r *RethClient) AuthClient() client.RPC {
 	return r.authClient
 }
 
+// AuthURL returns the URL of the JWT-authenticated engine API.
+func (r *RethClient) AuthURL() string {
+	return fmt.Sprintf("http://127.0.0.1:%d", r.authRPCPort)
+}
+
 func (r *RethClient) MetricsPort() int {
 	return int(r.metricsPort)
 }
@@ -279,6 +287,11 @@ func (r *RethClient) SupportsFlashblocks() bool {
 	return true
 }
 
+// SupportsMillisecondTimestamps returns false as reth only supports whole-second block timestamps.
+func (r *RethClient) SupportsMillisecondTimestamps() bool {
+	return false
+}
+
 func (r *RethClient) FlashblocksWsURL() string {
 	return ""
 }
@@ -27,12 +27,17 @@ type ExecutionClient interface {
 	Client() *ethclient.Client
 	ClientURL() string // needed for external transaction payload workers
 	AuthClient() client.RPC
+	// AuthURL returns the URL of the JWT-authenticated engine API.
+	AuthURL() string
 	MetricsPort() int
 	MetricsCollector() metrics.Collector
 	GetVersion(ctx context.Context) (string, error)
 	SetHead(ctx context.Context, blockNumber uint64) error
 	FlashblocksClient() FlashblocksClient // returns nil for clients that don't support flashblocks
 	SupportsFlashblocks() bool            // returns true if the client supports receiving flashblock payloads
+	// SupportsMillisecondTimestamps returns true if the client's chain accepts
+	// payload timestamps in milliseconds.
+	SupportsMillisecondTimestamps() bool
 	// FlashblocksWsURL returns the local WebSocket URL hosted by this client,
 	// or an empty string when the client does not expose one.
 	FlashblocksWsURL() string
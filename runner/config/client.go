@@ -9,6 +9,7 @@ import (
 	gethoptions "github.com/base/base-bench/runner/clients/geth/options"
 	rethoptions "github.com/base/base-bench/runner/clients/reth/options"
 	"github.com/base/base-bench/runner/flags"
+	"github.com/base/base-bench/runner/metrics"
 )
 
 // ClientOptions is the common options object that gets passed to execution clients.
@@ -31,6 +32,9 @@ type InternalClientOptions struct {
 	TestDirPath   string
 	JWTSecret     string
 	MetricsPath   string
+
+	// Scrape configures additional metric selection and scrape archiving.
+	Scrape *metrics.ScrapeConfig
 }
 
 type PortOverrides map[string]map[portmanager.PortPurpose]uint64
@@ -28,6 +28,12 @@ type Config interface {
 	MachineRegion() string
 	FileSystem() string
 	ParallelTxBatches() int
+	MetricsPort() int
+	ResultSink() string
+	ResultSinkToken() string
+	S3Endpoint() string
+	S3Region() string
+	EngineTrace() bool
 }
 
 type config struct {
@@ -45,6 +51,12 @@ type config struct {
 	machineRegion     string
 	fileSystem        string
 	parallelTxBatches int
+	metricsPort       int
+	resultSink        string
+	resultSinkToken   string
+	s3Endpoint        string
+	s3Region          string
+	engineTrace       bool
 }
 
 func NewConfig(ctx *cli.Context) Config {
@@ -62,6 +74,12 @@ func NewConfig(ctx *cli.Context) Config {
 		machineRegion:     ctx.String(appFlags.MachineRegionFlagName),
 		fileSystem:        ctx.String(appFlags.FileSystemFlagName),
 		parallelTxBatches: ctx.Int(appFlags.ParallelTxBatchesFlagName),
+		metricsPort:       ctx.Int(appFlags.MetricsPortFlagName),
+		resultSink:        ctx.String(appFlags.ResultSinkFlagName),
+		resultSinkToken:   ctx.String(appFlags.ResultSinkTokenFlagName),
+		s3Endpoint:        ctx.String(appFlags.S3EndpointFlagName),
+		s3Region:          ctx.String(appFlags.S3RegionFlagName),
+		engineTrace:       ctx.Bool(appFlags.EngineTraceFlagName),
 		clientOptions:     ReadClientOptions(ctx),
 	}
 }
@@ -142,3 +160,27 @@ func (c *config) FileSystem() string {
 func (c *config) ParallelTxBatches() int {
 	return c.parallelTxBatches
 }
+
+func (c *config) MetricsPort() int {
+	return c.metricsPort
+}
+
+func (c *config) ResultSink() string {
+	return c.resultSink
+}
+
+func (c *config) ResultSinkToken() string {
+	return c.resultSinkToken
+}
+
+func (c *config) S3Endpoint() string {
+	return c.s3Endpoint
+}
+
+func (c *config) S3Region() string {
+	return c.s3Region
+}
+
+func (c *config) EngineTrace() bool {
+	return c.engineTrace
+}
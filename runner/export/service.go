@@ -0,0 +1,292 @@
+// Package export flattens benchmark output into tidy tables for analysis
+// tools such as pandas or DuckDB.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/base/base-bench/benchmark/config"
+	"github.com/base/base-bench/runner/benchmark"
+	"github.com/base/base-bench/runner/metrics"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// baseColumns come first in every export. They are followed by one column
+// per test config dimension and then one column per block metric, each
+// sorted by name.
+var baseColumns = []string{
+	"run_id",
+	"test_name",
+	"output_dir",
+	"created_at",
+	"success",
+	"role",
+	"block_number",
+	"timestamp",
+}
+
+// ExportResult summarizes a completed export
+type ExportResult struct {
+	File    string
+	Runs    int
+	Rows    int
+	Columns int
+}
+
+// metricsFile is the per-block metrics of one role of a run
+type metricsFile struct {
+	run  *benchmark.Run
+	role string
+	path string
+}
+
+// Service exports the runs of an output directory
+type Service struct {
+	config *config.ExportCmdConfig
+	log    log.Logger
+}
+
+// NewService creates a new export service
+func NewService(cfg *config.ExportCmdConfig, log log.Logger) *Service {
+	return &Service{
+		config: cfg,
+		log:    log,
+	}
+}
+
+// Export writes one row per run, role and block of the output directory.
+func (s *Service) Export() (*ExportResult, error) {
+	metadataPath := path.Join(s.config.OutputDir(), "metadata.json")
+	metadata, err := benchmark.NewMetadataFile(metadataPath).Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata.Runs) == 0 {
+		return nil, fmt.Errorf("no runs found in %s", metadataPath)
+	}
+	s.log.Info("Loaded metadata", "path", metadataPath, "runs", len(metadata.Runs))
+
+	files, err := s.findMetricsFiles(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// Block metrics are read twice so that the full set of columns is known
+	// before the first row is written without holding every run in memory.
+	configKeys := make(map[string]struct{})
+	metricKeys := make(map[string]struct{})
+	for _, f := range files {
+		for key := range f.run.TestConfig {
+			configKeys[key] = struct{}{}
+		}
+		blocks, err := readBlockMetrics(f.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			for key := range block.ExecutionMetrics {
+				metricKeys[key] = struct{}{}
+			}
+		}
+	}
+
+	file := s.config.File()
+	out, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create export file")
+	}
+	defer func() { _ = out.Close() }()
+
+	table := newTable(out, sortedKeys(configKeys), sortedKeys(metricKeys))
+	if err := table.writeHeader(); err != nil {
+		return nil, err
+	}
+
+	runs := make(map[string]struct{})
+	for _, f := range files {
+		blocks, err := readBlockMetrics(f.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			if err := table.writeRow(f.run, f.role, &block); err != nil {
+				return nil, err
+			}
+		}
+		runs[f.run.ID] = struct{}{}
+	}
+
+	if err := table.flush(); err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close export file")
+	}
+
+	s.log.Info("Exported block metrics", "file", file, "rows", table.rows)
+	return &ExportResult{
+		File:    file,
+		Runs:    len(runs),
+		Rows:    table.rows,
+		Columns: len(table.columns),
+	}, nil
+}
+
+// findMetricsFiles returns the metrics-<role>.json files of every run in the
+// metadata, in metadata order.
+func (s *Service) findMetricsFiles(metadata *benchmark.RunGroup) ([]metricsFile, error) {
+	var files []metricsFile
+	for i := range metadata.Runs {
+		run := &metadata.Runs[i]
+		if run.OutputDir == "" {
+			continue
+		}
+
+		matches, err := filepath.Glob(path.Join(s.config.OutputDir(), run.OutputDir, "metrics-*.json"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list metrics files")
+		}
+		if len(matches) == 0 {
+			s.log.Warn("Run has no metrics files", "runID", run.ID, "outputDir", run.OutputDir)
+			continue
+		}
+
+		sort.Strings(matches)
+		for _, match := range matches {
+			role := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), "metrics-"), ".json")
+			files = append(files, metricsFile{run: run, role: role, path: match})
+		}
+	}
+	return files, nil
+}
+
+func readBlockMetrics(filename string) ([]metrics.BlockMetrics, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read metrics file")
+	}
+
+	var blocks []metrics.BlockMetrics
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode metrics file %s", filename)
+	}
+	return blocks, nil
+}
+
+func sortedKeys(keys map[string]struct{}) []string {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// table writes rows with a fixed set of columns as CSV.
+type table struct {
+	w          *csv.Writer
+	configKeys []string
+	metricKeys []string
+	columns    []string
+	rows       int
+}
+
+func newTable(w io.Writer, configKeys []string, metricKeys []string) *table {
+	columns := make([]string, 0, len(baseColumns)+len(configKeys)+len(metricKeys))
+	columns = append(columns, baseColumns...)
+	columns = append(columns, configKeys...)
+	columns = append(columns, metricKeys...)
+	return &table{
+		w:          csv.NewWriter(w),
+		configKeys: configKeys,
+		metricKeys: metricKeys,
+		columns:    columns,
+	}
+}
+
+func (t *table) writeHeader() error {
+	if err := t.w.Write(t.columns); err != nil {
+		return errors.Wrap(err, "failed to write export header")
+	}
+	return nil
+}
+
+func (t *table) writeRow(run *benchmark.Run, role string, block *metrics.BlockMetrics) error {
+	row := make([]string, 0, len(t.columns))
+
+	createdAt := ""
+	if run.CreatedAt != nil {
+		createdAt = run.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	success := ""
+	if run.Result != nil {
+		success = strconv.FormatBool(run.Result.Success)
+	}
+	timestamp := ""
+	if !block.Timestamp.IsZero() {
+		timestamp = block.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+
+	row = append(row,
+		run.ID,
+		run.TestName,
+		run.OutputDir,
+		createdAt,
+		success,
+		role,
+		strconv.FormatUint(block.BlockNumber, 10),
+		timestamp,
+	)
+	for _, key := range t.configKeys {
+		row = append(row, formatValue(run.TestConfig[key]))
+	}
+	for _, key := range t.metricKeys {
+		row = append(row, formatValue(block.ExecutionMetrics[key]))
+	}
+
+	if err := t.w.Write(row); err != nil {
+		return errors.Wrap(err, "failed to write export row")
+	}
+	t.rows++
+	return nil
+}
+
+func (t *table) flush() error {
+	t.w.Flush()
+	if err := t.w.Error(); err != nil {
+		return errors.Wrap(err, "failed to write export file")
+	}
+	return nil
+}
+
+// formatValue renders a JSON-decoded value as a CSV cell. Missing values are
+// empty so they load as nulls.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
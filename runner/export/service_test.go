@@ -0,0 +1,88 @@
+package export
+
+import (
+	"encoding/csv"
+	"flag"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/base/base-bench/benchmark/config"
+	"github.com/base/base-bench/benchmark/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func writeFile(t *testing.T, filename string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(path.Dir(filename), 0755))
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+}
+
+func newTestService(t *testing.T, outputDir string) *Service {
+	set := flag.NewFlagSet("export", flag.ContinueOnError)
+	set.String(flags.OutputDirFlagName, outputDir, "")
+	set.String(flags.ExportFileFlagName, "", "")
+	set.String(flags.ExportFormatFlagName, config.ExportFormatCSV, "")
+
+	cfg := config.NewExportCmdConfig(cli.NewContext(cli.NewApp(), set, nil))
+	require.NoError(t, cfg.Check())
+	return NewService(cfg, log.New())
+}
+
+func TestExportJoinsTestConfigWithBlockMetrics(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, path.Join(dir, "metadata.json"), `{"runs": [
+		{"id": "a", "outputDir": "a-1", "testName": "Transfers", "createdAt": "2025-01-01T00:00:00Z",
+		 "testConfig": {"BenchmarkRun": "run-1", "GasLimit": 100, "NodeType": "reth"}, "result": {"success": true}},
+		{"id": "b", "outputDir": "b-1", "testName": "Transfers",
+		 "testConfig": {"BenchmarkRun": "run-1", "GasLimit": 200, "NodeType": "geth", "Roles": "sequencer"}},
+		{"id": "c", "outputDir": "c-1", "testName": "Transfers", "testConfig": {}}
+	]}`)
+	writeFile(t, path.Join(dir, "a-1", "metrics-sequencer.json"), `[
+		{"BlockNumber": 1, "Timestamp": "2025-01-01T00:00:01Z", "ExecutionMetrics": {"latency/get_payload": 4500000, "gas/per_second": 1.5}},
+		{"BlockNumber": 2, "Timestamp": "2025-01-01T00:00:02Z", "ExecutionMetrics": {"latency/get_payload": 4600000}}
+	]`)
+	writeFile(t, path.Join(dir, "a-1", "metrics-validator.json"), `[
+		{"BlockNumber": 1, "ExecutionMetrics": {"latency/new_payload": 2000000}}
+	]`)
+	writeFile(t, path.Join(dir, "b-1", "metrics-sequencer.json"), `[
+		{"BlockNumber": 1, "ExecutionMetrics": {"latency/get_payload": 1e3}}
+	]`)
+
+	result, err := newTestService(t, dir).Export()
+	require.NoError(t, err)
+	require.Equal(t, path.Join(dir, "metrics.csv"), result.File)
+	require.Equal(t, 2, result.Runs)
+	require.Equal(t, 4, result.Rows)
+
+	f, err := os.Open(result.File)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"run_id", "test_name", "output_dir", "created_at", "success", "role", "block_number", "timestamp",
+		"BenchmarkRun", "GasLimit", "NodeType", "Roles",
+		"gas/per_second", "latency/get_payload", "latency/new_payload",
+	}, records[0])
+	require.Equal(t, []string{
+		"a", "Transfers", "a-1", "2025-01-01T00:00:00Z", "true", "sequencer", "1", "2025-01-01T00:00:01Z",
+		"run-1", "100", "reth", "",
+		"1.5", "4500000", "",
+	}, records[1])
+	require.Equal(t, "validator", records[3][5])
+	require.Equal(t, "2000000", records[3][14])
+	require.Equal(t, []string{
+		"b", "Transfers", "b-1", "", "", "sequencer", "1", "",
+		"run-1", "200", "geth", "sequencer",
+		"", "1000", "",
+	}, records[4])
+}
+
+func TestExportFailsWithoutMetadata(t *testing.T) {
+	_, err := newTestService(t, t.TempDir()).Export()
+	require.Error(t, err)
+}
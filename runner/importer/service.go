@@ -184,24 +184,17 @@ func (s *Service) LoadDestinationMetadata() (*benchmark.RunGroup, error) {
 	metadataPath := path.Join(s.config.OutputDir(), "metadata.json")
 	s.log.Info("Loading destination metadata", "path", metadataPath)
 
-	file, err := os.Open(metadataPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// If file doesn't exist, return empty metadata
-			s.log.Info("Destination metadata file does not exist, creating new one")
-			return &benchmark.RunGroup{Runs: []benchmark.Run{}}, nil
-		}
-		return nil, errors.Wrap(err, "failed to open destination metadata file")
+	if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
+		s.log.Info("Destination metadata file does not exist, creating new one")
 	}
-	defer func() { _ = file.Close() }()
 
-	var metadata benchmark.RunGroup
-	if err := json.NewDecoder(file).Decode(&metadata); err != nil {
-		return nil, errors.Wrap(err, "failed to decode destination metadata JSON")
+	metadata, err := s.metadataFile().Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load destination metadata")
 	}
 
 	s.log.Info("Loaded destination metadata", "runs", len(metadata.Runs))
-	return &metadata, nil
+	return metadata, nil
 }
 
 // getLastBenchmarkRunID gets the BenchmarkRun ID from the run with the latest CreatedAt timestamp
@@ -419,51 +412,78 @@ func (s *Service) MergeMetadata(srcMetadata, destMetadata *benchmark.RunGroup, s
 
 // WriteMetadata writes the merged metadata back to the output file
 func (s *Service) WriteMetadata(metadata *benchmark.RunGroup) error {
-	metadataPath := path.Join(s.config.OutputDir(), "metadata.json")
-	s.log.Info("Writing merged metadata", "path", metadataPath, "runs", len(metadata.Runs))
+	return s.updateMetadata(func(current *benchmark.RunGroup) error {
+		*current = *metadata
+		return nil
+	})
+}
 
-	// Create backup of existing file
-	backupPath := metadataPath + ".backup." + fmt.Sprintf("%d", time.Now().Unix())
-	if _, err := os.Stat(metadataPath); err == nil {
-		if err := os.Rename(metadataPath, backupPath); err != nil {
-			s.log.Warn("Failed to create backup", "error", err)
-		} else {
-			s.log.Info("Created backup", "path", backupPath)
+// updateMetadata applies fn to the destination metadata while holding its
+// lock, backs up the existing file and atomically replaces it with the result.
+func (s *Service) updateMetadata(fn func(current *benchmark.RunGroup) error) error {
+	metadataFile := s.metadataFile()
+
+	err := metadataFile.Update(func(current *benchmark.RunGroup) error {
+		if err := fn(current); err != nil {
+			return err
 		}
+		s.log.Info("Writing merged metadata", "path", metadataFile.Path(), "runs", len(current.Runs))
+		s.backupMetadata(metadataFile.Path())
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Write new metadata
-	file, err := os.OpenFile(metadataPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	s.log.Info("Successfully wrote merged metadata")
+	return nil
+}
+
+// backupMetadata copies the existing metadata file aside before it is
+// replaced. It must be called with the metadata lock held.
+func (s *Service) backupMetadata(metadataPath string) {
+	data, err := os.ReadFile(metadataPath)
+	if os.IsNotExist(err) {
+		return
+	}
 	if err != nil {
-		return errors.Wrap(err, "failed to create metadata file")
+		s.log.Warn("Failed to create backup", "error", err)
+		return
 	}
-	defer func() { _ = file.Close() }()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(metadata); err != nil {
-		return errors.Wrap(err, "failed to encode metadata JSON")
+	backupPath := metadataPath + ".backup." + fmt.Sprintf("%d", time.Now().Unix())
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		s.log.Warn("Failed to create backup", "error", err)
+		return
 	}
+	s.log.Info("Created backup", "path", backupPath)
+}
 
-	s.log.Info("Successfully wrote merged metadata")
-	return nil
+func (s *Service) metadataFile() *benchmark.MetadataFile {
+	return benchmark.NewMetadataFile(path.Join(s.config.OutputDir(), "metadata.json"))
 }
 
 // Import performs the complete import operation
 func (s *Service) Import(request *ImportRequest) (*ImportResult, error) {
 	s.log.Info("Starting import operation")
 
-	// Merge metadata
-	mergedMetadata, summary := s.MergeMetadata(
-		request.SourceMetadata,
-		request.DestMetadata,
-		request.SrcTag,
-		request.DestTag,
-		request.BenchmarkRunOpt,
-	)
-
-	// Write merged metadata
-	if err := s.WriteMetadata(mergedMetadata); err != nil {
+	// Merge against the destination metadata as it is on disk once locked,
+	// since another process may have written it after request.DestMetadata
+	// was loaded.
+	var mergedMetadata *benchmark.RunGroup
+	var summary *ImportSummary
+	err := s.updateMetadata(func(current *benchmark.RunGroup) error {
+		mergedMetadata, summary = s.MergeMetadata(
+			request.SourceMetadata,
+			current,
+			request.SrcTag,
+			request.DestTag,
+			request.BenchmarkRunOpt,
+		)
+		*current = *mergedMetadata
+		return nil
+	})
+	if err != nil {
 		return &ImportResult{
 			Success: false,
 			Error:   err,
@@ -0,0 +1,214 @@
+// Package exporter publishes live benchmark progress on a Prometheus
+// /metrics endpoint so runs can be watched alongside client dashboards.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/base/base-bench/runner/metrics"
+	benchtypes "github.com/base/base-bench/runner/network/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "base_bench"
+
+// MetricsPath is the HTTP path the exporter serves metrics on.
+const MetricsPath = "/metrics"
+
+const latencyPrefix = "latency/"
+
+// RunInfo identifies the benchmark run that is currently executing.
+type RunInfo struct {
+	RunID          string
+	BenchmarkRunID string
+	NodeType       string
+	Payload        string
+}
+
+// Exporter implements metrics.BlockObserver by updating Prometheus metrics
+// for each observed block.
+type Exporter struct {
+	log      log.Logger
+	registry *prometheus.Registry
+
+	runInfo      *prometheus.GaugeVec
+	runStart     prometheus.Gauge
+	blockIndex   *prometheus.GaugeVec
+	blocks       *prometheus.CounterVec
+	latency      *prometheus.GaugeVec
+	latencyHist  *prometheus.HistogramVec
+	gasPerSecond *prometheus.GaugeVec
+	gasPerBlock  *prometheus.GaugeVec
+	txsPerBlock  *prometheus.GaugeVec
+	pendingTxs   prometheus.Gauge
+
+	server *http.Server
+}
+
+var _ metrics.BlockObserver = (*Exporter)(nil)
+
+// NewExporter creates an exporter with its own registry.
+func NewExporter(log log.Logger) *Exporter {
+	e := &Exporter{
+		log:      log,
+		registry: prometheus.NewRegistry(),
+		runInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "run_info",
+			Help:      "Information about the benchmark run currently executing. Always 1.",
+		}, []string{"run_id", "benchmark_run_id", "node_type", "payload"}),
+		runStart: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "run_start_timestamp_seconds",
+			Help:      "Unix time the current benchmark run started.",
+		}),
+		blockIndex: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "block_index",
+			Help:      "Index of the last benchmark block processed in the current run.",
+		}, []string{"role"}),
+		blocks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "blocks_total",
+			Help:      "Number of benchmark blocks processed.",
+		}, []string{"role"}),
+		latency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_block_latency_seconds",
+			Help:      "Engine API latency of the last block by call.",
+		}, []string{"role", "call"}),
+		latencyHist: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "block_latency_seconds",
+			Help:      "Distribution of engine API latency per block by call.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		}, []string{"role", "call"}),
+		gasPerSecond: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "gas_per_second",
+			Help:      "Gas per second of the last block.",
+		}, []string{"role"}),
+		gasPerBlock: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "gas_per_block",
+			Help:      "Gas used by the last block.",
+		}, []string{"role"}),
+		txsPerBlock: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "transactions_per_block",
+			Help:      "Transactions in the last block.",
+		}, []string{"role"}),
+		pendingTxs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_transactions",
+			Help:      "Transactions sent by the payload worker that are still pending in the sequencer mempool.",
+		}),
+	}
+
+	e.registry.MustRegister(
+		e.runInfo,
+		e.runStart,
+		e.blockIndex,
+		e.blocks,
+		e.latency,
+		e.latencyHist,
+		e.gasPerSecond,
+		e.gasPerBlock,
+		e.txsPerBlock,
+		e.pendingTxs,
+	)
+	return e
+}
+
+// Handler returns the HTTP handler serving the registry in the Prometheus
+// text or OpenMetrics format, depending on content negotiation.
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
+
+// Start serves metrics on the given address in the background.
+func (e *Exporter) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, e.Handler())
+	e.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := e.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.log.Error("Metrics server stopped", "err", err)
+		}
+	}()
+
+	e.log.Info("Serving benchmark metrics", "addr", listener.Addr().String(), "path", MetricsPath)
+	return nil
+}
+
+// Stop shuts down the metrics server if it was started.
+func (e *Exporter) Stop(ctx context.Context) error {
+	if e.server == nil {
+		return nil
+	}
+	return e.server.Shutdown(ctx)
+}
+
+// SetRun marks the start of a new benchmark run and resets per-run metrics.
+func (e *Exporter) SetRun(info RunInfo) {
+	e.runInfo.Reset()
+	e.runInfo.WithLabelValues(info.RunID, info.BenchmarkRunID, info.NodeType, info.Payload).Set(1)
+	e.runStart.SetToCurrentTime()
+
+	e.blockIndex.Reset()
+	e.latency.Reset()
+	e.gasPerSecond.Reset()
+	e.gasPerBlock.Reset()
+	e.txsPerBlock.Reset()
+	e.pendingTxs.Set(0)
+}
+
+// ObserveBlock publishes the metrics of a single block.
+func (e *Exporter) ObserveBlock(role string, m *metrics.BlockMetrics) {
+	e.blockIndex.WithLabelValues(role).Set(float64(m.BlockNumber))
+	e.blocks.WithLabelValues(role).Inc()
+
+	for name, value := range m.ExecutionMetrics {
+		if !strings.HasPrefix(name, latencyPrefix) {
+			continue
+		}
+		d, ok := value.(time.Duration)
+		if !ok {
+			continue
+		}
+		call := strings.TrimPrefix(name, latencyPrefix)
+		e.latency.WithLabelValues(role, call).Set(d.Seconds())
+		e.latencyHist.WithLabelValues(role, call).Observe(d.Seconds())
+	}
+
+	if v, ok := m.GetMetricFloat(benchtypes.GasPerSecondMetric); ok {
+		e.gasPerSecond.WithLabelValues(role).Set(v)
+	}
+	if v, ok := m.GetMetricFloat(benchtypes.GasPerBlockMetric); ok {
+		e.gasPerBlock.WithLabelValues(role).Set(v)
+	}
+	if v, ok := m.GetMetricFloat(benchtypes.TransactionsPerBlockMetric); ok {
+		e.txsPerBlock.WithLabelValues(role).Set(v)
+	}
+	if v, ok := m.GetMetricFloat(benchtypes.PendingTransactionsMetric); ok {
+		e.pendingTxs.Set(v)
+	}
+}
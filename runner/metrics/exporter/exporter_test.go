@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/base/base-bench/runner/metrics"
+	benchtypes "github.com/base/base-bench/runner/network/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveBlock(t *testing.T) {
+	e := NewExporter(log.New())
+	e.SetRun(RunInfo{RunID: "run-1", BenchmarkRunID: "bench-1", NodeType: "geth", Payload: "transfer-only"})
+
+	m := metrics.NewBlockMetrics()
+	m.SetBlockNumber(3)
+	m.AddExecutionMetric(benchtypes.UpdateForkChoiceLatencyMetric, 20*time.Millisecond)
+	m.AddExecutionMetric(benchtypes.GetPayloadLatencyMetric, 150*time.Millisecond)
+	m.AddExecutionMetric(benchtypes.GasPerSecondMetric, 1.5e8)
+	m.AddExecutionMetric(benchtypes.GasPerBlockMetric, 3e7)
+	m.AddExecutionMetric(benchtypes.PendingTransactionsMetric, 42.0)
+	e.ObserveBlock("sequencer", m)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(e.runInfo.WithLabelValues("run-1", "bench-1", "geth", "transfer-only")))
+	require.Equal(t, 3.0, testutil.ToFloat64(e.blockIndex.WithLabelValues("sequencer")))
+	require.Equal(t, 1.0, testutil.ToFloat64(e.blocks.WithLabelValues("sequencer")))
+	require.InDelta(t, 0.02, testutil.ToFloat64(e.latency.WithLabelValues("sequencer", "update_fork_choice")), 1e-9)
+	require.InDelta(t, 0.15, testutil.ToFloat64(e.latency.WithLabelValues("sequencer", "get_payload")), 1e-9)
+	require.Equal(t, 1.5e8, testutil.ToFloat64(e.gasPerSecond.WithLabelValues("sequencer")))
+	require.Equal(t, 42.0, testutil.ToFloat64(e.pendingTxs))
+	require.Equal(t, 2, testutil.CollectAndCount(e.latencyHist))
+}
+
+func TestSetRunResetsPerRunMetrics(t *testing.T) {
+	e := NewExporter(log.New())
+	e.SetRun(RunInfo{RunID: "run-1"})
+
+	m := metrics.NewBlockMetrics()
+	m.SetBlockNumber(10)
+	m.AddExecutionMetric(benchtypes.NewPayloadLatencyMetric, time.Second)
+	e.ObserveBlock("validator", m)
+
+	e.SetRun(RunInfo{RunID: "run-2"})
+	require.Equal(t, 1, testutil.CollectAndCount(e.runInfo))
+	require.Equal(t, 0, testutil.CollectAndCount(e.blockIndex))
+	require.Equal(t, 0, testutil.CollectAndCount(e.latency))
+	// Counters and histograms accumulate across runs.
+	require.Equal(t, 1, testutil.CollectAndCount(e.blocks))
+}
+
+func TestHandlerServesMetrics(t *testing.T) {
+	e := NewExporter(log.New())
+	e.SetRun(RunInfo{RunID: "run-1"})
+
+	server := httptest.NewServer(e.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, string(body), `base_bench_run_info{benchmark_run_id="",node_type="",payload="",run_id="run-1"} 1`)
+}
@@ -27,7 +27,10 @@ type BlockMetrics struct {
 }
 
 // PrometheusMetricSample preserves a raw Prometheus sample alongside the
-// flattened ExecutionMetrics values used by the report UI.
+// flattened ExecutionMetrics values used by the report UI. For histograms and
+// summaries, Mean is the average observation since the previous block and
+// Quantiles are keyed like "0_99"; histogram quantiles are estimated from the
+// bucket counts observed since the previous block.
 type PrometheusMetricSample struct {
 	Name       string             `json:"name"`
 	Key        string             `json:"key,omitempty"`
@@ -35,10 +38,12 @@ type PrometheusMetricSample struct {
 	Type       string             `json:"type"`
 	Value      *float64           `json:"value,omitempty"`
 	Delta      *float64           `json:"delta,omitempty"`
+	Rate       *float64           `json:"rate,omitempty"`
 	Sum        *float64           `json:"sum,omitempty"`
 	Count      *uint64            `json:"count,omitempty"`
 	SumDelta   *float64           `json:"sumDelta,omitempty"`
 	CountDelta *uint64            `json:"countDelta,omitempty"`
+	Mean       *float64           `json:"mean,omitempty"`
 	Quantiles  map[string]float64 `json:"quantiles,omitempty"`
 }
 
@@ -145,6 +150,7 @@ func (m *BlockMetrics) UpdatePrometheusMetric(name string, value *io_prometheus_
 		}
 		// NaN values and nil values are silently omitted
 	} else if value.Counter != nil {
+		m.prevMetrics[name] = value
 		if value.Counter.Value != nil && !math.IsNaN(*value.Counter.Value) {
 			m.ExecutionMetrics[name] = *value.Counter.Value
 		}
@@ -0,0 +1,35 @@
+package metrics
+
+import "context"
+
+// BlockObserver is notified with the metrics of every benchmark block once
+// they have been collected, e.g. to publish live progress.
+type BlockObserver interface {
+	ObserveBlock(role string, m *BlockMetrics)
+}
+
+type observedCollector struct {
+	Collector
+	observer BlockObserver
+	role     string
+}
+
+// NewObservedCollector wraps a collector so that the observer sees each block
+// after the client metrics have been added to it. It returns the collector
+// unchanged if observer is nil.
+func NewObservedCollector(collector Collector, observer BlockObserver, role string) Collector {
+	if observer == nil {
+		return collector
+	}
+	return &observedCollector{
+		Collector: collector,
+		observer:  observer,
+		role:      role,
+	}
+}
+
+func (c *observedCollector) Collect(ctx context.Context, m *BlockMetrics) error {
+	err := c.Collector.Collect(ctx, m)
+	c.observer.ObserveBlock(c.role, m)
+	return err
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubCollector struct {
+	err       error
+	collected []*BlockMetrics
+}
+
+func (c *stubCollector) Collect(_ context.Context, m *BlockMetrics) error {
+	m.AddExecutionMetric("client_metric", 1.0)
+	c.collected = append(c.collected, m)
+	return c.err
+}
+
+func (c *stubCollector) GetMetrics() []BlockMetrics {
+	out := make([]BlockMetrics, len(c.collected))
+	for i, m := range c.collected {
+		out[i] = *m
+	}
+	return out
+}
+
+type recordingObserver struct {
+	roles  []string
+	blocks []*BlockMetrics
+}
+
+func (o *recordingObserver) ObserveBlock(role string, m *BlockMetrics) {
+	o.roles = append(o.roles, role)
+	o.blocks = append(o.blocks, m)
+}
+
+func TestNewObservedCollectorWithoutObserver(t *testing.T) {
+	inner := &stubCollector{}
+	require.Same(t, inner, NewObservedCollector(inner, nil, "sequencer"))
+}
+
+func TestObservedCollectorNotifiesAfterCollect(t *testing.T) {
+	inner := &stubCollector{err: errors.New("scrape failed")}
+	observer := &recordingObserver{}
+	collector := NewObservedCollector(inner, observer, "validator")
+
+	m := NewBlockMetrics()
+	m.SetBlockNumber(7)
+	require.Error(t, collector.Collect(context.Background(), m))
+
+	require.Equal(t, []string{"validator"}, observer.roles)
+	require.Equal(t, uint64(7), observer.blocks[0].BlockNumber)
+	require.Contains(t, observer.blocks[0].ExecutionMetrics, "client_metric")
+	require.Len(t, collector.GetMetrics(), 1)
+}
@@ -0,0 +1,416 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultHistogramQuantiles are the quantiles estimated from histogram
+// buckets when a spec does not set its own: p50, p90, p95 and p99.
+var DefaultHistogramQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// PrometheusSpec declares what a client collects from its Prometheus
+// endpoint. Everything else about scraping is shared by all clients.
+type PrometheusSpec struct {
+	// Metrics are the metric families recorded for every block. Labeled
+	// series are recorded under their SeriesName, and a family with a single
+	// labeled series is also recorded under its plain name.
+	Metrics []string
+	// Quantiles are estimated per block from histogram buckets. Defaults to
+	// DefaultHistogramQuantiles.
+	Quantiles []float64
+}
+
+// Names returns the metric families of the spec as a set.
+func (s PrometheusSpec) Names() map[string]bool {
+	names := make(map[string]bool, len(s.Metrics))
+	for _, name := range s.Metrics {
+		names[name] = true
+	}
+	return names
+}
+
+// PrometheusCollector scrapes a Prometheus endpoint after every block.
+//
+// For each recorded series it stores:
+//   - gauges as their value
+//   - counters as their value and, from the second block on, as a per-second
+//     rate under "<key>_rate"
+//   - histograms and summaries as the average observation since the previous
+//     block, plus quantiles under "<key>_quantile_<q>" (estimated from the
+//     buckets observed during the block for histograms)
+//
+// along with a raw PrometheusMetricSample that carries the same mean and
+// quantiles.
+type PrometheusCollector struct {
+	log       log.Logger
+	endpoint  string
+	names     map[string]bool
+	quantiles []float64
+	selection *MetricSelection
+	archive   *ScrapeArchive
+
+	prevMetrics map[string]*io_prometheus_client.Metric
+	prevScrape  time.Time
+	metrics     []BlockMetrics
+}
+
+var _ Collector = (*PrometheusCollector)(nil)
+
+// NewPrometheusCollector creates a collector for the given endpoint. The
+// scrape config adds selected series and archiving on top of the spec.
+func NewPrometheusCollector(log log.Logger, endpoint string, spec PrometheusSpec, scrape *ScrapeConfig, metricsDir string) (*PrometheusCollector, error) {
+	selection, err := NewMetricSelection(scrape)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric selection: %w", err)
+	}
+
+	quantiles := spec.Quantiles
+	if len(quantiles) == 0 {
+		quantiles = DefaultHistogramQuantiles
+	}
+
+	return &PrometheusCollector{
+		log:         log,
+		endpoint:    endpoint,
+		names:       spec.Names(),
+		quantiles:   quantiles,
+		selection:   selection,
+		archive:     NewScrapeArchive(scrape, metricsDir),
+		prevMetrics: make(map[string]*io_prometheus_client.Metric),
+		metrics:     make([]BlockMetrics, 0),
+	}, nil
+}
+
+// Endpoint returns the URL the collector scrapes.
+func (c *PrometheusCollector) Endpoint() string {
+	return c.endpoint
+}
+
+func (c *PrometheusCollector) GetMetrics() []BlockMetrics {
+	return c.metrics
+}
+
+// FetchMetrics performs a single scrape of an endpoint and returns the body
+// and its content type.
+func FetchMetrics(ctx context.Context, endpoint string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create metrics request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get metrics: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to get metrics: unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read metrics response: %w", err)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+func (c *PrometheusCollector) Collect(ctx context.Context, m *BlockMetrics) error {
+	scrapeTime := time.Now()
+	body, contentType, err := FetchMetrics(ctx, c.endpoint)
+	if err != nil {
+		return err
+	}
+
+	if c.archive != nil {
+		if err := c.archive.Write(m.BlockNumber, contentType, body); err != nil {
+			c.log.Warn("failed to archive metrics scrape", "error", err)
+		}
+	}
+
+	txtParser := expfmt.NewTextParser(model.LegacyValidation)
+	families, err := txtParser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to parse metrics: %w", err)
+	}
+
+	elapsed := 0.0
+	if !c.prevScrape.IsZero() {
+		elapsed = scrapeTime.Sub(c.prevScrape).Seconds()
+	}
+
+	m.SetPreviousPrometheusMetrics(c.prevMetrics)
+	for name, family := range families {
+		series := family.GetMetric()
+		if !c.names[name] {
+			series = c.selection.Select(family)
+		}
+		for _, s := range series {
+			key := SeriesName(name, s)
+			c.record(m, name, key, s, elapsed, true)
+
+			if key != name && len(series) == 1 {
+				c.record(m, name, name, s, elapsed, false)
+			}
+		}
+	}
+
+	c.prevMetrics = m.PreviousPrometheusMetrics()
+	c.prevScrape = scrapeTime
+	c.metrics = append(c.metrics, *m.Copy())
+	return nil
+}
+
+func (c *PrometheusCollector) record(m *BlockMetrics, name string, key string, metric *io_prometheus_client.Metric, elapsed float64, recordSample bool) {
+	prevMetric := m.PreviousPrometheusMetric(key)
+
+	sample := newPrometheusMetricSample(name, key, metric, prevMetric)
+	if metric.Histogram != nil {
+		sample.Quantiles = c.histogramQuantiles(metric, prevMetric)
+		for q, value := range sample.Quantiles {
+			m.AddExecutionMetric(key+"_quantile_"+q, value)
+		}
+	}
+	if sample.Type == "counter" && sample.Delta != nil && prevMetric != nil && elapsed > 0 {
+		rate := *sample.Delta / elapsed
+		sample.Rate = &rate
+		m.AddExecutionMetric(key+"_rate", rate)
+	}
+	if recordSample {
+		m.AddPrometheusMetricSample(sample)
+	}
+
+	if err := m.UpdatePrometheusMetric(key, metric); err != nil {
+		c.log.Warn("failed to add metric", "name", key, "error", err)
+	}
+	addSummaryQuantiles(m, key, metric)
+}
+
+// histogramQuantiles estimates the configured quantiles of the observations
+// made since the previous scrape, keyed by FormatQuantile. It returns nil if
+// nothing was observed.
+func (c *PrometheusCollector) histogramQuantiles(metric *io_prometheus_client.Metric, prevMetric *io_prometheus_client.Metric) map[string]float64 {
+	var prevHistogram *io_prometheus_client.Histogram
+	if prevMetric != nil {
+		prevHistogram = prevMetric.Histogram
+	}
+
+	var quantiles map[string]float64
+	for _, quantile := range c.quantiles {
+		value, ok := HistogramQuantile(quantile, metric.Histogram, prevHistogram)
+		if !ok {
+			continue
+		}
+		if quantiles == nil {
+			quantiles = make(map[string]float64, len(c.quantiles))
+		}
+		quantiles[FormatQuantile(quantile)] = value
+	}
+	return quantiles
+}
+
+func addSummaryQuantiles(m *BlockMetrics, key string, metric *io_prometheus_client.Metric) {
+	if metric.Summary == nil {
+		return
+	}
+
+	for _, quantile := range metric.Summary.GetQuantile() {
+		m.AddExecutionMetric(key+"_quantile_"+FormatQuantile(quantile.GetQuantile()), quantile.GetValue())
+	}
+}
+
+func newPrometheusMetricSample(name string, key string, metric *io_prometheus_client.Metric, prevMetric *io_prometheus_client.Metric) PrometheusMetricSample {
+	sample := PrometheusMetricSample{
+		Name:   name,
+		Key:    key,
+		Labels: SeriesLabels(metric),
+	}
+
+	if metric.Gauge != nil {
+		sample.Type = "gauge"
+		if metric.Gauge.Value != nil && !math.IsNaN(*metric.Gauge.Value) {
+			sample.Value = ptrFloat64(*metric.Gauge.Value)
+		}
+		return sample
+	}
+
+	if metric.Counter != nil {
+		sample.Type = "counter"
+		if metric.Counter.Value != nil && !math.IsNaN(*metric.Counter.Value) {
+			value := *metric.Counter.Value
+			sample.Value = ptrFloat64(value)
+			if prevMetric != nil && prevMetric.Counter != nil && prevMetric.Counter.Value != nil {
+				sample.Delta = ptrFloat64(deltaFloat64(value, *prevMetric.Counter.Value))
+			} else {
+				sample.Delta = ptrFloat64(value)
+			}
+		}
+		return sample
+	}
+
+	if metric.Histogram != nil {
+		sample.Type = "histogram"
+		var prevHistogram *io_prometheus_client.Histogram
+		if prevMetric != nil {
+			prevHistogram = prevMetric.Histogram
+		}
+		histogram := metric.Histogram
+		if histogram.SampleSum != nil && !math.IsNaN(*histogram.SampleSum) {
+			sum := *histogram.SampleSum
+			sample.Sum = ptrFloat64(sum)
+			if prevHistogram != nil && prevHistogram.SampleSum != nil {
+				sample.SumDelta = ptrFloat64(deltaFloat64(sum, *prevHistogram.SampleSum))
+			} else {
+				sample.SumDelta = ptrFloat64(sum)
+			}
+		}
+		if histogram.SampleCount != nil {
+			count := *histogram.SampleCount
+			sample.Count = ptrUint64(count)
+			if prevHistogram != nil && prevHistogram.SampleCount != nil {
+				sample.CountDelta = ptrUint64(deltaUint64(count, *prevHistogram.SampleCount))
+			} else {
+				sample.CountDelta = ptrUint64(count)
+			}
+		}
+		sample.Mean = sampleMean(sample)
+		return sample
+	}
+
+	if metric.Summary != nil {
+		sample.Type = "summary"
+		var prevSummary *io_prometheus_client.Summary
+		if prevMetric != nil {
+			prevSummary = prevMetric.Summary
+		}
+		summary := metric.Summary
+		if summary.SampleSum != nil && !math.IsNaN(*summary.SampleSum) {
+			sum := *summary.SampleSum
+			sample.Sum = ptrFloat64(sum)
+			if prevSummary != nil && prevSummary.SampleSum != nil {
+				sample.SumDelta = ptrFloat64(deltaFloat64(sum, *prevSummary.SampleSum))
+			} else {
+				sample.SumDelta = ptrFloat64(sum)
+			}
+		}
+		if summary.SampleCount != nil {
+			count := *summary.SampleCount
+			sample.Count = ptrUint64(count)
+			if prevSummary != nil && prevSummary.SampleCount != nil {
+				sample.CountDelta = ptrUint64(deltaUint64(count, *prevSummary.SampleCount))
+			} else {
+				sample.CountDelta = ptrUint64(count)
+			}
+		}
+		if len(summary.Quantile) > 0 {
+			sample.Quantiles = make(map[string]float64, len(summary.Quantile))
+			for _, quantile := range summary.Quantile {
+				sample.Quantiles[FormatQuantile(quantile.GetQuantile())] = quantile.GetValue()
+			}
+		}
+		sample.Mean = sampleMean(sample)
+		return sample
+	}
+
+	sample.Type = "unknown"
+	return sample
+}
+
+// sampleMean returns the mean observation since the previous scrape, or nil
+// if there were none.
+func sampleMean(sample PrometheusMetricSample) *float64 {
+	if sample.SumDelta == nil || sample.CountDelta == nil || *sample.CountDelta == 0 {
+		return nil
+	}
+	return ptrFloat64(*sample.SumDelta / float64(*sample.CountDelta))
+}
+
+// HistogramQuantile estimates a quantile of the observations made between
+// two scrapes of a histogram. It returns the upper bound of the bucket the
+// quantile falls in, or the largest finite bound for the +Inf bucket.
+func HistogramQuantile(quantile float64, histogram *io_prometheus_client.Histogram, prevHistogram *io_prometheus_client.Histogram) (float64, bool) {
+	if histogram == nil || histogram.SampleCount == nil || len(histogram.Bucket) == 0 {
+		return 0, false
+	}
+
+	prevCount := uint64(0)
+	if prevHistogram != nil && prevHistogram.SampleCount != nil {
+		prevCount = *prevHistogram.SampleCount
+	}
+	count := deltaUint64(*histogram.SampleCount, prevCount)
+	if count == 0 {
+		return 0, false
+	}
+
+	rank := quantile * float64(count)
+	lastFiniteUpperBound := 0.0
+	hasFiniteUpperBound := false
+	for i, bucket := range histogram.Bucket {
+		if bucket.CumulativeCount == nil || bucket.UpperBound == nil {
+			continue
+		}
+		if !math.IsInf(*bucket.UpperBound, 0) {
+			lastFiniteUpperBound = *bucket.UpperBound
+			hasFiniteUpperBound = true
+		}
+
+		prevBucketCount := uint64(0)
+		if prevHistogram != nil && i < len(prevHistogram.Bucket) && prevHistogram.Bucket[i].CumulativeCount != nil {
+			prevBucketCount = *prevHistogram.Bucket[i].CumulativeCount
+		}
+
+		bucketCount := deltaUint64(*bucket.CumulativeCount, prevBucketCount)
+		if float64(bucketCount) >= rank {
+			if math.IsInf(*bucket.UpperBound, 0) {
+				return lastFiniteUpperBound, hasFiniteUpperBound
+			}
+			return *bucket.UpperBound, true
+		}
+	}
+
+	return 0, false
+}
+
+// FormatQuantile formats a quantile for use in a metric key, e.g. "0_99".
+func FormatQuantile(quantile float64) string {
+	return strings.ReplaceAll(strconv.FormatFloat(quantile, 'f', -1, 64), ".", "_")
+}
+
+// deltaUint64 returns the increase of a cumulative value, treating a
+// decrease as a reset.
+func deltaUint64(current uint64, previous uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+func deltaFloat64(current float64, previous float64) float64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+func ptrFloat64(value float64) *float64 {
+	return &value
+}
+
+func ptrUint64(value uint64) *uint64 {
+	return &value
+}
@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func histogramMetric(count uint64, buckets ...*io_prometheus_client.Bucket) *io_prometheus_client.Metric {
+	return &io_prometheus_client.Metric{
+		Histogram: &io_prometheus_client.Histogram{
+			SampleCount: uint64Ptr(count),
+			SampleSum:   floatPtr(0),
+			Bucket:      buckets,
+		},
+	}
+}
+
+func bucket(upperBound float64, count uint64) *io_prometheus_client.Bucket {
+	return &io_prometheus_client.Bucket{
+		UpperBound:      floatPtr(upperBound),
+		CumulativeCount: uint64Ptr(count),
+	}
+}
+
+func TestHistogramQuantileUsesIntervalBuckets(t *testing.T) {
+	prev := histogramMetric(10, bucket(1, 5), bucket(2, 9), bucket(3, 10))
+	current := histogramMetric(20, bucket(1, 8), bucket(2, 17), bucket(3, 20))
+
+	got, ok := HistogramQuantile(0.5, current.Histogram, prev.Histogram)
+	require.True(t, ok)
+	require.Equal(t, 2.0, got)
+
+	got, ok = HistogramQuantile(0.9, current.Histogram, prev.Histogram)
+	require.True(t, ok)
+	require.Equal(t, 3.0, got)
+}
+
+func TestHistogramQuantileAvoidsInfiniteUpperBound(t *testing.T) {
+	current := histogramMetric(10, bucket(1, 5), bucket(math.Inf(1), 10))
+
+	got, ok := HistogramQuantile(0.9, current.Histogram, nil)
+	require.True(t, ok)
+	require.Equal(t, 1.0, got)
+}
+
+func TestHistogramQuantileWithoutObservations(t *testing.T) {
+	prev := histogramMetric(10, bucket(1, 10))
+	current := histogramMetric(10, bucket(1, 10))
+
+	_, ok := HistogramQuantile(0.5, current.Histogram, prev.Histogram)
+	require.False(t, ok)
+}
+
+func TestPrometheusMetricSamplePreservesLabelsAndHistogramDeltas(t *testing.T) {
+	prev := histogramMetric(10, bucket(1, 5), bucket(2, 10))
+	*prev.Histogram.SampleSum = 100
+
+	current := histogramMetric(16, bucket(1, 8), bucket(2, 16))
+	*current.Histogram.SampleSum = 172
+	current.Label = labeledGauge(0, "flashblock_index", "7").Label
+
+	sample := newPrometheusMetricSample(
+		"reth_base_builder_flashblock_txs_considered",
+		"reth_base_builder_flashblock_txs_considered_flashblock_index_7",
+		current,
+		prev,
+	)
+
+	require.Equal(t, "reth_base_builder_flashblock_txs_considered", sample.Name)
+	require.Equal(t, "reth_base_builder_flashblock_txs_considered_flashblock_index_7", sample.Key)
+	require.Equal(t, "7", sample.Labels["flashblock_index"])
+	require.Equal(t, "histogram", sample.Type)
+	require.Equal(t, uint64(16), *sample.Count)
+	require.Equal(t, uint64(6), *sample.CountDelta)
+	require.Equal(t, 172.0, *sample.Sum)
+	require.Equal(t, 72.0, *sample.SumDelta)
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	scrapes := []string{
+		`# TYPE reth_blocks_total counter
+reth_blocks_total 10
+# TYPE reth_execution_duration histogram
+reth_execution_duration_bucket{le="0.1"} 4
+reth_execution_duration_bucket{le="1"} 5
+reth_execution_duration_bucket{le="+Inf"} 5
+reth_execution_duration_sum 1.5
+reth_execution_duration_count 5
+# TYPE reth_fetch_latency summary
+reth_fetch_latency{quantile="0.5"} 0.2
+reth_fetch_latency{quantile="0.99"} 0.9
+reth_fetch_latency_sum 3
+reth_fetch_latency_count 10
+# TYPE reth_table_size gauge
+reth_table_size{table="PlainState"} 100
+reth_table_size{table="Headers"} 50
+# TYPE reth_ignored gauge
+reth_ignored 1
+`,
+		`# TYPE reth_blocks_total counter
+reth_blocks_total 16
+# TYPE reth_execution_duration histogram
+reth_execution_duration_bucket{le="0.1"} 4
+reth_execution_duration_bucket{le="1"} 9
+reth_execution_duration_bucket{le="+Inf"} 9
+reth_execution_duration_sum 4.7
+reth_execution_duration_count 9
+# TYPE reth_fetch_latency summary
+reth_fetch_latency{quantile="0.5"} 0.3
+reth_fetch_latency{quantile="0.99"} 1.1
+reth_fetch_latency_sum 5
+reth_fetch_latency_count 14
+# TYPE reth_table_size gauge
+reth_table_size{table="PlainState"} 110
+reth_table_size{table="Headers"} 55
+# TYPE reth_ignored gauge
+reth_ignored 1
+`,
+	}
+
+	scrape := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = fmt.Fprint(w, scrapes[scrape])
+		scrape++
+	}))
+	defer server.Close()
+
+	spec := PrometheusSpec{Metrics: []string{
+		"reth_blocks_total",
+		"reth_execution_duration",
+		"reth_fetch_latency",
+		"reth_table_size",
+	}}
+	collector, err := NewPrometheusCollector(log.New(), server.URL, spec, nil, "")
+	require.NoError(t, err)
+
+	first := NewBlockMetrics()
+	first.SetBlockNumber(1)
+	require.NoError(t, collector.Collect(context.Background(), first))
+
+	require.Equal(t, 10.0, first.ExecutionMetrics["reth_blocks_total"])
+	require.NotContains(t, first.ExecutionMetrics, "reth_blocks_total_rate")
+	require.Equal(t, 100.0, first.ExecutionMetrics["reth_table_size_table_PlainState"])
+	require.Equal(t, 50.0, first.ExecutionMetrics["reth_table_size_table_Headers"])
+	require.NotContains(t, first.ExecutionMetrics, "reth_ignored")
+
+	second := NewBlockMetrics()
+	second.SetBlockNumber(2)
+	require.NoError(t, collector.Collect(context.Background(), second))
+
+	require.Equal(t, 16.0, second.ExecutionMetrics["reth_blocks_total"])
+	require.Contains(t, second.ExecutionMetrics, "reth_blocks_total_rate")
+	require.Greater(t, second.ExecutionMetrics["reth_blocks_total_rate"], 0.0)
+
+	// 4 observations during the second block, all in the (0.1, 1] bucket
+	require.InDelta(t, 3.2/4, second.ExecutionMetrics["reth_execution_duration"], 1e-9)
+	require.Equal(t, 1.0, second.ExecutionMetrics["reth_execution_duration_quantile_0_5"])
+
+	require.InDelta(t, 0.5, second.ExecutionMetrics["reth_fetch_latency"], 1e-9)
+	require.Equal(t, 1.1, second.ExecutionMetrics["reth_fetch_latency_quantile_0_99"])
+
+	require.Equal(t, 110.0, second.ExecutionMetrics["reth_table_size_table_PlainState"])
+
+	var counterSample *PrometheusMetricSample
+	for i, sample := range second.PrometheusMetrics {
+		if sample.Key == "reth_blocks_total" {
+			counterSample = &second.PrometheusMetrics[i]
+		}
+	}
+	require.NotNil(t, counterSample)
+	require.Equal(t, 6.0, *counterSample.Delta)
+	require.NotNil(t, counterSample.Rate)
+
+	require.Len(t, collector.GetMetrics(), 2)
+}
+
+func TestPrometheusCollectorSelection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `# TYPE reth_table_size gauge
+reth_table_size{table="PlainState"} 100
+reth_table_size{table="Headers"} 50
+`)
+	}))
+	defer server.Close()
+
+	scrape := &ScrapeConfig{Metrics: []MetricSelector{
+		{Name: "reth_table_size", Labels: map[string]string{"table": "Plain.*"}},
+	}}
+	collector, err := NewPrometheusCollector(log.New(), server.URL, PrometheusSpec{}, scrape, "")
+	require.NoError(t, err)
+
+	m := NewBlockMetrics()
+	require.NoError(t, collector.Collect(context.Background(), m))
+	require.NotContains(t, m.ExecutionMetrics, "reth_table_size_table_PlainState")
+
+	scrape.Metrics[0].Labels["table"] = "=~Plain.*"
+	collector, err = NewPrometheusCollector(log.New(), server.URL, PrometheusSpec{}, scrape, "")
+	require.NoError(t, err)
+
+	m = NewBlockMetrics()
+	require.NoError(t, collector.Collect(context.Background(), m))
+	require.Equal(t, 100.0, m.ExecutionMetrics["reth_table_size_table_PlainState"])
+	require.Equal(t, 100.0, m.ExecutionMetrics["reth_table_size"])
+	require.NotContains(t, m.ExecutionMetrics, "reth_table_size_table_Headers")
+}
+
+func TestPrometheusCollectorHistogramQuantiles(t *testing.T) {
+	scrapes := []string{
+		`# TYPE reth_sync_execution_execution_duration histogram
+reth_sync_execution_execution_duration_bucket{le="0.01"} 50
+reth_sync_execution_execution_duration_bucket{le="0.05"} 90
+reth_sync_execution_execution_duration_bucket{le="0.1"} 100
+reth_sync_execution_execution_duration_bucket{le="+Inf"} 100
+reth_sync_execution_execution_duration_sum 2
+reth_sync_execution_execution_duration_count 100
+`,
+		`# TYPE reth_sync_execution_execution_duration histogram
+reth_sync_execution_execution_duration_bucket{le="0.01"} 100
+reth_sync_execution_execution_duration_bucket{le="0.05"} 180
+reth_sync_execution_execution_duration_bucket{le="0.1"} 196
+reth_sync_execution_execution_duration_bucket{le="+Inf"} 200
+reth_sync_execution_execution_duration_sum 6
+reth_sync_execution_execution_duration_count 200
+`,
+	}
+
+	scrape := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, scrapes[scrape])
+		scrape++
+	}))
+	defer server.Close()
+
+	name := "reth_sync_execution_execution_duration"
+	collector, err := NewPrometheusCollector(log.New(), server.URL, PrometheusSpec{Metrics: []string{name}}, nil, "")
+	require.NoError(t, err)
+
+	require.NoError(t, collector.Collect(context.Background(), NewBlockMetrics()))
+
+	m := NewBlockMetrics()
+	require.NoError(t, collector.Collect(context.Background(), m))
+
+	// Between the scrapes: 50 observations <= 0.01, 40 in (0.01, 0.05], 6 in
+	// (0.05, 0.1] and 4 above. p99 falls in the +Inf bucket.
+	require.Len(t, m.PrometheusMetrics, 1)
+	sample := m.PrometheusMetrics[0]
+	require.Equal(t, map[string]float64{
+		"0_5":  0.01,
+		"0_9":  0.05,
+		"0_95": 0.1,
+		"0_99": 0.1,
+	}, sample.Quantiles)
+	require.NotNil(t, sample.Mean)
+	require.InDelta(t, 0.04, *sample.Mean, 1e-9)
+
+	require.InDelta(t, 0.04, m.ExecutionMetrics[name], 1e-9)
+	require.Equal(t, 0.01, m.ExecutionMetrics[name+"_quantile_0_5"])
+	require.Equal(t, 0.1, m.ExecutionMetrics[name+"_quantile_0_95"])
+	require.Equal(t, 0.1, m.ExecutionMetrics[name+"_quantile_0_99"])
+}
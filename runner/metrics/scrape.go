@@ -0,0 +1,284 @@
+package metrics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	io_prometheus_client "github.com/prometheus/client_model/go"
+)
+
+// ScrapeArchiveFileName is the file, relative to the metrics directory, that
+// holds the archived Prometheus scrapes of a run.
+const ScrapeArchiveFileName = "prometheus.jsonl.gz"
+
+// ScrapeConfig controls which Prometheus metrics are collected from a client
+// in addition to the ones each client collects by default.
+type ScrapeConfig struct {
+	// Archive stores the complete exposition of every scrape, compressed, as
+	// a run artifact.
+	Archive bool `yaml:"archive"`
+	// Metrics selects additional series to record per block.
+	Metrics []MetricSelector `yaml:"metrics"`
+}
+
+// MetricSelector selects series by metric family name and labels. The name
+// and label values are matched exactly unless prefixed with a Prometheus
+// match operator: "=~" (regex), "!~" (negated regex) or "!=" (not equal).
+// Regexes are anchored. A label that is absent matches the empty string.
+type MetricSelector struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+// Check validates all selectors.
+func (c *ScrapeConfig) Check() error {
+	_, err := NewMetricSelection(c)
+	return err
+}
+
+type valueMatcher struct {
+	negate bool
+	equal  string
+	regex  *regexp.Regexp
+}
+
+func newValueMatcher(pattern string) (*valueMatcher, error) {
+	m := &valueMatcher{}
+	switch {
+	case strings.HasPrefix(pattern, "=~"), strings.HasPrefix(pattern, "!~"):
+		re, err := regexp.Compile("^(?:" + pattern[2:] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", pattern[2:], err)
+		}
+		m.regex = re
+		m.negate = pattern[0] == '!'
+	case strings.HasPrefix(pattern, "!="):
+		m.equal = pattern[2:]
+		m.negate = true
+	default:
+		m.equal = strings.TrimPrefix(pattern, "=")
+	}
+	return m, nil
+}
+
+func (m *valueMatcher) matches(value string) bool {
+	var ok bool
+	if m.regex != nil {
+		ok = m.regex.MatchString(value)
+	} else {
+		ok = value == m.equal
+	}
+	return ok != m.negate
+}
+
+type compiledSelector struct {
+	name   *valueMatcher
+	labels map[string]*valueMatcher
+}
+
+// MetricSelection is a compiled set of metric selectors.
+type MetricSelection struct {
+	selectors []compiledSelector
+}
+
+// NewMetricSelection compiles the selectors of a scrape config. It returns
+// nil if no selectors are configured.
+func NewMetricSelection(c *ScrapeConfig) (*MetricSelection, error) {
+	if c == nil || len(c.Metrics) == 0 {
+		return nil, nil
+	}
+
+	s := &MetricSelection{}
+	for i, sel := range c.Metrics {
+		if sel.Name == "" {
+			return nil, fmt.Errorf("metric selector %d is missing a name", i)
+		}
+		name, err := newValueMatcher(sel.Name)
+		if err != nil {
+			return nil, fmt.Errorf("metric selector %d: %w", i, err)
+		}
+		compiled := compiledSelector{name: name, labels: make(map[string]*valueMatcher, len(sel.Labels))}
+		for label, pattern := range sel.Labels {
+			m, err := newValueMatcher(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("metric selector %d label %s: %w", i, label, err)
+			}
+			compiled.labels[label] = m
+		}
+		s.selectors = append(s.selectors, compiled)
+	}
+	return s, nil
+}
+
+// Matches reports whether a series with the given name and labels is
+// selected.
+func (s *MetricSelection) Matches(name string, labels map[string]string) bool {
+	if s == nil {
+		return false
+	}
+	for _, sel := range s.selectors {
+		if !sel.name.matches(name) {
+			continue
+		}
+		ok := true
+		for label, m := range sel.labels {
+			if !m.matches(labels[label]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the series of a metric family that are selected.
+func (s *MetricSelection) Select(family *io_prometheus_client.MetricFamily) []*io_prometheus_client.Metric {
+	if s == nil {
+		return nil
+	}
+	var selected []*io_prometheus_client.Metric
+	for _, series := range family.GetMetric() {
+		if s.Matches(family.GetName(), SeriesLabels(series)) {
+			selected = append(selected, series)
+		}
+	}
+	return selected
+}
+
+// SeriesLabels returns the labels of a series as a map, or nil if it has
+// none.
+func SeriesLabels(metric *io_prometheus_client.Metric) map[string]string {
+	labels := metric.GetLabel()
+	if len(labels) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(labels))
+	for _, label := range labels {
+		result[label.GetName()] = label.GetValue()
+	}
+	return result
+}
+
+// SeriesName returns a flat metric name for a labeled series, e.g.
+// "name_method_get_status_200". Unlabeled series keep their name.
+func SeriesName(name string, metric *io_prometheus_client.Metric) string {
+	labels := metric.GetLabel()
+	if len(labels) == 0 {
+		return name
+	}
+
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, sanitizeMetricPart(label.GetName())+"_"+sanitizeMetricPart(label.GetValue()))
+	}
+	sort.Strings(parts)
+	return name + "_" + strings.Join(parts, "_")
+}
+
+func sanitizeMetricPart(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// ScrapeArchive appends raw scrapes to a gzip-compressed JSON Lines file.
+// Each block is written as its own gzip member so the file stays readable
+// if the runner stops mid-run.
+type ScrapeArchive struct {
+	path string
+}
+
+// ScrapeRecord is one archived scrape.
+type ScrapeRecord struct {
+	BlockNumber uint64    `json:"block"`
+	Timestamp   time.Time `json:"timestamp"`
+	ContentType string    `json:"contentType,omitempty"`
+	Body        string    `json:"body"`
+}
+
+// NewScrapeArchive returns an archive in the given metrics directory, or nil
+// if archiving is not enabled.
+func NewScrapeArchive(c *ScrapeConfig, metricsDir string) *ScrapeArchive {
+	if c == nil || !c.Archive || metricsDir == "" {
+		return nil
+	}
+	return &ScrapeArchive{path: path.Join(metricsDir, ScrapeArchiveFileName)}
+}
+
+// Write appends a scrape for the given block.
+func (a *ScrapeArchive) Write(blockNumber uint64, contentType string, body []byte) error {
+	line, err := json.Marshal(ScrapeRecord{
+		BlockNumber: blockNumber,
+		Timestamp:   time.Now(),
+		ContentType: contentType,
+		Body:        string(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal scrape: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to compress scrape: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress scrape: %w", err)
+	}
+
+	f, err := os.OpenFile(a.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open scrape archive: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write scrape archive: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadScrapeArchive reads all records of an archive written by ScrapeArchive.
+func ReadScrapeArchive(filename string) ([]ScrapeRecord, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scrape archive: %w", err)
+	}
+	defer func() {
+		_ = gz.Close()
+	}()
+
+	var records []ScrapeRecord
+	decoder := json.NewDecoder(gz)
+	for decoder.More() {
+		var record ScrapeRecord
+		if err := decoder.Decode(&record); err != nil {
+			return records, fmt.Errorf("failed to decode scrape archive: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
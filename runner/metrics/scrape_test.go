@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"path"
+	"testing"
+
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func labeledGauge(value float64, labels ...string) *io_prometheus_client.Metric {
+	metric := &io_prometheus_client.Metric{Gauge: &io_prometheus_client.Gauge{Value: floatPtr(value)}}
+	for i := 0; i+1 < len(labels); i += 2 {
+		name, val := labels[i], labels[i+1]
+		metric.Label = append(metric.Label, &io_prometheus_client.LabelPair{Name: &name, Value: &val})
+	}
+	return metric
+}
+
+func TestMetricSelectionMatchers(t *testing.T) {
+	selection, err := NewMetricSelection(&ScrapeConfig{Metrics: []MetricSelector{
+		{Name: "reth_sync_execution_execution_duration"},
+		{Name: "=~reth_base_builder_.*_total", Labels: map[string]string{"reason": "!=ok"}},
+		{Name: "reth_db_table_size", Labels: map[string]string{"table": "=~Plain.*", "kind": "!~tmp|scratch"}},
+	}})
+	require.NoError(t, err)
+
+	require.True(t, selection.Matches("reth_sync_execution_execution_duration", nil))
+	require.False(t, selection.Matches("reth_sync_execution_execution_duration_other", nil))
+
+	require.True(t, selection.Matches("reth_base_builder_gas_limit_exceeded_total", map[string]string{"reason": "full"}))
+	require.True(t, selection.Matches("reth_base_builder_gas_limit_exceeded_total", nil))
+	require.False(t, selection.Matches("reth_base_builder_gas_limit_exceeded_total", map[string]string{"reason": "ok"}))
+	require.False(t, selection.Matches("prefix_reth_base_builder_x_total", nil))
+
+	require.True(t, selection.Matches("reth_db_table_size", map[string]string{"table": "PlainState"}))
+	require.False(t, selection.Matches("reth_db_table_size", map[string]string{"table": "PlainState", "kind": "tmp"}))
+	require.False(t, selection.Matches("reth_db_table_size", map[string]string{"table": "HashedState"}))
+}
+
+func TestMetricSelectionNil(t *testing.T) {
+	selection, err := NewMetricSelection(nil)
+	require.NoError(t, err)
+	require.Nil(t, selection)
+	require.False(t, selection.Matches("anything", nil))
+	require.Nil(t, selection.Select(&io_prometheus_client.MetricFamily{}))
+
+	selection, err = NewMetricSelection(&ScrapeConfig{Archive: true})
+	require.NoError(t, err)
+	require.Nil(t, selection)
+}
+
+func TestMetricSelectionErrors(t *testing.T) {
+	require.Error(t, (&ScrapeConfig{Metrics: []MetricSelector{{}}}).Check())
+	require.Error(t, (&ScrapeConfig{Metrics: []MetricSelector{{Name: "=~("}}}).Check())
+	require.Error(t, (&ScrapeConfig{Metrics: []MetricSelector{{Name: "x", Labels: map[string]string{"a": "!~["}}}}).Check())
+	require.NoError(t, (&ScrapeConfig{Metrics: []MetricSelector{{Name: "x"}}}).Check())
+}
+
+func TestMetricSelectionSelectsLabeledSeries(t *testing.T) {
+	selection, err := NewMetricSelection(&ScrapeConfig{Metrics: []MetricSelector{
+		{Name: "reth_db_table_size", Labels: map[string]string{"table": "=~Plain.*"}},
+	}})
+	require.NoError(t, err)
+
+	name := "reth_db_table_size"
+	family := &io_prometheus_client.MetricFamily{
+		Name: &name,
+		Metric: []*io_prometheus_client.Metric{
+			labeledGauge(1, "table", "PlainState"),
+			labeledGauge(2, "table", "HashedState"),
+			labeledGauge(3, "table", "PlainCode"),
+		},
+	}
+
+	selected := selection.Select(family)
+	require.Len(t, selected, 2)
+	require.Equal(t, "reth_db_table_size_table_PlainState", SeriesName(name, selected[0]))
+	require.Equal(t, "reth_db_table_size_table_PlainCode", SeriesName(name, selected[1]))
+	require.Equal(t, map[string]string{"table": "PlainCode"}, SeriesLabels(selected[1]))
+}
+
+func TestSeriesNameSortsAndSanitizesLabels(t *testing.T) {
+	metric := labeledGauge(1, "type", "account.worker", "configname", "mainnet/snapshot")
+	require.Equal(t, "reth_example_metric_configname_mainnet_snapshot_type_account_worker", SeriesName("reth_example_metric", metric))
+	require.Equal(t, "plain", SeriesName("plain", labeledGauge(1)))
+	require.Nil(t, SeriesLabels(labeledGauge(1)))
+}
+
+func TestScrapeArchive(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, NewScrapeArchive(nil, dir))
+	require.Nil(t, NewScrapeArchive(&ScrapeConfig{}, dir))
+
+	archive := NewScrapeArchive(&ScrapeConfig{Archive: true}, dir)
+	require.NotNil(t, archive)
+
+	require.NoError(t, archive.Write(1, "text/plain; version=0.0.4", []byte("# TYPE a gauge\na 1\n")))
+	require.NoError(t, archive.Write(2, "text/plain; version=0.0.4", []byte("# TYPE a gauge\na 2\n")))
+
+	records, err := ReadScrapeArchive(path.Join(dir, ScrapeArchiveFileName))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, uint64(1), records[0].BlockNumber)
+	require.Equal(t, "# TYPE a gauge\na 2\n", records[1].Body)
+	require.Equal(t, "text/plain; version=0.0.4", records[1].ContentType)
+}
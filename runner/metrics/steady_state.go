@@ -0,0 +1,65 @@
+package metrics
+
+import "math"
+
+// SteadyStateDetector reports when a per-block series, such as the gas
+// processed per second, has settled: the coefficient of variation (standard
+// deviation over mean) of its last window values is at most the tolerance.
+type SteadyStateDetector struct {
+	window    int
+	tolerance float64
+	values    []float64
+}
+
+// NewSteadyStateDetector creates a detector over the last window values.
+func NewSteadyStateDetector(window int, tolerance float64) *SteadyStateDetector {
+	return &SteadyStateDetector{
+		window:    window,
+		tolerance: tolerance,
+		values:    make([]float64, 0, window),
+	}
+}
+
+// Add records the value of the next block and returns true if the series is
+// steady.
+func (d *SteadyStateDetector) Add(value float64) bool {
+	if len(d.values) == d.window {
+		d.values = append(d.values[:0], d.values[1:]...)
+	}
+	d.values = append(d.values, value)
+	return d.Steady()
+}
+
+// Steady returns true if a full window of values has been recorded and its
+// coefficient of variation is within the tolerance.
+func (d *SteadyStateDetector) Steady() bool {
+	if len(d.values) < d.window {
+		return false
+	}
+	cv, ok := d.CoefficientOfVariation()
+	return ok && cv <= d.tolerance
+}
+
+// CoefficientOfVariation returns the coefficient of variation of the recorded
+// values, or false if there are none or their mean is not positive.
+func (d *SteadyStateDetector) CoefficientOfVariation() (float64, bool) {
+	if len(d.values) == 0 {
+		return 0, false
+	}
+
+	mean := 0.0
+	for _, v := range d.values {
+		mean += v
+	}
+	mean /= float64(len(d.values))
+	if mean <= 0 {
+		return 0, false
+	}
+
+	variance := 0.0
+	for _, v := range d.values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(d.values))
+	return math.Sqrt(variance) / mean, true
+}
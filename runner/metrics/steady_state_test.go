@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSteadyStateDetectorNeedsFullWindow(t *testing.T) {
+	d := NewSteadyStateDetector(3, 0.05)
+	require.False(t, d.Add(100))
+	require.False(t, d.Add(100))
+	require.True(t, d.Add(100))
+}
+
+func TestSteadyStateDetectorRollingWindow(t *testing.T) {
+	d := NewSteadyStateDetector(3, 0.05)
+
+	// ramping up while caches warm
+	for _, v := range []float64{20, 50, 80, 95} {
+		require.False(t, d.Add(v))
+	}
+	require.False(t, d.Add(100))
+	require.True(t, d.Add(100))
+
+	cv, ok := d.CoefficientOfVariation()
+	require.True(t, ok)
+	require.InDelta(t, 0.0240, cv, 1e-4)
+
+	// a spike leaves the steady state until it falls out of the window
+	require.False(t, d.Add(200))
+	require.False(t, d.Add(100))
+	require.False(t, d.Add(100))
+	require.True(t, d.Add(100))
+}
+
+func TestSteadyStateDetectorZeroMean(t *testing.T) {
+	d := NewSteadyStateDetector(2, 0.05)
+	d.Add(0)
+	require.False(t, d.Add(0))
+
+	_, ok := d.CoefficientOfVariation()
+	require.False(t, ok)
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// MetricsStreamFileName is the file, relative to the metrics directory, that
+// block metrics are appended to while a benchmark runs.
+const MetricsStreamFileName = "metrics.jsonl"
+
+// StreamingMetricsWriter appends the metrics of each block to a JSON Lines
+// file and syncs it to disk, so the blocks collected before the runner is
+// killed are not lost.
+type StreamingMetricsWriter struct {
+	baseDir string
+	file    *os.File
+}
+
+// NewStreamingMetricsWriter creates (or truncates) the metrics stream in the
+// given metrics directory.
+func NewStreamingMetricsWriter(baseDir string) (*StreamingMetricsWriter, error) {
+	f, err := os.OpenFile(path.Join(baseDir, MetricsStreamFileName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metrics stream: %w", err)
+	}
+	return &StreamingMetricsWriter{
+		baseDir: baseDir,
+		file:    f,
+	}, nil
+}
+
+// WriteBlock appends one block and fsyncs the stream.
+func (w *StreamingMetricsWriter) WriteBlock(m *BlockMetrics) error {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal block metrics: %w", err)
+	}
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write metrics stream: %w", err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync metrics stream: %w", err)
+	}
+	return nil
+}
+
+// Finalize closes the stream and assembles metrics.json from it.
+func (w *StreamingMetricsWriter) Finalize() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close metrics stream: %w", err)
+	}
+	return AssembleMetricsFile(w.baseDir)
+}
+
+// AssembleMetricsFile writes metrics.json in the given metrics directory from
+// the blocks in its metrics stream. It is used both at the end of a run and
+// to recover the metrics of a run that did not finish.
+func AssembleMetricsFile(baseDir string) error {
+	blocks, err := ReadMetricsStream(path.Join(baseDir, MetricsStreamFileName))
+	if err != nil {
+		return err
+	}
+	return NewFileMetricsWriter(baseDir).Write(blocks)
+}
+
+// ReadMetricsStream reads the blocks of a metrics stream. A trailing partial
+// line, left behind if the runner was killed mid-write, is ignored.
+func ReadMetricsStream(filename string) ([]BlockMetrics, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metrics stream: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	blocks := make([]BlockMetrics, 0)
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metrics stream: %w", err)
+		}
+
+		var block BlockMetrics
+		if err := json.Unmarshal(line, &block); err != nil {
+			return nil, fmt.Errorf("failed to decode block %d of metrics stream: %w", len(blocks), err)
+		}
+		blocks = append(blocks, block)
+	}
+}
+
+type streamingCollector struct {
+	Collector
+	writer *StreamingMetricsWriter
+}
+
+// NewStreamingCollector wraps a collector so that each block is appended to
+// the metrics stream as soon as it has been collected.
+func NewStreamingCollector(collector Collector, writer *StreamingMetricsWriter) Collector {
+	return &streamingCollector{
+		Collector: collector,
+		writer:    writer,
+	}
+}
+
+func (c *streamingCollector) Collect(ctx context.Context, m *BlockMetrics) error {
+	collectErr := c.Collector.Collect(ctx, m)
+	if err := c.writer.WriteBlock(m); err != nil {
+		return errors.Join(collectErr, err)
+	}
+	return collectErr
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingCollectorWritesEachBlock(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewStreamingMetricsWriter(dir)
+	require.NoError(t, err)
+
+	inner := &stubCollector{}
+	collector := NewStreamingCollector(inner, writer)
+
+	for i := uint64(1); i <= 3; i++ {
+		m := NewBlockMetrics()
+		m.SetBlockNumber(i)
+		require.NoError(t, collector.Collect(context.Background(), m))
+
+		// every block is on disk before the run finishes
+		blocks, err := ReadMetricsStream(path.Join(dir, MetricsStreamFileName))
+		require.NoError(t, err)
+		require.Len(t, blocks, int(i))
+		require.Equal(t, i, blocks[i-1].BlockNumber)
+		require.Equal(t, 1.0, blocks[i-1].ExecutionMetrics["client_metric"])
+	}
+
+	require.NoError(t, writer.Finalize())
+
+	data, err := os.ReadFile(path.Join(dir, MetricsFileName))
+	require.NoError(t, err)
+	var blocks []BlockMetrics
+	require.NoError(t, json.Unmarshal(data, &blocks))
+	require.Len(t, blocks, 3)
+	require.Equal(t, uint64(3), blocks[2].BlockNumber)
+}
+
+func TestStreamingCollectorWritesBlockOnCollectError(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewStreamingMetricsWriter(dir)
+	require.NoError(t, err)
+
+	collector := NewStreamingCollector(&stubCollector{err: errors.New("scrape failed")}, writer)
+	m := NewBlockMetrics()
+	m.AddExecutionMetric("latency/get_payload", 1.5)
+	require.Error(t, collector.Collect(context.Background(), m))
+
+	blocks, err := ReadMetricsStream(path.Join(dir, MetricsStreamFileName))
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, 1.5, blocks[0].ExecutionMetrics["latency/get_payload"])
+}
+
+func TestAssembleMetricsFileIgnoresPartialLine(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewStreamingMetricsWriter(dir)
+	require.NoError(t, err)
+
+	for i := uint64(1); i <= 2; i++ {
+		m := NewBlockMetrics()
+		m.SetBlockNumber(i)
+		require.NoError(t, writer.WriteBlock(m))
+	}
+
+	// simulate the runner being killed in the middle of writing block 3
+	_, err = writer.file.WriteString(`{"BlockNumber":3,"Execution`)
+	require.NoError(t, err)
+	require.NoError(t, writer.file.Close())
+
+	require.NoError(t, AssembleMetricsFile(dir))
+
+	data, err := os.ReadFile(path.Join(dir, MetricsFileName))
+	require.NoError(t, err)
+	var blocks []BlockMetrics
+	require.NoError(t, json.Unmarshal(data, &blocks))
+	require.Len(t, blocks, 2)
+	require.Equal(t, uint64(2), blocks[1].BlockNumber)
+}
@@ -0,0 +1,110 @@
+// Package migrate splits aggregate metadata files, which hold every run of an
+// output directory, into the per-run <outputDir>/metadata.json layout of the
+// report data contract.
+package migrate
+
+import (
+	"context"
+	"os"
+	"path"
+
+	"github.com/base/base-bench/benchmark/config"
+	"github.com/base/base-bench/runner/benchmark"
+	"github.com/base/base-bench/runner/sink"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// MigrateResult summarizes a completed migration
+type MigrateResult struct {
+	// Written is the number of per-run metadata files written
+	Written int
+	// Existing is the number of runs that already had a per-run metadata file
+	Existing int
+	// Skipped is the number of runs that could not be migrated
+	Skipped int
+}
+
+// Service migrates the metadata of an output directory
+type Service struct {
+	config *config.MigrateCmdConfig
+	log    log.Logger
+}
+
+// NewService creates a new migrate service
+func NewService(cfg *config.MigrateCmdConfig, log log.Logger) *Service {
+	return &Service{
+		config: cfg,
+		log:    log,
+	}
+}
+
+// Migrate writes a per-run metadata.json for every run in the aggregate
+// metadata files. A run that appears in several files is taken from the last
+// one, which is the most recent.
+func (s *Service) Migrate(ctx context.Context) (*MigrateResult, error) {
+	files, err := s.config.MetadataFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	// runs are keyed by output directory since that is where they are written
+	runs := make(map[string]benchmark.Run)
+	var order []string
+	result := &MigrateResult{}
+	for _, file := range files {
+		metadata, err := benchmark.NewMetadataFile(file).Read()
+		if err != nil {
+			return nil, err
+		}
+		s.log.Info("Loaded metadata", "path", file, "runs", len(metadata.Runs))
+
+		for _, run := range metadata.Runs {
+			if run.OutputDir == "" {
+				s.log.Warn("Skipping run without an output directory", "runID", run.ID, "file", file)
+				result.Skipped++
+				continue
+			}
+			// the group timestamp predates Run.CreatedAt
+			if run.CreatedAt == nil {
+				run.CreatedAt = metadata.CreatedAt
+			}
+			if _, ok := runs[run.OutputDir]; !ok {
+				order = append(order, run.OutputDir)
+			}
+			runs[run.OutputDir] = run
+		}
+	}
+
+	outputSink := sink.NewLocalSink(s.config.OutputDir())
+	for _, outputDir := range order {
+		run := runs[outputDir]
+
+		if err := run.Validate(); err != nil {
+			s.log.Warn("Skipping run", "outputDir", outputDir, "err", err)
+			result.Skipped++
+			continue
+		}
+
+		runDir := path.Join(s.config.OutputDir(), outputDir)
+		if _, err := os.Stat(runDir); os.IsNotExist(err) {
+			s.log.Warn("Skipping run without output", "runID", run.ID, "outputDir", outputDir)
+			result.Skipped++
+			continue
+		}
+
+		metadataPath := path.Join(runDir, benchmark.RunMetadataFileName)
+		if _, err := os.Stat(metadataPath); err == nil && !s.config.Overwrite() {
+			result.Existing++
+			continue
+		}
+
+		if err := sink.WriteRunMetadata(ctx, outputSink, run); err != nil {
+			return nil, errors.Wrapf(err, "failed to migrate run %s", run.ID)
+		}
+		s.log.Info("Wrote run metadata", "runID", run.ID, "path", metadataPath)
+		result.Written++
+	}
+
+	return result, nil
+}
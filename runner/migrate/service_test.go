@@ -0,0 +1,111 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/base/base-bench/benchmark/config"
+	"github.com/base/base-bench/benchmark/flags"
+	"github.com/base/base-bench/runner/benchmark"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func writeFile(t *testing.T, filename string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(path.Dir(filename), 0755))
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+}
+
+func newTestService(t *testing.T, outputDir string, overwrite bool, args ...string) *Service {
+	set := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	set.String(flags.OutputDirFlagName, outputDir, "")
+	set.Bool(flags.MigrateOverwriteFlagName, overwrite, "")
+	require.NoError(t, set.Parse(args))
+
+	cfg := config.NewMigrateCmdConfig(cli.NewContext(cli.NewApp(), set, nil))
+	require.NoError(t, cfg.Check())
+	return NewService(cfg, log.New())
+}
+
+func readRunMetadata(t *testing.T, filename string) benchmark.Run {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	var metadata benchmark.RunGroup
+	require.NoError(t, json.Unmarshal(data, &metadata))
+	require.Len(t, metadata.Runs, 1)
+	return metadata.Runs[0]
+}
+
+func TestMigrateSplitsAggregateMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, path.Join(dir, config.LegacyMetadataDir, "metadata-1700000000.json"), `{"createdAt": "2023-11-14T22:13:20Z", "runs": [
+		{"id": "a", "sourceFile": "mainnet.yml", "outputDir": "a-1", "testName": "Transfers",
+		 "testConfig": {"BenchmarkRun": "run-1"}, "result": {"success": false, "complete": false}}
+	]}`)
+	writeFile(t, path.Join(dir, "metadata.json"), `{"runs": [
+		{"id": "a", "sourceFile": "mainnet.yml", "outputDir": "a-1", "testName": "Transfers", "createdAt": "2025-01-01T00:00:00Z",
+		 "testConfig": {"BenchmarkRun": "run-1"}, "result": {"success": true, "complete": true}},
+		{"id": "b", "sourceFile": "mainnet.yml", "outputDir": "b-1", "testName": "Transfers", "createdAt": "2025-01-01T00:00:00Z",
+		 "testConfig": {"BenchmarkRun": "run-1"}},
+		{"id": "c", "sourceFile": "mainnet.yml", "outputDir": "c-1", "testName": "Transfers", "createdAt": "2025-01-01T00:00:00Z",
+		 "testConfig": {}},
+		{"id": "d", "sourceFile": "mainnet.yml", "outputDir": "d-1", "testName": "Transfers", "createdAt": "2025-01-01T00:00:00Z",
+		 "testConfig": {"BenchmarkRun": "run-1"}},
+		{"id": "e", "sourceFile": "mainnet.yml", "testName": "Transfers", "testConfig": {"BenchmarkRun": "run-1"}}
+	]}`)
+	writeFile(t, path.Join(dir, "a-1", "metrics-sequencer.json"), `[]`)
+	writeFile(t, path.Join(dir, "b-1", "metrics-sequencer.json"), `[]`)
+	writeFile(t, path.Join(dir, "c-1", "metrics-sequencer.json"), `[]`)
+
+	result, err := newTestService(t, dir, false).Migrate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &MigrateResult{Written: 2, Skipped: 3}, result)
+
+	// the run in the aggregate file replaces the older legacy copy
+	run := readRunMetadata(t, path.Join(dir, "a-1", benchmark.RunMetadataFileName))
+	require.Equal(t, "a", run.ID)
+	require.True(t, run.Result.Success)
+
+	run = readRunMetadata(t, path.Join(dir, "b-1", benchmark.RunMetadataFileName))
+	require.Equal(t, "b", run.ID)
+
+	// c is missing testConfig.BenchmarkRun and d has no output
+	require.NoFileExists(t, path.Join(dir, "c-1", benchmark.RunMetadataFileName))
+	require.NoDirExists(t, path.Join(dir, "d-1"))
+
+	result, err = newTestService(t, dir, false).Migrate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &MigrateResult{Existing: 2, Skipped: 3}, result)
+}
+
+func TestMigrateUsesGroupCreatedAt(t *testing.T) {
+	dir := t.TempDir()
+	legacy := path.Join(dir, "legacy.json")
+	writeFile(t, legacy, `{"createdAt": "2023-11-14T22:13:20Z", "runs": [
+		{"id": "a", "sourceFile": "mainnet.yml", "outputDir": "a-1", "testName": "Transfers",
+		 "testConfig": {"BenchmarkRun": "run-1"}}
+	]}`)
+	writeFile(t, path.Join(dir, "a-1", "metrics-sequencer.json"), `[]`)
+	writeFile(t, path.Join(dir, "a-1", benchmark.RunMetadataFileName), `{"runs": []}`)
+
+	result, err := newTestService(t, dir, true, legacy).Migrate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &MigrateResult{Written: 1}, result)
+
+	run := readRunMetadata(t, path.Join(dir, "a-1", benchmark.RunMetadataFileName))
+	require.NotNil(t, run.CreatedAt)
+	require.Equal(t, int64(1700000000), run.CreatedAt.Unix())
+}
+
+func TestMigrateWithoutMetadata(t *testing.T) {
+	_, err := newTestService(t, t.TempDir(), false).Migrate(context.Background())
+	require.ErrorContains(t, err, "no metadata files found")
+}
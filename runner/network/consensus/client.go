@@ -2,14 +2,20 @@ package consensus
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/base/base-bench/runner/metrics"
+	networktypes "github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/payload/deposit"
+	"github.com/base/base-bench/runner/timeline"
 	"github.com/ethereum-optimism/optimism/op-service/client"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/pkg/errors"
 )
 
@@ -17,6 +23,9 @@ import (
 type ConsensusClientOptions struct {
 	// BlockTime is the time between FCU and GetPayload calls
 	BlockTime time.Duration
+	// TimestampResolution is the unit of payload timestamps. Zero means whole
+	// seconds.
+	TimestampResolution time.Duration
 	// GasLimit is the gas limit for the payload
 	GasLimit uint64
 	// GasLimitSetup is the gas limit for the setup payload
@@ -25,6 +34,46 @@ type ConsensusClientOptions struct {
 	ParallelTxBatches int
 	// ConsensusTimingMode controls how FCU and getPayload calls are scheduled.
 	ConsensusTimingMode string
+	// BuildWindow is the time between FCU and getPayload calls of the
+	// fixed-window timing mode.
+	BuildWindow time.Duration
+	// TimingJitter is the maximum random delay of FCU and getPayload calls
+	// of the jittered timing mode.
+	TimingJitter time.Duration
+	// L1OriginInterval is the number of L2 blocks per simulated L1 origin.
+	// Zero derives it from the block time and a 12s L1 block time.
+	L1OriginInterval uint64
+	// Deposits, if set, are the user deposits added to the first block of
+	// each simulated L1 origin.
+	Deposits *deposit.Definition
+	// FeeParams are the fee parameters of the payload attributes and the L1
+	// info transaction.
+	FeeParams networktypes.FeeParams
+	// Timeline, if set, records the engine API calls of the client.
+	Timeline *timeline.Timeline
+	// ChainConfig selects the engine API methods and payload attributes by
+	// the forks active at each payload's timestamp. If nil, the newest
+	// supported fork is assumed.
+	ChainConfig *params.ChainConfig
+	// EngineTracer, if set, reports the engine API call durations measured
+	// upstream of the client's JSON handling, which are recorded alongside
+	// the client's own latency metrics.
+	EngineTracer EngineTracer
+}
+
+// EngineTracer reports engine API call durations measured outside the runner,
+// like by a tracing proxy in front of the auth RPC.
+type EngineTracer interface {
+	// LastDuration returns the duration of the last call of method.
+	LastDuration(method string) (time.Duration, bool)
+}
+
+// timestampResolution returns the unit of payload timestamps.
+func (o ConsensusClientOptions) timestampResolution() time.Duration {
+	if o.TimestampResolution <= 0 {
+		return time.Second
+	}
+	return o.TimestampResolution
 }
 
 // BaseConsensusClient contains common functionality shared between different consensus client implementations.
@@ -38,6 +87,15 @@ type BaseConsensusClient struct {
 	headBlockNumber uint64
 
 	currentPayloadID *engine.PayloadID
+
+	// track is the timeline track the engine API calls are recorded on.
+	track string
+
+	// capabilities are the engine API methods the client reported in
+	// engine_exchangeCapabilities, nil until probed or if it does not
+	// implement the method.
+	capabilities       map[string]bool
+	capabilitiesProbed bool
 }
 
 // NewBaseConsensusClient creates a new base consensus client.
@@ -53,7 +111,63 @@ func NewBaseConsensusClient(log log.Logger, client *ethclient.Client, authClient
 	}
 }
 
+// exchangeCapabilities asks the client which of the engine API methods it
+// supports. A client without engine_exchangeCapabilities is assumed to support
+// the methods of its forks.
+func (b *BaseConsensusClient) exchangeCapabilities(ctx context.Context) {
+	if b.capabilitiesProbed {
+		return
+	}
+	b.capabilitiesProbed = true
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	var capabilities []string
+	err := b.authClient.CallContext(ctx, &capabilities, "engine_exchangeCapabilities", supportedEngineMethods())
+	if err != nil {
+		b.log.Warn("engine_exchangeCapabilities failed, assuming the client supports the engine methods of its forks", "err", err)
+		return
+	}
+
+	b.capabilities = make(map[string]bool, len(capabilities))
+	for _, method := range capabilities {
+		b.capabilities[method] = true
+	}
+	b.log.Debug("Exchanged engine capabilities", "capabilities", capabilities)
+}
+
+// forkRules returns the rules for a payload with the given timestamp, in
+// units of the timestamp resolution, checking
+// that the client supports their engine API methods.
+func (b *BaseConsensusClient) forkRules(ctx context.Context, timestamp uint64) (ForkRules, error) {
+	rules, err := ForkRulesAt(b.options.ChainConfig, timestamp, b.options.timestampResolution())
+	if err != nil {
+		return ForkRules{}, err
+	}
+
+	b.exchangeCapabilities(ctx)
+	if b.capabilities != nil {
+		for _, method := range []string{rules.Methods.ForkchoiceUpdated, rules.Methods.GetPayload, rules.Methods.NewPayload} {
+			if !b.capabilities[method] {
+				return ForkRules{}, fmt.Errorf("client does not support %s, required by %s", method, rules.Fork)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// updateForkChoice calls engine_forkchoiceUpdated, starting to build a payload
+// if payloadAttrs is set.
 func (f *BaseConsensusClient) updateForkChoice(ctx context.Context, payloadAttrs *eth.PayloadAttributes) (*eth.PayloadID, error) {
+	method := forkchoiceOnlyMethod()
+	if payloadAttrs != nil {
+		rules, err := f.forkRules(ctx, uint64(payloadAttrs.Timestamp))
+		if err != nil {
+			return nil, err
+		}
+		method = rules.Methods.ForkchoiceUpdated
+	}
+
 	fcu := engine.ForkchoiceStateV1{
 		HeadBlockHash:      f.headBlockHash,
 		SafeBlockHash:      f.headBlockHash,
@@ -63,7 +177,9 @@ func (f *BaseConsensusClient) updateForkChoice(ctx context.Context, payloadAttrs
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	var resp engine.ForkChoiceResponse
-	err := f.authClient.CallContext(ctx, &resp, "engine_forkchoiceUpdatedV3", fcu, payloadAttrs)
+	span := f.options.Timeline.Begin(f.track, "engine", method, "head", f.headBlockNumber, "build", payloadAttrs != nil)
+	err := f.authClient.CallContext(ctx, &resp, method, fcu, payloadAttrs)
+	span.End("err", err)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to propose block")
@@ -72,28 +188,41 @@ func (f *BaseConsensusClient) updateForkChoice(ctx context.Context, payloadAttrs
 	return resp.PayloadID, nil
 }
 
-// getBuiltPayload retrieves the built payload for the given payload ID.
-func (b *BaseConsensusClient) getBuiltPayload(ctx context.Context, payloadID engine.PayloadID) (*engine.ExecutableData, error) {
+// getBuiltPayload retrieves the built payload for the given payload ID using
+// the getPayload method of the payload's fork.
+func (b *BaseConsensusClient) getBuiltPayload(ctx context.Context, payloadID engine.PayloadID, rules ForkRules) (*engine.ExecutableData, error) {
 	ctx, cancel := context.WithTimeout(ctx, 240*time.Second)
 	defer cancel()
 	var payloadResp engine.ExecutionPayloadEnvelope
-	err := b.authClient.CallContext(ctx, &payloadResp, "engine_getPayloadV4", payloadID)
+	method := rules.Methods.GetPayload
+	span := b.options.Timeline.Begin(b.track, "engine", method, "payloadID", payloadID.String())
+	err := b.authClient.CallContext(ctx, &payloadResp, method, payloadID)
 	if err != nil {
+		span.End("err", err)
 		return nil, errors.Wrap(err, "failed to get payload")
 	}
+	span.End("number", payloadResp.ExecutionPayload.Number, "txs", len(payloadResp.ExecutionPayload.Transactions))
 
 	b.log.Debug("Built payload", "parent_hash", payloadResp.ExecutionPayload.ParentHash, "stateRoot", payloadResp.ExecutionPayload.StateRoot)
 
 	return payloadResp.ExecutionPayload, nil
 }
 
-// newPayload calls engine_newPayloadV4 with the given executable data.
+// newPayload calls the newPayload method of the payload's fork with the given
+// executable data.
 func (b *BaseConsensusClient) newPayload(ctx context.Context, params *engine.ExecutableData, beaconRoot common.Hash) error {
+	rules, err := b.forkRules(ctx, params.Timestamp)
+	if err != nil {
+		return err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	var resp engine.ForkChoiceResponse
-	err := b.authClient.CallContext(ctx, &resp, "engine_newPayloadV4", params, []common.Hash{}, beaconRoot, []common.Hash{})
+	method := rules.Methods.NewPayload
+	span := b.options.Timeline.Begin(b.track, "engine", method, "number", params.Number, "txs", len(params.Transactions))
+	err = b.authClient.CallContext(ctx, &resp, method, rules.NewPayloadParams(params, beaconRoot)...)
+	span.End("err", err)
 
 	if err != nil {
 		return errors.Wrap(err, "newPayload call failed")
@@ -101,3 +230,14 @@ func (b *BaseConsensusClient) newPayload(ctx context.Context, params *engine.Exe
 
 	return nil
 }
+
+// addEngineLatency records the duration of the last call of method reported by
+// the engine tracer, if there is one.
+func (b *BaseConsensusClient) addEngineLatency(blockMetrics *metrics.BlockMetrics, name string, method string) {
+	if b.options.EngineTracer == nil {
+		return
+	}
+	if duration, ok := b.options.EngineTracer.LastDuration(method); ok {
+		blockMetrics.AddExecutionMetric(name, duration)
+	}
+}
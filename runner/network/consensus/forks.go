@@ -0,0 +1,125 @@
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// l1InfoFormat is the calldata layout of the L1 info deposit transaction.
+type l1InfoFormat int
+
+const (
+	// l1InfoEcotone is setL1BlockValuesEcotone(), used from Ecotone through
+	// Holocene.
+	l1InfoEcotone l1InfoFormat = iota
+	// l1InfoIsthmus adds the operator fee params.
+	l1InfoIsthmus
+	// l1InfoJovian adds the DA footprint gas scalar.
+	l1InfoJovian
+)
+
+// EngineMethods are the engine API methods used for the payloads of a fork.
+type EngineMethods struct {
+	ForkchoiceUpdated string
+	GetPayload        string
+	NewPayload        string
+}
+
+// ForkRules describe how payloads are built and imported under a fork.
+type ForkRules struct {
+	// Fork is the name of the latest active fork.
+	Fork    string
+	Methods EngineMethods
+
+	l1Info l1InfoFormat
+	// eip1559Params is set from Holocene, which reads the EIP-1559 params
+	// from the payload attributes.
+	eip1559Params bool
+	// minBaseFee is set from Jovian.
+	minBaseFee bool
+	// executionRequests is set from Isthmus, whose newPayload takes the
+	// execution requests.
+	executionRequests bool
+}
+
+var (
+	ecotoneEngineMethods = EngineMethods{
+		ForkchoiceUpdated: "engine_forkchoiceUpdatedV3",
+		GetPayload:        "engine_getPayloadV3",
+		NewPayload:        "engine_newPayloadV3",
+	}
+	isthmusEngineMethods = EngineMethods{
+		ForkchoiceUpdated: "engine_forkchoiceUpdatedV3",
+		GetPayload:        "engine_getPayloadV4",
+		NewPayload:        "engine_newPayloadV4",
+	}
+)
+
+// supportedForks lists the forks payloads can be built for, newest first.
+// Supporting a new fork means adding an entry here, and a new l1InfoFormat if
+// it changes the L1 info transaction.
+var supportedForks = []struct {
+	active func(*params.ChainConfig, uint64) bool
+	rules  ForkRules
+}{
+	{(*params.ChainConfig).IsOptimismJovian, ForkRules{Fork: "jovian", Methods: isthmusEngineMethods, l1Info: l1InfoJovian, eip1559Params: true, minBaseFee: true, executionRequests: true}},
+	{(*params.ChainConfig).IsOptimismIsthmus, ForkRules{Fork: "isthmus", Methods: isthmusEngineMethods, l1Info: l1InfoIsthmus, eip1559Params: true, executionRequests: true}},
+	{(*params.ChainConfig).IsOptimismHolocene, ForkRules{Fork: "holocene", Methods: ecotoneEngineMethods, l1Info: l1InfoEcotone, eip1559Params: true}},
+	{(*params.ChainConfig).IsOptimismGranite, ForkRules{Fork: "granite", Methods: ecotoneEngineMethods, l1Info: l1InfoEcotone}},
+	{(*params.ChainConfig).IsOptimismFjord, ForkRules{Fork: "fjord", Methods: ecotoneEngineMethods, l1Info: l1InfoEcotone}},
+	{(*params.ChainConfig).IsOptimismEcotone, ForkRules{Fork: "ecotone", Methods: ecotoneEngineMethods, l1Info: l1InfoEcotone}},
+}
+
+// ForkRulesAt returns the rules for a payload with the given timestamp in
+// units of resolution. Fork times in the chain config are whole seconds, so
+// the timestamp is converted to seconds before the lookup. A nil chain config
+// assumes the newest supported fork.
+func ForkRulesAt(config *params.ChainConfig, timestamp uint64, resolution time.Duration) (ForkRules, error) {
+	if config == nil {
+		return supportedForks[0].rules, nil
+	}
+	if !config.IsOptimism() {
+		return ForkRules{}, fmt.Errorf("chain %v is not an OP Stack chain", config.ChainID)
+	}
+	seconds := uint64(payloadTime(timestamp, resolution).Unix())
+	for _, fork := range supportedForks {
+		if fork.active(config, seconds) {
+			return fork.rules, nil
+		}
+	}
+	return ForkRules{}, fmt.Errorf("no supported fork is active at time %d, ecotone is the oldest supported fork", seconds)
+}
+
+// NewPayloadParams returns the params of the rules' newPayload method.
+func (r ForkRules) NewPayloadParams(payload interface{}, beaconRoot common.Hash) []interface{} {
+	if r.executionRequests {
+		return []interface{}{payload, []common.Hash{}, beaconRoot, []common.Hash{}}
+	}
+	return []interface{}{payload, []common.Hash{}, beaconRoot}
+}
+
+// forkchoiceOnlyMethod returns the method of forkchoice updates without
+// payload attributes, which does not depend on the fork.
+func forkchoiceOnlyMethod() string {
+	return supportedForks[0].rules.Methods.ForkchoiceUpdated
+}
+
+// supportedEngineMethods returns every engine API method the consensus
+// clients may call, for engine_exchangeCapabilities.
+func supportedEngineMethods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, fork := range supportedForks {
+		m := fork.rules.Methods
+		for _, method := range []string{m.ForkchoiceUpdated, m.GetPayload, m.NewPayload} {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods
+}
@@ -0,0 +1,155 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
+
+func testChainConfig() *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainID:      big.NewInt(8453),
+		Optimism:     &params.OptimismConfig{},
+		CanyonTime:   uint64Ptr(0),
+		EcotoneTime:  uint64Ptr(10),
+		FjordTime:    uint64Ptr(20),
+		GraniteTime:  uint64Ptr(30),
+		HoloceneTime: uint64Ptr(40),
+		IsthmusTime:  uint64Ptr(50),
+		JovianTime:   uint64Ptr(60),
+	}
+}
+
+func TestForkRulesAt(t *testing.T) {
+	config := testChainConfig()
+
+	tests := []struct {
+		timestamp  uint64
+		fork       string
+		newPayload string
+		getPayload string
+		l1Info     l1InfoFormat
+	}{
+		{10, "ecotone", "engine_newPayloadV3", "engine_getPayloadV3", l1InfoEcotone},
+		{25, "fjord", "engine_newPayloadV3", "engine_getPayloadV3", l1InfoEcotone},
+		{30, "granite", "engine_newPayloadV3", "engine_getPayloadV3", l1InfoEcotone},
+		{45, "holocene", "engine_newPayloadV3", "engine_getPayloadV3", l1InfoEcotone},
+		{50, "isthmus", "engine_newPayloadV4", "engine_getPayloadV4", l1InfoIsthmus},
+		{1000, "jovian", "engine_newPayloadV4", "engine_getPayloadV4", l1InfoJovian},
+	}
+	for _, tt := range tests {
+		rules, err := ForkRulesAt(config, tt.timestamp, time.Second)
+		if err != nil {
+			t.Fatalf("timestamp %d: unexpected error: %v", tt.timestamp, err)
+		}
+		if rules.Fork != tt.fork || rules.Methods.NewPayload != tt.newPayload || rules.Methods.GetPayload != tt.getPayload || rules.l1Info != tt.l1Info {
+			t.Fatalf("timestamp %d: unexpected rules %+v", tt.timestamp, rules)
+		}
+		if rules.Methods.ForkchoiceUpdated != "engine_forkchoiceUpdatedV3" {
+			t.Fatalf("timestamp %d: unexpected forkchoice method %s", tt.timestamp, rules.Methods.ForkchoiceUpdated)
+		}
+		if rules.eip1559Params != (tt.timestamp >= 40) || rules.minBaseFee != (tt.timestamp >= 60) {
+			t.Fatalf("timestamp %d: unexpected payload attribute rules %+v", tt.timestamp, rules)
+		}
+	}
+
+	if _, err := ForkRulesAt(config, 5, time.Second); err == nil {
+		t.Fatal("expected an error before ecotone")
+	}
+
+	config.Optimism = nil
+	if _, err := ForkRulesAt(config, 100, time.Second); err == nil {
+		t.Fatal("expected an error for a non OP Stack chain")
+	}
+
+	rules, err := ForkRulesAt(nil, 0, time.Second)
+	if err != nil || rules.Fork != "jovian" {
+		t.Fatalf("expected the newest fork without a chain config, got %+v, %v", rules, err)
+	}
+}
+
+func TestForkRulesAtConvertsMillisecondTimestamps(t *testing.T) {
+	config := testChainConfig()
+
+	rules, err := ForkRulesAt(config, 45_500, time.Millisecond)
+	if err != nil || rules.Fork != "holocene" {
+		t.Fatalf("expected holocene at 45.5s, got %+v, %v", rules, err)
+	}
+	rules, err = ForkRulesAt(config, 50_000, time.Millisecond)
+	if err != nil || rules.Fork != "isthmus" {
+		t.Fatalf("expected isthmus at 50s, got %+v, %v", rules, err)
+	}
+	if _, err := ForkRulesAt(config, 5_000, time.Millisecond); err == nil {
+		t.Fatal("expected an error before ecotone")
+	}
+}
+
+func TestForkRulesNewPayloadParams(t *testing.T) {
+	root := common.HexToHash("0x01")
+
+	holocene, _ := ForkRulesAt(testChainConfig(), 40, time.Second)
+	if params := holocene.NewPayloadParams(nil, root); len(params) != 3 || params[2] != root {
+		t.Fatalf("unexpected newPayloadV3 params %v", params)
+	}
+
+	isthmus, _ := ForkRulesAt(testChainConfig(), 50, time.Second)
+	if params := isthmus.NewPayloadParams(nil, root); len(params) != 4 || params[2] != root {
+		t.Fatalf("unexpected newPayloadV4 params %v", params)
+	}
+}
+
+func TestMarshalL1Info(t *testing.T) {
+	info := &derive.L1BlockInfo{
+		BaseFee:     big.NewInt(1),
+		BlobBaseFee: big.NewInt(1),
+	}
+
+	tests := []struct {
+		format    l1InfoFormat
+		length    int
+		signature []byte
+	}{
+		{l1InfoEcotone, derive.L1InfoEcotoneLen, derive.L1InfoFuncEcotoneBytes4},
+		{l1InfoIsthmus, derive.L1InfoIsthmusLen, derive.L1InfoFuncIsthmusBytes4},
+		{l1InfoJovian, derive.L1InfoJovianLen, derive.L1InfoFuncJovianBytes4},
+	}
+	for _, tt := range tests {
+		data, err := marshalL1Info(info, tt.format)
+		if err != nil {
+			t.Fatalf("format %d: unexpected error: %v", tt.format, err)
+		}
+		if len(data) != tt.length {
+			t.Fatalf("format %d: expected %d bytes, got %d", tt.format, tt.length, len(data))
+		}
+		if string(data[:4]) != string(tt.signature) {
+			t.Fatalf("format %d: unexpected selector %x", tt.format, data[:4])
+		}
+	}
+}
+
+func TestSupportedEngineMethods(t *testing.T) {
+	methods := supportedEngineMethods()
+	want := []string{
+		"engine_forkchoiceUpdatedV3",
+		"engine_getPayloadV4",
+		"engine_newPayloadV4",
+		"engine_getPayloadV3",
+		"engine_newPayloadV3",
+	}
+	if len(methods) != len(want) {
+		t.Fatalf("expected %v, got %v", want, methods)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, methods)
+		}
+	}
+}
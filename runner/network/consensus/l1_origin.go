@@ -0,0 +1,132 @@
+package consensus
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// l1BlockTime is the time between blocks of the simulated L1.
+	l1BlockTime = 12 * time.Second
+
+	// initialL1BaseFee and initialL1BlobBaseFee are the fees of the first
+	// simulated L1 origin.
+	initialL1BaseFee     = 10_000_000_000 // 10 gwei
+	initialL1BlobBaseFee = 1_000_000      // 1 mwei
+
+	// minL1BaseFee and minL1BlobBaseFee bound the simulated fees from below.
+	minL1BaseFee     = 7
+	minL1BlobBaseFee = 1
+)
+
+// l1Origin is the L1 block an L2 block is derived from, along with the L2
+// block's position in the L1 block's epoch.
+type l1Origin struct {
+	Number         uint64
+	Time           uint64
+	Hash           common.Hash
+	BaseFee        *big.Int
+	BlobBaseFee    *big.Int
+	SequenceNumber uint64
+}
+
+// l1OriginSimulator produces the L1 origins of consecutive L2 blocks without
+// an L1 chain. The origin advances every interval L2 blocks, like a sequencer
+// following an L1 with a longer block time, and its base fee and blob base fee
+// move the way EIP-1559 and EIP-4844 fees do under varying L1 demand.
+type l1OriginSimulator struct {
+	interval uint64
+	random   *rand.Rand
+
+	origin  l1Origin
+	started bool
+}
+
+// newL1OriginSimulator creates a simulator whose origin advances every
+// interval L2 blocks. A zero interval advances it once per L1 block time of
+// L2 blocks. The seed makes the fee movement reproducible.
+func newL1OriginSimulator(interval uint64, l2BlockTime time.Duration, seed int64) *l1OriginSimulator {
+	if interval == 0 {
+		interval = 1
+		if l2BlockTime > 0 && l2BlockTime < l1BlockTime {
+			interval = uint64(l1BlockTime / l2BlockTime)
+		}
+	}
+
+	return &l1OriginSimulator{
+		interval: interval,
+		random:   rand.New(rand.NewSource(seed)),
+		origin: l1Origin{
+			Number:      1,
+			Time:        uint64(time.Now().Add(-l1BlockTime).Unix()),
+			BaseFee:     big.NewInt(initialL1BaseFee),
+			BlobBaseFee: big.NewInt(initialL1BlobBaseFee),
+		},
+	}
+}
+
+// Next returns the L1 origin of the next L2 block. It reports whether the
+// origin advanced, in which case the sequence number starts over at zero.
+func (s *l1OriginSimulator) Next() (l1Origin, bool) {
+	switch {
+	case !s.started:
+		s.started = true
+		s.origin.Hash = l1BlockHash(s.origin.Number)
+		return s.copyOrigin(), true
+	case s.origin.SequenceNumber+1 < s.interval:
+		s.origin.SequenceNumber++
+		return s.copyOrigin(), false
+	}
+
+	s.origin.Number++
+	s.origin.Time += uint64(l1BlockTime / time.Second)
+	s.origin.Hash = l1BlockHash(s.origin.Number)
+	s.origin.BaseFee = nextFee(s.origin.BaseFee, s.random.Float64(), minL1BaseFee)
+	s.origin.BlobBaseFee = nextFee(s.origin.BlobBaseFee, s.random.Float64(), minL1BlobBaseFee)
+	s.origin.SequenceNumber = 0
+	return s.copyOrigin(), true
+}
+
+func (s *l1OriginSimulator) copyOrigin() l1Origin {
+	origin := s.origin
+	origin.BaseFee = new(big.Int).Set(s.origin.BaseFee)
+	origin.BlobBaseFee = new(big.Int).Set(s.origin.BlobBaseFee)
+	return origin
+}
+
+// nextFee returns the fee of the next L1 block given the fullness of the
+// current one, between 0 and 1. Like EIP-1559, a block at the target of half
+// full keeps the fee, and a full or empty one moves it by an eighth. Any
+// other fullness moves the fee by at least 1 wei, so that a small fee can
+// still rise.
+func nextFee(fee *big.Int, fullness float64, minFee int64) *big.Int {
+	// change in 1/1000ths of a percent, -12500 to 12500
+	change := int64((fullness*2 - 1) * 12500)
+	delta := new(big.Int).Mul(fee, big.NewInt(change))
+	delta.Quo(delta, big.NewInt(100_000))
+	if delta.Sign() == 0 && change != 0 {
+		if change > 0 {
+			delta.SetInt64(1)
+		} else {
+			delta.SetInt64(-1)
+		}
+	}
+
+	next := new(big.Int).Add(fee, delta)
+	if next.Cmp(big.NewInt(minFee)) < 0 {
+		next.SetInt64(minFee)
+	}
+	return next
+}
+
+// l1BlockHash returns a stand-in hash for the simulated L1 block.
+func l1BlockHash(number uint64) common.Hash {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], number)
+	return crypto.Keccak256Hash([]byte("fake-l1-block"), buf[:])
+}
@@ -0,0 +1,110 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestL1OriginSimulatorAdvancesEveryInterval(t *testing.T) {
+	sim := newL1OriginSimulator(3, 2*time.Second, 1)
+
+	var origins []l1Origin
+	var advanced []bool
+	for i := 0; i < 7; i++ {
+		origin, adv := sim.Next()
+		origins = append(origins, origin)
+		advanced = append(advanced, adv)
+	}
+
+	wantNumbers := []uint64{1, 1, 1, 2, 2, 2, 3}
+	wantSequence := []uint64{0, 1, 2, 0, 1, 2, 0}
+	wantAdvanced := []bool{true, false, false, true, false, false, true}
+	for i, origin := range origins {
+		if origin.Number != wantNumbers[i] || origin.SequenceNumber != wantSequence[i] || advanced[i] != wantAdvanced[i] {
+			t.Fatalf("block %d: unexpected origin %d seq %d advanced %v", i, origin.Number, origin.SequenceNumber, advanced[i])
+		}
+	}
+
+	if origins[3].Time != origins[0].Time+12 {
+		t.Fatalf("expected origin time to advance by 12s, got %d -> %d", origins[0].Time, origins[3].Time)
+	}
+	if origins[0].Hash == origins[3].Hash || origins[0].Hash != origins[2].Hash {
+		t.Fatal("expected the origin hash to change only with the origin")
+	}
+
+	// fees only change with the origin and by at most an eighth
+	if origins[0].BaseFee.Cmp(origins[2].BaseFee) != 0 {
+		t.Fatal("expected the base fee to stay within an epoch")
+	}
+	for _, i := range []int{3, 6} {
+		for _, fees := range [][2]*big.Int{
+			{origins[i-1].BaseFee, origins[i].BaseFee},
+			{origins[i-1].BlobBaseFee, origins[i].BlobBaseFee},
+		} {
+			diff := new(big.Int).Sub(fees[1], fees[0])
+			limit := new(big.Int).Quo(fees[0], big.NewInt(8))
+			if diff.CmpAbs(limit) > 0 {
+				t.Fatalf("fee moved from %s to %s, more than an eighth", fees[0], fees[1])
+			}
+		}
+	}
+}
+
+func TestL1OriginSimulatorDefaultInterval(t *testing.T) {
+	tests := []struct {
+		blockTime time.Duration
+		interval  uint64
+	}{
+		{2 * time.Second, 6},
+		{200 * time.Millisecond, 60},
+		{12 * time.Second, 1},
+		{30 * time.Second, 1},
+	}
+	for _, tt := range tests {
+		if sim := newL1OriginSimulator(0, tt.blockTime, 1); sim.interval != tt.interval {
+			t.Fatalf("block time %s: expected interval %d, got %d", tt.blockTime, tt.interval, sim.interval)
+		}
+	}
+}
+
+func TestL1OriginSimulatorIsReproducible(t *testing.T) {
+	a := newL1OriginSimulator(1, time.Second, 42)
+	b := newL1OriginSimulator(1, time.Second, 42)
+	for i := 0; i < 20; i++ {
+		originA, _ := a.Next()
+		originB, _ := b.Next()
+		if originA.BaseFee.Cmp(originB.BaseFee) != 0 || originA.BlobBaseFee.Cmp(originB.BlobBaseFee) != 0 {
+			t.Fatalf("block %d: fees differ for the same seed", i)
+		}
+		// returned origins are copies
+		originA.BaseFee.SetInt64(0)
+	}
+}
+
+func TestNextFeeRespectsMinimum(t *testing.T) {
+	if fee := nextFee(big.NewInt(7), 0, 7); fee.Int64() != 7 {
+		t.Fatalf("expected the minimum fee, got %s", fee)
+	}
+	if fee := nextFee(big.NewInt(800), 1, 7); fee.Int64() != 900 {
+		t.Fatalf("expected a full block to raise the fee by an eighth, got %s", fee)
+	}
+	if fee := nextFee(big.NewInt(800), 0.5, 7); fee.Int64() != 800 {
+		t.Fatalf("expected a block at target to keep the fee, got %s", fee)
+	}
+}
+
+func TestNextFeeMovesSmallFeesByAtLeastOne(t *testing.T) {
+	if fee := nextFee(big.NewInt(7), 1, 7); fee.Int64() != 8 {
+		t.Fatalf("expected a full block to raise a small fee by 1, got %s", fee)
+	}
+	if fee := nextFee(big.NewInt(1), 0.75, 1); fee.Int64() != 2 {
+		t.Fatalf("expected a block above target to raise the minimum fee, got %s", fee)
+	}
+	if fee := nextFee(big.NewInt(5), 0.25, 1); fee.Int64() != 4 {
+		t.Fatalf("expected a block below target to lower a small fee by 1, got %s", fee)
+	}
+	if fee := nextFee(big.NewInt(5), 0.5, 1); fee.Int64() != 5 {
+		t.Fatalf("expected a block at target to keep a small fee, got %s", fee)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/base/base-bench/runner/network/mempool"
 	"github.com/base/base-bench/runner/network/proofprogram/fakel1"
 	networktypes "github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/timeline"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum-optimism/optimism/op-service/client"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
@@ -36,17 +37,24 @@ type SequencerConsensusClient struct {
 	mempool       mempool.FakeMempool
 	l1Chain       fakel1.L1Chain
 	batcherAddr   common.Address
+	// l1Origins simulates the L1 origin when there is no L1 chain.
+	l1Origins *l1OriginSimulator
+	// timing schedules the FCU and getPayload calls of each block.
+	timing TimingStrategy
 }
 
 // NewSequencerConsensusClient creates a new consensus client using the given genesis hash and timestamp.
 func NewSequencerConsensusClient(log log.Logger, client *ethclient.Client, authClient client.RPC, mempool mempool.FakeMempool, options ConsensusClientOptions, headBlockHash common.Hash, headBlockNumber uint64, l1Chain fakel1.L1Chain, batcherAddr common.Address) *SequencerConsensusClient {
 	base := NewBaseConsensusClient(log, client, authClient, options, headBlockHash, headBlockNumber)
+	base.track = timeline.TrackSequencer
 	return &SequencerConsensusClient{
 		BaseConsensusClient: base,
-		lastTimestamp:       uint64(time.Now().Unix()),
+		lastTimestamp:       toPayloadTimestamp(time.Now(), options.timestampResolution()),
 		mempool:             mempool,
 		l1Chain:             l1Chain,
 		batcherAddr:         batcherAddr,
+		l1Origins:           newL1OriginSimulator(options.L1OriginInterval, options.BlockTime, int64(headBlockNumber)),
+		timing:              newTimingStrategy(options, int64(headBlockNumber)),
 	}
 }
 
@@ -62,9 +70,23 @@ func (f *SequencerConsensusClient) Stop(ctx context.Context) error {
 	return nil
 }
 
-// marshalBinaryWithSignature creates the call data for an L1Info transaction.
-func marshalBinaryWithSignature(info *derive.L1BlockInfo, signature []byte) ([]byte, error) {
-	w := bytes.NewBuffer(make([]byte, 0, derive.L1InfoJovianLen))
+// marshalL1Info creates the call data for an L1Info transaction in the given
+// format.
+func marshalL1Info(info *derive.L1BlockInfo, format l1InfoFormat) ([]byte, error) {
+	var signature []byte
+	var size int
+	switch format {
+	case l1InfoEcotone:
+		signature, size = derive.L1InfoFuncEcotoneBytes4, derive.L1InfoEcotoneLen
+	case l1InfoIsthmus:
+		signature, size = derive.L1InfoFuncIsthmusBytes4, derive.L1InfoIsthmusLen
+	case l1InfoJovian:
+		signature, size = derive.L1InfoFuncJovianBytes4, derive.L1InfoJovianLen
+	default:
+		return nil, fmt.Errorf("unknown L1 info format %d", format)
+	}
+
+	w := bytes.NewBuffer(make([]byte, 0, size))
 	if err := solabi.WriteSignature(w, signature); err != nil {
 		return nil, err
 	}
@@ -100,59 +122,96 @@ func marshalBinaryWithSignature(info *derive.L1BlockInfo, signature []byte) ([]b
 	if err := solabi.WriteAddress(w, info.BatcherAddr); err != nil {
 		return nil, err
 	}
+	if format < l1InfoIsthmus {
+		return w.Bytes(), nil
+	}
 	if err := binary.Write(w, binary.BigEndian, info.OperatorFeeScalar); err != nil {
 		return nil, err
 	}
 	if err := binary.Write(w, binary.BigEndian, info.OperatorFeeConstant); err != nil {
 		return nil, err
 	}
+	if format < l1InfoJovian {
+		return w.Bytes(), nil
+	}
 	if err := binary.Write(w, binary.BigEndian, info.DAFootprintGasScalar); err != nil {
 		return nil, err
 	}
 	return w.Bytes(), nil
 }
 
-func (f *SequencerConsensusClient) generatePayloadAttributes(sequencerTxs [][]byte, isSetupPayload bool, timestamp uint64) (*eth.PayloadAttributes, *common.Hash, error) {
+// nextL1Origin returns the L1 origin of the next payload and whether it is the
+// first payload of the origin. With an L1 chain, every payload uses its first
+// block so that the proof program can derive them; otherwise the origin is
+// simulated.
+func (f *SequencerConsensusClient) nextL1Origin() (l1Origin, bool, error) {
+	if f.l1Chain != nil {
+		block, err := f.l1Chain.GetBlockByNumber(1)
+		if err != nil {
+			return l1Origin{}, false, fmt.Errorf("failed to get block by number: %w", err)
+		}
+		return l1Origin{
+			Number:         block.NumberU64(),
+			Time:           block.Time(),
+			Hash:           block.Hash(),
+			BaseFee:        block.BaseFee(),
+			BlobBaseFee:    big.NewInt(1),
+			SequenceNumber: f.headBlockNumber,
+		}, false, nil
+	}
+
+	origin, advanced := f.l1Origins.Next()
+	if advanced {
+		f.log.Debug("Advanced L1 origin", "number", origin.Number, "base_fee", origin.BaseFee, "blob_base_fee", origin.BlobBaseFee)
+		f.options.Timeline.Instant(f.track, "l1", "l1 origin advanced", "number", origin.Number, "baseFee", origin.BaseFee, "blobBaseFee", origin.BlobBaseFee)
+	}
+	return origin, advanced, nil
+}
+
+// userDeposits returns the user deposits of a payload, which are only added
+// to the first benchmark payload of each L1 origin.
+func (f *SequencerConsensusClient) userDeposits(origin l1Origin, advanced bool, isSetupPayload bool) ([][]byte, error) {
+	if f.options.Deposits == nil || !advanced || isSetupPayload {
+		return nil, nil
+	}
+	deposits, err := f.options.Deposits.Transactions(origin.Hash, origin.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate user deposits: %w", err)
+	}
+	return deposits, nil
+}
+
+// generatePayloadAttributes creates the attributes of a payload built on the
+// given L1 origin. The L1 info transaction is followed by the user deposits
+// and then by the sequencer transactions from the mempool.
+func (f *SequencerConsensusClient) generatePayloadAttributes(origin l1Origin, userDeposits [][]byte, sequencerTxs [][]byte, isSetupPayload bool, timestamp uint64, rules ForkRules) (*eth.PayloadAttributes, *common.Hash, error) {
 	gasLimit := eth.Uint64Quantity(f.options.GasLimit)
 	if isSetupPayload {
 		gasLimit = eth.Uint64Quantity(f.options.GasLimitSetup)
 	}
 
-	var b8 eth.Bytes8
-	copy(b8[:], eip1559.EncodeHolocene1559Params(50, 1))
+	fees := f.options.FeeParams.Resolve()
 
-	number := uint64(0)
-	time := uint64(0)
-	baseFee := big.NewInt(1)
-	blockHash := common.Hash{}
-	if f.l1Chain != nil {
-		block, err := f.l1Chain.GetBlockByNumber(1)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get block by number: %w", err)
-		}
-		number = block.NumberU64()
-		time = block.Time()
-		baseFee = block.BaseFee()
-		blockHash = block.Hash()
-	}
+	var b8 eth.Bytes8
+	copy(b8[:], eip1559.EncodeHolocene1559Params(uint64(fees.EIP1559Denominator), uint64(fees.EIP1559Elasticity)))
 
 	l1BlockInfo := &derive.L1BlockInfo{
-		Number:               number,
-		Time:                 time,
-		BaseFee:              baseFee,
-		BlockHash:            blockHash,
-		SequenceNumber:       f.headBlockNumber,
-		BatcherAddr:          f.batcherAddr,
-		BlobBaseFee:          big.NewInt(1),
-		BaseFeeScalar:        1,
-		BlobBaseFeeScalar:    1,
-		OperatorFeeScalar:    0,
-		OperatorFeeConstant:  0,
-		// Intentionally 0: disables the post-Jovian DA footprint cap so
+		Number:              origin.Number,
+		Time:                origin.Time,
+		BaseFee:             origin.BaseFee,
+		BlockHash:           origin.Hash,
+		SequenceNumber:      origin.SequenceNumber,
+		BatcherAddr:         f.batcherAddr,
+		BlobBaseFee:         origin.BlobBaseFee,
+		BaseFeeScalar:       fees.BaseFeeScalar,
+		BlobBaseFeeScalar:   fees.BlobBaseFeeScalar,
+		OperatorFeeScalar:   fees.OperatorFeeScalar,
+		OperatorFeeConstant: fees.OperatorFeeConstant,
+		// Defaults to 0, which disables the post-Jovian DA footprint cap so
 		// the benchmark measures raw EL gas throughput rather than L1 DA
 		// budget. With a non-zero scalar, cheap-tx workloads plateau at
 		// ~50% of the configured gas limit regardless of EL capacity.
-		DAFootprintGasScalar: 0,
+		DAFootprintGasScalar: fees.DAFootprintGasScalar,
 	}
 
 	source := derive.L1InfoDepositSource{
@@ -160,7 +219,7 @@ func (f *SequencerConsensusClient) generatePayloadAttributes(sequencerTxs [][]by
 		SeqNumber:   l1BlockInfo.SequenceNumber,
 	}
 
-	data, err := marshalBinaryWithSignature(l1BlockInfo, derive.L1InfoFuncJovianBytes4)
+	data, err := marshalL1Info(l1BlockInfo, rules.l1Info)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -183,15 +242,18 @@ func (f *SequencerConsensusClient) generatePayloadAttributes(sequencerTxs [][]by
 		return nil, nil, fmt.Errorf("failed to encode L1 info tx: %w", err)
 	}
 
-	sequencerTxsHexBytes := make([]hexutil.Bytes, len(sequencerTxs)+1)
-	sequencerTxsHexBytes[0] = hexutil.Bytes(opaqueL1Tx)
-	for i, tx := range sequencerTxs {
-		sequencerTxsHexBytes[i+1] = hexutil.Bytes(tx)
+	sequencerTxsHexBytes := make([]hexutil.Bytes, 0, len(userDeposits)+len(sequencerTxs)+1)
+	sequencerTxsHexBytes = append(sequencerTxsHexBytes, hexutil.Bytes(opaqueL1Tx))
+	for _, tx := range userDeposits {
+		sequencerTxsHexBytes = append(sequencerTxsHexBytes, hexutil.Bytes(tx))
+	}
+	for _, tx := range sequencerTxs {
+		sequencerTxsHexBytes = append(sequencerTxsHexBytes, hexutil.Bytes(tx))
 	}
 
 	root := crypto.Keccak256Hash([]byte("fake-beacon-block-root"), big.NewInt(int64(1)).Bytes())
 
-	minBaseFee := uint64(1)
+	minBaseFee := fees.MinBaseFee
 	payloadAttrs := &eth.PayloadAttributes{
 		Timestamp:             eth.Uint64Quantity(timestamp),
 		PrevRandao:            eth.Bytes32{},
@@ -201,31 +263,51 @@ func (f *SequencerConsensusClient) generatePayloadAttributes(sequencerTxs [][]by
 		GasLimit:              &gasLimit,
 		ParentBeaconBlockRoot: &root,
 		NoTxPool:              false,
-		EIP1559Params:         &b8,
-		MinBaseFee:            &minBaseFee,
+	}
+	if rules.eip1559Params {
+		payloadAttrs.EIP1559Params = &b8
+	}
+	if rules.minBaseFee {
+		payloadAttrs.MinBaseFee = &minBaseFee
 	}
 
 	return payloadAttrs, &root, nil
 }
 
-func nextPayloadTimestamp(lastTimestamp uint64, now time.Time, blockTime time.Duration) uint64 {
-	blockTimeSeconds := uint64(blockTime / time.Second)
-	if blockTimeSeconds == 0 {
-		blockTimeSeconds = 1
+// toPayloadTimestamp converts t to a payload timestamp in units of resolution.
+func toPayloadTimestamp(t time.Time, resolution time.Duration) uint64 {
+	return uint64(t.UnixNano() / int64(resolution))
+}
+
+// payloadTime converts a payload timestamp in units of resolution to a time.
+func payloadTime(timestamp uint64, resolution time.Duration) time.Time {
+	return time.Unix(0, int64(timestamp)*int64(resolution))
+}
+
+// nextBlockBoundary returns the first multiple of blockTime since the Unix
+// epoch after now, so that sub-second boundaries line up with whole seconds.
+func nextBlockBoundary(now time.Time, blockTime time.Duration) time.Time {
+	return time.Unix(0, (now.UnixNano()/int64(blockTime)+1)*int64(blockTime))
+}
+
+func nextPayloadTimestamp(lastTimestamp uint64, now time.Time, blockTime time.Duration, resolution time.Duration) uint64 {
+	step := uint64(blockTime / resolution)
+	if step == 0 {
+		step = 1
 	}
 
 	// Match the sequencer cadence when possible: the next payload timestamp is
 	// one block time after the parent. If transaction draining made that slot
 	// too close to wall clock, skip ahead so the builder still has time to work.
-	timestamp := lastTimestamp + blockTimeSeconds
+	timestamp := lastTimestamp + step
 	minLead := blockTime / 2
 	if minLead <= 0 {
-		minLead = time.Second
+		minLead = resolution
 	}
 
 	minDeadline := now.Add(minLead)
-	for time.Unix(int64(timestamp), 0).Before(minDeadline) {
-		timestamp += blockTimeSeconds
+	for payloadTime(timestamp, resolution).Before(minDeadline) {
+		timestamp += step
 	}
 
 	return timestamp
@@ -286,32 +368,33 @@ func (f *SequencerConsensusClient) Propose(ctx context.Context, blockMetrics *me
 	f.log.Info("Sent transactions", "duration", duration, "num_txs", len(sendTxs))
 	blockMetrics.AddExecutionMetric(networktypes.SendTxsLatencyMetric, duration)
 
+	payloadTimestamp, fcuAt := f.timing.Schedule(f.lastTimestamp, time.Now())
+	if sleepDuration := time.Until(fcuAt); sleepDuration > 0 {
+		f.log.Info("Waiting to send FCU", "sleep", sleepDuration, "timing", f.options.ConsensusTimingMode)
+		time.Sleep(sleepDuration)
+	}
 	startBlockBuildingTime := time.Now()
-	var payloadTimestamp uint64
-	var blockDeadline time.Time
-	if f.options.ConsensusTimingMode == networktypes.ConsensusTimingModeBaseConsensus {
-		payloadTimestamp = nextPayloadTimestamp(f.lastTimestamp, startBlockBuildingTime, f.options.BlockTime)
-		blockDeadline = time.Unix(int64(payloadTimestamp), 0)
-	} else {
-		nextBoundary := startBlockBuildingTime.Truncate(f.options.BlockTime).Add(f.options.BlockTime)
-		sleepDuration := time.Until(nextBoundary)
-		f.log.Info("Aligning to next block time boundary before FCU", "sleep", sleepDuration, "block_time", f.options.BlockTime)
-		if sleepDuration > 0 {
-			time.Sleep(sleepDuration)
-		}
-		startBlockBuildingTime = time.Now()
 
-		// Use nextBoundary (the block-time-aligned wall-clock boundary we slept to) plus
-		// one block time as the block timestamp. This guarantees the FCU always arrives
-		// at the same point relative to the block deadline, eliminating the jitter that
-		// causes "FCU arrived too late" and empty blocks when sendTxs takes variable time.
-		blockDeadline = nextBoundary.Add(f.options.BlockTime)
-		payloadTimestamp = uint64(blockDeadline.Unix())
+	rules, err := f.forkRules(ctx, payloadTimestamp)
+	if err != nil {
+		return nil, err
 	}
 
-	f.log.Info("Starting block building")
+	f.log.Info("Starting block building", "fork", rules.Fork)
+
+	origin, advanced, err := f.nextL1Origin()
+	if err != nil {
+		return nil, err
+	}
+	userDeposits, err := f.userDeposits(origin, advanced, isSetupPayload)
+	if err != nil {
+		return nil, err
+	}
+	if f.options.Deposits != nil {
+		blockMetrics.AddExecutionMetric(networktypes.DepositsPerBlockMetric, len(userDeposits))
+	}
 
-	payloadAttrs, beaconRoot, err := f.generatePayloadAttributes(sequencerTxs, isSetupPayload, payloadTimestamp)
+	payloadAttrs, beaconRoot, err := f.generatePayloadAttributes(origin, userDeposits, sequencerTxs, isSetupPayload, payloadTimestamp, rules)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate payload attributes")
 	}
@@ -327,9 +410,10 @@ func (f *SequencerConsensusClient) Propose(ctx context.Context, blockMetrics *me
 	}
 	duration = time.Since(startTime)
 	blockMetrics.AddExecutionMetric(networktypes.UpdateForkChoiceLatencyMetric, duration)
+	f.addEngineLatency(blockMetrics, networktypes.EngineUpdateForkChoiceLatencyMetric, rules.Methods.ForkchoiceUpdated)
 
 	f.currentPayloadID = payloadID
-	waitDuration := time.Until(blockDeadline)
+	waitDuration := time.Until(f.timing.GetPayloadAt(payloadTimestamp, startTime))
 	f.log.Info("Waiting for block deadline", "wait", waitDuration)
 	if waitDuration > 0 {
 		time.Sleep(waitDuration)
@@ -337,7 +421,7 @@ func (f *SequencerConsensusClient) Propose(ctx context.Context, blockMetrics *me
 
 	startTime = time.Now()
 
-	payload, err := f.getBuiltPayload(ctx, *f.currentPayloadID)
+	payload, err := f.getBuiltPayload(ctx, *f.currentPayloadID, rules)
 	if err != nil {
 		return nil, err
 	}
@@ -348,6 +432,7 @@ func (f *SequencerConsensusClient) Propose(ctx context.Context, blockMetrics *me
 
 	duration = time.Since(startTime)
 	blockMetrics.AddExecutionMetric(networktypes.GetPayloadLatencyMetric, duration)
+	f.addEngineLatency(blockMetrics, networktypes.EngineGetPayloadLatencyMetric, rules.Methods.GetPayload)
 	f.log.Info("Fetched built payload", "duration", duration, "txs", len(payload.Transactions), "number", payload.Number, "hash", payload.BlockHash.Hex())
 
 	// get gas usage
@@ -8,7 +8,7 @@ import (
 func TestNextPayloadTimestampUsesNextBlockTime(t *testing.T) {
 	now := time.Unix(100, int64(100*time.Millisecond))
 
-	timestamp := nextPayloadTimestamp(100, now, 2*time.Second)
+	timestamp := nextPayloadTimestamp(100, now, 2*time.Second, time.Second)
 
 	if timestamp != 102 {
 		t.Fatalf("expected next payload timestamp 102, got %d", timestamp)
@@ -18,7 +18,7 @@ func TestNextPayloadTimestampUsesNextBlockTime(t *testing.T) {
 func TestNextPayloadTimestampSkipsTooCloseSlot(t *testing.T) {
 	now := time.Unix(101, int64(250*time.Millisecond))
 
-	timestamp := nextPayloadTimestamp(100, now, 2*time.Second)
+	timestamp := nextPayloadTimestamp(100, now, 2*time.Second, time.Second)
 
 	if timestamp != 104 {
 		t.Fatalf("expected next payload timestamp 104, got %d", timestamp)
@@ -28,9 +28,47 @@ func TestNextPayloadTimestampSkipsTooCloseSlot(t *testing.T) {
 func TestNextPayloadTimestampCatchesUpFromWallClock(t *testing.T) {
 	now := time.Unix(120, 0)
 
-	timestamp := nextPayloadTimestamp(100, now, 2*time.Second)
+	timestamp := nextPayloadTimestamp(100, now, 2*time.Second, time.Second)
 
 	if timestamp != 122 {
 		t.Fatalf("expected next payload timestamp 122, got %d", timestamp)
 	}
 }
+
+func TestNextPayloadTimestampMilliseconds(t *testing.T) {
+	now := time.UnixMilli(100_050)
+
+	timestamp := nextPayloadTimestamp(100_000, now, 200*time.Millisecond, time.Millisecond)
+
+	if timestamp != 100_200 {
+		t.Fatalf("expected next payload timestamp 100200, got %d", timestamp)
+	}
+
+	// the 100_200 slot leaves less than half a block time to build
+	now = time.UnixMilli(100_150)
+
+	timestamp = nextPayloadTimestamp(100_000, now, 200*time.Millisecond, time.Millisecond)
+
+	if timestamp != 100_400 {
+		t.Fatalf("expected next payload timestamp 100400, got %d", timestamp)
+	}
+}
+
+func TestNextBlockBoundaryAlignsToEpoch(t *testing.T) {
+	now := time.Unix(100, int64(450*time.Millisecond))
+
+	boundary := nextBlockBoundary(now, 200*time.Millisecond)
+
+	if !boundary.Equal(time.Unix(100, int64(600*time.Millisecond))) {
+		t.Fatalf("expected boundary at 100.6s, got %s", boundary)
+	}
+	if timestamp := toPayloadTimestamp(boundary, time.Millisecond); timestamp != 100_600 {
+		t.Fatalf("expected payload timestamp 100600, got %d", timestamp)
+	}
+
+	boundary = nextBlockBoundary(time.Unix(100, 0), time.Second)
+
+	if !boundary.Equal(time.Unix(101, 0)) {
+		t.Fatalf("expected boundary at 101s, got %s", boundary)
+	}
+}
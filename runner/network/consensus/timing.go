@@ -0,0 +1,126 @@
+package consensus
+
+import (
+	"math/rand"
+	"time"
+
+	networktypes "github.com/base/base-bench/runner/network/types"
+)
+
+// TimingStrategy schedules the forkchoice update that starts building a
+// payload and the getPayload call that seals it.
+type TimingStrategy interface {
+	// Schedule returns the timestamp of the next payload and when to send its
+	// forkchoice update, given the parent timestamp and the current time.
+	Schedule(lastTimestamp uint64, now time.Time) (timestamp uint64, fcuAt time.Time)
+	// GetPayloadAt returns when to call getPayload for the payload with
+	// timestamp, whose forkchoice update was sent at fcuSent.
+	GetPayloadAt(timestamp uint64, fcuSent time.Time) time.Time
+}
+
+// newTimingStrategy returns the timing strategy of the consensus timing mode
+// of options. Unknown modes fall back to prevent-late-fcu.
+func newTimingStrategy(options ConsensusClientOptions, seed int64) TimingStrategy {
+	blockTime := options.BlockTime
+	resolution := options.timestampResolution()
+
+	switch options.ConsensusTimingMode {
+	case networktypes.ConsensusTimingModeBaseConsensus:
+		return &baseConsensusTiming{blockTime: blockTime, resolution: resolution}
+	case networktypes.ConsensusTimingModeFixedWindow:
+		return &fixedWindowTiming{blockTime: blockTime, resolution: resolution, window: options.BuildWindow}
+	case networktypes.ConsensusTimingModeASAP:
+		return &fixedWindowTiming{blockTime: blockTime, resolution: resolution}
+	case networktypes.ConsensusTimingModeJittered:
+		return &jitteredTiming{
+			baseConsensusTiming: baseConsensusTiming{blockTime: blockTime, resolution: resolution},
+			jitter:              options.TimingJitter,
+			random:              rand.New(rand.NewSource(seed)),
+		}
+	default:
+		return &preventLateFCUTiming{blockTime: blockTime, resolution: resolution}
+	}
+}
+
+// preventLateFCUTiming sends the forkchoice update at a block time boundary
+// and calls getPayload one block time later, so that the forkchoice update
+// always arrives at the same point relative to the block deadline.
+type preventLateFCUTiming struct {
+	blockTime  time.Duration
+	resolution time.Duration
+}
+
+func (t *preventLateFCUTiming) Schedule(_ uint64, now time.Time) (uint64, time.Time) {
+	// Use the block-time-aligned wall-clock boundary plus one block time as
+	// the block timestamp. This eliminates the jitter that causes "FCU arrived
+	// too late" and empty blocks when sendTxs takes variable time.
+	boundary := nextBlockBoundary(now, t.blockTime)
+	return toPayloadTimestamp(boundary.Add(t.blockTime), t.resolution), boundary
+}
+
+func (t *preventLateFCUTiming) GetPayloadAt(timestamp uint64, _ time.Time) time.Time {
+	return payloadTime(timestamp, t.resolution)
+}
+
+// baseConsensusTiming sends the forkchoice update right away and calls
+// getPayload at the payload timestamp, like the Base sequencer.
+type baseConsensusTiming struct {
+	blockTime  time.Duration
+	resolution time.Duration
+}
+
+func (t *baseConsensusTiming) Schedule(lastTimestamp uint64, now time.Time) (uint64, time.Time) {
+	return nextPayloadTimestamp(lastTimestamp, now, t.blockTime, t.resolution), now
+}
+
+func (t *baseConsensusTiming) GetPayloadAt(timestamp uint64, _ time.Time) time.Time {
+	return payloadTime(timestamp, t.resolution)
+}
+
+// fixedWindowTiming sends the forkchoice update right away and calls
+// getPayload a fixed window after it, independent of the block time. A zero
+// window calls getPayload immediately to measure pure build throughput.
+type fixedWindowTiming struct {
+	blockTime  time.Duration
+	resolution time.Duration
+	window     time.Duration
+}
+
+func (t *fixedWindowTiming) Schedule(lastTimestamp uint64, now time.Time) (uint64, time.Time) {
+	// blocks may be built faster than the block time, so timestamps run
+	// ahead of the wall clock rather than repeating
+	step := uint64(t.blockTime / t.resolution)
+	if step == 0 {
+		step = 1
+	}
+	return max(lastTimestamp+step, toPayloadTimestamp(now, t.resolution)), now
+}
+
+func (t *fixedWindowTiming) GetPayloadAt(_ uint64, fcuSent time.Time) time.Time {
+	return fcuSent.Add(t.window)
+}
+
+// jitteredTiming follows baseConsensusTiming but delays both the forkchoice
+// update and the getPayload call by a random duration below jitter, modeling
+// the variable arrival of op-node's engine API calls.
+type jitteredTiming struct {
+	baseConsensusTiming
+	jitter time.Duration
+	random *rand.Rand
+}
+
+func (t *jitteredTiming) Schedule(lastTimestamp uint64, now time.Time) (uint64, time.Time) {
+	fcuAt := now.Add(t.delay())
+	return nextPayloadTimestamp(lastTimestamp, fcuAt, t.blockTime, t.resolution), fcuAt
+}
+
+func (t *jitteredTiming) GetPayloadAt(timestamp uint64, _ time.Time) time.Time {
+	return payloadTime(timestamp, t.resolution).Add(t.delay())
+}
+
+func (t *jitteredTiming) delay() time.Duration {
+	if t.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(t.random.Int63n(int64(t.jitter)))
+}
@@ -0,0 +1,122 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	networktypes "github.com/base/base-bench/runner/network/types"
+)
+
+func TestPreventLateFCUTimingAlignsToBlockBoundary(t *testing.T) {
+	timing := newTimingStrategy(ConsensusClientOptions{BlockTime: 2 * time.Second}, 0)
+	now := time.Unix(101, int64(300*time.Millisecond))
+
+	timestamp, fcuAt := timing.Schedule(100, now)
+
+	if !fcuAt.Equal(time.Unix(102, 0)) {
+		t.Fatalf("expected FCU at 102s, got %s", fcuAt)
+	}
+	if timestamp != 104 {
+		t.Fatalf("expected payload timestamp 104, got %d", timestamp)
+	}
+	if getPayloadAt := timing.GetPayloadAt(timestamp, fcuAt); !getPayloadAt.Equal(time.Unix(104, 0)) {
+		t.Fatalf("expected getPayload at 104s, got %s", getPayloadAt)
+	}
+}
+
+func TestBaseConsensusTimingSendsFCUImmediately(t *testing.T) {
+	timing := newTimingStrategy(ConsensusClientOptions{
+		BlockTime:           2 * time.Second,
+		ConsensusTimingMode: networktypes.ConsensusTimingModeBaseConsensus,
+	}, 0)
+	now := time.Unix(100, int64(100*time.Millisecond))
+
+	timestamp, fcuAt := timing.Schedule(100, now)
+
+	if !fcuAt.Equal(now) {
+		t.Fatalf("expected FCU now, got %s", fcuAt)
+	}
+	if timestamp != 102 {
+		t.Fatalf("expected payload timestamp 102, got %d", timestamp)
+	}
+	if getPayloadAt := timing.GetPayloadAt(timestamp, fcuAt); !getPayloadAt.Equal(time.Unix(102, 0)) {
+		t.Fatalf("expected getPayload at 102s, got %s", getPayloadAt)
+	}
+}
+
+func TestFixedWindowTimingCallsGetPayloadAfterWindow(t *testing.T) {
+	timing := newTimingStrategy(ConsensusClientOptions{
+		BlockTime:           2 * time.Second,
+		ConsensusTimingMode: networktypes.ConsensusTimingModeFixedWindow,
+		BuildWindow:         300 * time.Millisecond,
+	}, 0)
+	now := time.Unix(100, int64(500*time.Millisecond))
+
+	timestamp, fcuAt := timing.Schedule(100, now)
+
+	if !fcuAt.Equal(now) {
+		t.Fatalf("expected FCU now, got %s", fcuAt)
+	}
+	if timestamp != 102 {
+		t.Fatalf("expected payload timestamp 102, got %d", timestamp)
+	}
+	fcuSent := now.Add(10 * time.Millisecond)
+	if getPayloadAt := timing.GetPayloadAt(timestamp, fcuSent); !getPayloadAt.Equal(fcuSent.Add(300 * time.Millisecond)) {
+		t.Fatalf("expected getPayload 300ms after FCU, got %s", getPayloadAt)
+	}
+
+	// a chain that fell behind the wall clock catches up
+	if timestamp, _ := timing.Schedule(90, now); timestamp != 100 {
+		t.Fatalf("expected payload timestamp 100, got %d", timestamp)
+	}
+}
+
+func TestASAPTimingRunsAheadOfWallClock(t *testing.T) {
+	timing := newTimingStrategy(ConsensusClientOptions{
+		BlockTime:           time.Second,
+		ConsensusTimingMode: networktypes.ConsensusTimingModeASAP,
+	}, 0)
+	now := time.Unix(100, 0)
+
+	lastTimestamp := uint64(100)
+	for i := uint64(1); i <= 3; i++ {
+		timestamp, fcuAt := timing.Schedule(lastTimestamp, now)
+		if timestamp != 100+i {
+			t.Fatalf("expected payload timestamp %d, got %d", 100+i, timestamp)
+		}
+		if getPayloadAt := timing.GetPayloadAt(timestamp, fcuAt); !getPayloadAt.Equal(fcuAt) {
+			t.Fatalf("expected getPayload right after FCU, got %s", getPayloadAt)
+		}
+		lastTimestamp = timestamp
+	}
+}
+
+func TestJitteredTimingDelaysWithinJitter(t *testing.T) {
+	jitter := 100 * time.Millisecond
+	timing := newTimingStrategy(ConsensusClientOptions{
+		BlockTime:           2 * time.Second,
+		ConsensusTimingMode: networktypes.ConsensusTimingModeJittered,
+		TimingJitter:        jitter,
+	}, 1)
+	now := time.Unix(100, 0)
+
+	delayed := false
+	for i := 0; i < 20; i++ {
+		timestamp, fcuAt := timing.Schedule(100, now)
+		if fcuAt.Before(now) || !fcuAt.Before(now.Add(jitter)) {
+			t.Fatalf("expected FCU within %s of now, got %s", jitter, fcuAt)
+		}
+		if timestamp != 102 {
+			t.Fatalf("expected payload timestamp 102, got %d", timestamp)
+		}
+		deadline := time.Unix(102, 0)
+		getPayloadAt := timing.GetPayloadAt(timestamp, fcuAt)
+		if getPayloadAt.Before(deadline) || !getPayloadAt.Before(deadline.Add(jitter)) {
+			t.Fatalf("expected getPayload within %s of the deadline, got %s", jitter, getPayloadAt)
+		}
+		delayed = delayed || fcuAt.After(now)
+	}
+	if !delayed {
+		t.Fatalf("expected some FCUs to be delayed")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/base/base-bench/runner/metrics"
 	"github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/timeline"
 	"github.com/ethereum-optimism/optimism/op-service/client"
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
@@ -23,6 +24,7 @@ type SyncingConsensusClient struct {
 // NewSyncingConsensusClient creates a new consensus client.
 func NewSyncingConsensusClient(log log.Logger, client *ethclient.Client, authClient client.RPC, options ConsensusClientOptions, headBlockHash common.Hash, headBlockNumber uint64) *SyncingConsensusClient {
 	base := NewBaseConsensusClient(log, client, authClient, options, headBlockHash, headBlockNumber)
+	base.track = timeline.TrackValidator
 	return &SyncingConsensusClient{
 		BaseConsensusClient: base,
 	}
@@ -44,6 +46,9 @@ func (f *SyncingConsensusClient) propose(ctx context.Context, payload *engine.Ex
 	duration := time.Since(startTime)
 	f.log.Info("Validated payload", "payload_index", payload.Number, "duration", duration)
 	blockMetrics.AddExecutionMetric(types.NewPayloadLatencyMetric, duration)
+	if rules, err := ForkRulesAt(f.options.ChainConfig, payload.Timestamp, f.options.timestampResolution()); err == nil {
+		f.addEngineLatency(blockMetrics, types.EngineNewPayloadLatencyMetric, rules.Methods.NewPayload)
+	}
 
 	// fetch gas used from the payload
 	gasUsed := payload.GasUsed
@@ -59,6 +64,7 @@ func (f *SyncingConsensusClient) propose(ctx context.Context, payload *engine.Ex
 	}
 	duration = time.Since(startTime)
 	blockMetrics.AddExecutionMetric(types.UpdateForkChoiceLatencyMetric, duration)
+	f.addEngineLatency(blockMetrics, types.EngineUpdateForkChoiceLatencyMetric, forkchoiceOnlyMethod())
 
 	return nil
 }
@@ -68,6 +74,9 @@ func (f *SyncingConsensusClient) Start(ctx context.Context, payloads []engine.Ex
 	f.log.Info("Starting sync benchmark", "num_payloads", len(payloads))
 	m := metrics.NewBlockMetrics()
 
+	// probe before the first payload so that it is not part of its latency
+	f.exchangeCapabilities(ctx)
+
 	for i := 0; i < len(payloads); i++ {
 		m.SetBlockNumber(uint64(max(0, int(payloads[i].Number)-int(firstTestBlock)+1)))
 		f.log.Info("Proposing payload", "payload_index", i)
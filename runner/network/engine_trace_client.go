@@ -0,0 +1,93 @@
+package network
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/base/base-bench/runner/clients/common/proxy"
+	"github.com/base/base-bench/runner/clients/types"
+	"github.com/base/base-bench/runner/network/consensus"
+	"github.com/ethereum-optimism/optimism/op-service/client"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// EngineTraceFileName returns the name of the engine trace file of a role.
+func EngineTraceFileName(role string) string {
+	return fmt.Sprintf("engine-trace-%s.jsonl", role)
+}
+
+// engineTraceClient routes the engine API calls of an execution client
+// through an EngineTraceProxy and writes the recorded calls when the client is
+// stopped.
+type engineTraceClient struct {
+	types.ExecutionClient
+	log        log.Logger
+	proxy      *proxy.EngineTraceProxy
+	authClient client.RPC
+	path       string
+}
+
+// newEngineTraceClient starts a tracing proxy in front of the client's auth
+// RPC. The recorded calls are written to path when the client is stopped.
+func newEngineTraceClient(ctx context.Context, log log.Logger, c types.ExecutionClient, jwtSecretHex string, path string) (*engineTraceClient, error) {
+	jwtSecretBytes, err := hex.DecodeString(jwtSecretHex)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode jwt secret")
+	}
+	if len(jwtSecretBytes) != 32 {
+		return nil, errors.New("jwt secret must be 32 bytes")
+	}
+	var jwtSecret [32]byte
+	copy(jwtSecret[:], jwtSecretBytes)
+
+	p := proxy.NewEngineTraceProxy(log, c.AuthURL())
+	if err := p.Run(); err != nil {
+		return nil, errors.Wrap(err, "failed to start engine trace proxy")
+	}
+
+	authClient, err := client.NewRPC(ctx, log, p.URL(), client.WithGethRPCOptions(rpc.WithHTTPAuth(node.NewJWTAuth(jwtSecret))), client.WithCallTimeout(240*time.Second))
+	if err != nil {
+		p.Stop()
+		return nil, errors.Wrap(err, "failed to dial engine trace proxy")
+	}
+
+	return &engineTraceClient{
+		ExecutionClient: c,
+		log:             log,
+		proxy:           p,
+		authClient:      authClient,
+		path:            path,
+	}, nil
+}
+
+func (c *engineTraceClient) AuthClient() client.RPC {
+	return c.authClient
+}
+
+// LastDuration implements consensus.EngineTracer.
+func (c *engineTraceClient) LastDuration(method string) (time.Duration, bool) {
+	return c.proxy.LastDuration(method)
+}
+
+func (c *engineTraceClient) Stop() {
+	c.ExecutionClient.Stop()
+	c.authClient.Close()
+	c.proxy.Stop()
+	if err := c.proxy.WriteFile(c.path); err != nil {
+		c.log.Error("failed to write engine trace", "err", err)
+	}
+}
+
+// engineTracer returns the tracer of a client started with engine tracing, or
+// nil.
+func engineTracer(c types.ExecutionClient) consensus.EngineTracer {
+	if traced, ok := c.(*engineTraceClient); ok {
+		return traced
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/base/base-bench/runner/network/configutil"
 	"github.com/base/base-bench/runner/network/proofprogram"
 	"github.com/base/base-bench/runner/network/proofprogram/fakel1"
+	benchtypes "github.com/base/base-bench/runner/network/types"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 	"github.com/ethereum/go-ethereum/beacon/engine"
@@ -30,7 +31,7 @@ import (
 )
 
 type ProofProgramBenchmark interface {
-	Run(ctx context.Context, payloads []engine.ExecutableData, lastSetupBlock uint64) error
+	Run(ctx context.Context, payloads []engine.ExecutableData, lastSetupBlock uint64) (*benchtypes.ProofProgramKeyMetrics, error)
 }
 
 type opProgramBenchmark struct {
@@ -43,9 +44,9 @@ type opProgramBenchmark struct {
 	rollupCfg    *rollup.Config
 }
 
-func NewOPProgramBenchmark(genesis *core.Genesis, log log.Logger, opProgramBin string, l2RPCURL string, l1Chain fakel1.L1Chain, batcherKey *ecdsa.PrivateKey, blockTimeSec uint64) ProofProgramBenchmark {
+func NewOPProgramBenchmark(genesis *core.Genesis, log log.Logger, opProgramBin string, l2RPCURL string, l1Chain fakel1.L1Chain, batcherKey *ecdsa.PrivateKey, blockTimeSec uint64, blobsPerBlock uint64) ProofProgramBenchmark {
 	rollupCfg := configutil.GetRollupConfig(genesis, l1Chain, crypto.PubkeyToAddress(batcherKey.PublicKey), blockTimeSec)
-	batcher := proofprogram.NewBatcher(rollupCfg, batcherKey, l1Chain)
+	batcher := proofprogram.NewBatcher(rollupCfg, batcherKey, l1Chain, blobsPerBlock)
 
 	return &opProgramBenchmark{
 		l2Genesis:    genesis,
@@ -58,69 +59,82 @@ func NewOPProgramBenchmark(genesis *core.Genesis, log log.Logger, opProgramBin s
 	}
 }
 
-func (o *opProgramBenchmark) Run(ctx context.Context, payloads []engine.ExecutableData, lastSetupBlock uint64) error {
+func (o *opProgramBenchmark) Run(ctx context.Context, payloads []engine.ExecutableData, lastSetupBlock uint64) (*benchtypes.ProofProgramKeyMetrics, error) {
 	// Split payloads into setup and test groups
 	setupPayloads := make([]engine.ExecutableData, lastSetupBlock)
 	copy(setupPayloads, payloads[:lastSetupBlock+1])
 	testPayloads := payloads[lastSetupBlock+1:]
 
 	// Process batches
-	if err := o.processBatches(setupPayloads, testPayloads); err != nil {
-		return err
+	testBatch, err := o.processBatches(setupPayloads, testPayloads)
+	if err != nil {
+		return nil, err
 	}
 
 	// Start L1 proxy server
 	l1Proxy := fakel1.NewL1ProxyServer(o.log, 8099, o.chain)
 	if err := l1Proxy.Run(ctx); err != nil {
-		return fmt.Errorf("failed to start l1 proxy: %w", err)
+		return nil, fmt.Errorf("failed to start l1 proxy: %w", err)
 	}
 	defer l1Proxy.Stop()
 
 	// Connect to L2 RPC and get block information
 	ethClient, err := o.connectToL2RPC(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Prepare for op-program execution
 	l2HeadNumber := testPayloads[len(testPayloads)-1].Number
 	blockBeforeL2Head, l2OutputRoot, claimOutputRoot, err := o.prepareBlockData(ctx, ethClient, l2HeadNumber)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Write necessary files
 	if err := o.writeConfigFiles(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Execute op-program
-	return o.executeOpProgram(ctx, blockBeforeL2Head, l2HeadNumber, l2OutputRoot, claimOutputRoot)
-}
+	startTime := time.Now()
+	if err := o.executeOpProgram(ctx, blockBeforeL2Head, l2HeadNumber, l2OutputRoot, claimOutputRoot); err != nil {
+		return nil, err
+	}
+	duration := time.Since(startTime)
 
-// processBatches creates and sends both setup and test batches
-func (o *opProgramBenchmark) processBatches(setupPayloads, testPayloads []engine.ExecutableData) error {
-	// Process setup batches
-	parentHash, err := o.chain.GetLatestBlock()
-	if err != nil {
-		return fmt.Errorf("failed to get parent hash: %w", err)
+	o.log.Info("op-program finished", "duration", duration, "l1_blocks", testBatch.L1Blocks, "data_blobs", testBatch.DataBlobs, "filler_blobs", testBatch.FillerBlobs)
+
+	totalBlobs := testBatch.DataBlobs + testBatch.FillerBlobs
+	metrics := &benchtypes.ProofProgramKeyMetrics{
+		Duration:    duration.Seconds(),
+		L2Blocks:    len(testPayloads),
+		L1Blocks:    testBatch.L1Blocks,
+		DataBlobs:   testBatch.DataBlobs,
+		FillerBlobs: testBatch.FillerBlobs,
+	}
+	if totalBlobs > 0 {
+		metrics.DurationPerBlob = metrics.Duration / float64(totalBlobs)
 	}
 
-	if err := o.batcher.CreateAndSendBatch(setupPayloads, parentHash.Hash()); err != nil {
-		return fmt.Errorf("failed to create span batch for setup: %w", err)
+	return metrics, nil
+}
+
+// processBatches creates and sends both setup and test batches, returning
+// the blob summary for the test batch.
+func (o *opProgramBenchmark) processBatches(setupPayloads, testPayloads []engine.ExecutableData) (*proofprogram.BatchResult, error) {
+	// Process setup batches
+	if _, err := o.batcher.CreateAndSendBatch(setupPayloads); err != nil {
+		return nil, fmt.Errorf("failed to create span batch for setup: %w", err)
 	}
 
 	// Process test batches
-	parentHash, err = o.chain.GetLatestBlock()
+	result, err := o.batcher.CreateAndSendBatch(testPayloads)
 	if err != nil {
-		return fmt.Errorf("failed to get parent hash: %w", err)
+		return nil, fmt.Errorf("failed to create span batch for test: %w", err)
 	}
 
-	if err := o.batcher.CreateAndSendBatch(testPayloads, parentHash.Hash()); err != nil {
-		return fmt.Errorf("failed to create span batch for test: %w", err)
-	}
-
-	return nil
+	return result, nil
 }
 
 // connectToL2RPC establishes a connection to the L2 RPC endpoint
@@ -197,7 +211,8 @@ func (o *opProgramBenchmark) writeConfigFiles() error {
 
 // executeOpProgram runs the op-program binary with the necessary arguments
 func (o *opProgramBenchmark) executeOpProgram(ctx context.Context, blockBeforeL2Head *types.Header, l2HeadNumber uint64, l2OutputRoot, claimOutputRoot eth.Bytes32) error {
-	l1Head, err := o.chain.GetBlockByNumber(3)
+	// batches may span several L1 blocks, so the L1 head is the latest block
+	l1Head, err := o.chain.GetLatestBlock()
 	if err != nil {
 		return fmt.Errorf("failed to get l1 head: %w", err)
 	}
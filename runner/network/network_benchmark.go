@@ -6,14 +6,18 @@ import (
 	"math/big"
 	"os"
 	"path"
+	"time"
 
 	"github.com/base/base-bench/runner/benchmark"
 	"github.com/base/base-bench/runner/benchmark/portmanager"
 	"github.com/base/base-bench/runner/clients"
 	"github.com/base/base-bench/runner/clients/types"
 	"github.com/base/base-bench/runner/config"
+	"github.com/base/base-bench/runner/network/consensus"
 	"github.com/base/base-bench/runner/network/flashblocks"
 	"github.com/base/base-bench/runner/payload"
+	"github.com/base/base-bench/runner/soak"
+	"github.com/base/base-bench/runner/timeline"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 
@@ -22,7 +26,6 @@ import (
 
 	benchtypes "github.com/base/base-bench/runner/network/types"
 	"github.com/ethereum/go-ethereum/beacon/engine"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/pkg/errors"
 )
@@ -40,6 +43,9 @@ type NetworkBenchmark struct {
 
 	collectedSequencerMetrics *benchtypes.SequencerKeyMetrics
 	collectedValidatorMetrics *benchtypes.ValidatorKeyMetrics
+	collectedProofMetrics     *benchtypes.ProofProgramKeyMetrics
+	collectedWarmup           *benchtypes.WarmupSummary
+	collectedSoak             *soak.Report
 	// collectedClientVersion is the EL binary version captured from
 	// the sequencer client (the EL under test). Best-effort: if the
 	// version probe fails we record an empty string and the caller
@@ -100,6 +106,7 @@ func (nb *NetworkBenchmark) Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to run sequencer benchmark: %w", err)
 	}
+	nb.collectedWarmup = payloadResult.Warmup
 
 	if !nb.runsValidator() {
 		nb.log.Info("Skipping validator benchmark", "roles", nb.mode.RolesString())
@@ -116,7 +123,7 @@ func (nb *NetworkBenchmark) Run(ctx context.Context) error {
 }
 
 func (nb *NetworkBenchmark) benchmarkSequencer(ctx context.Context, l1Chain *l1Chain) (*benchtypes.PayloadResult, uint64, types.ExecutionClient, error) {
-	sequencerClient, err := setupNode(ctx, nb.log, nb.testConfig.Params.NodeType, nb.testConfig.Params, nb.sequencerOptions, nb.ports, "", nb.flashblocksBlockTime, nb.flashblocksLeewayTime)
+	sequencerClient, err := nb.startNode(ctx, timeline.TrackSequencer, nb.testConfig.Params.NodeType, nb.sequencerOptions, "")
 	if err != nil {
 		return nil, 0, nil, fmt.Errorf("failed to setup sequencer node: %w", err)
 	}
@@ -130,23 +137,41 @@ func (nb *NetworkBenchmark) benchmarkSequencer(ctx context.Context, l1Chain *l1C
 		nb.collectedClientVersion = version
 	}
 
-	// Create metrics collector and writer
-	metricsCollector := sequencerClient.MetricsCollector()
-	metricsWriter := metrics.NewFileMetricsWriter(nb.sequencerOptions.MetricsPath)
+	// Create metrics collector and stream each block to disk as it is collected
+	metricsStream, err := metrics.NewStreamingMetricsWriter(nb.sequencerOptions.MetricsPath)
+	if err != nil {
+		sequencerClient.Stop()
+		return nil, 0, nil, fmt.Errorf("failed to create sequencer metrics stream: %w", err)
+	}
+	metricsCollector := metrics.NewStreamingCollector(
+		metrics.NewObservedCollector(sequencerClient.MetricsCollector(), nb.testConfig.BlockObserver, "sequencer"),
+		metricsStream,
+	)
 
 	// Collect metrics in a deferred function to ensure they're always collected
 	defer func() {
 		sequencerMetrics := metricsCollector.GetMetrics()
 		if sequencerMetrics != nil {
 			nb.collectedSequencerMetrics = benchtypes.BlockMetricsToSequencerSummary(sequencerMetrics)
-			if err := metricsWriter.Write(sequencerMetrics); err != nil {
-				nb.log.Error("Failed to write sequencer metrics", "error", err)
-			}
+		}
+		if err := metricsStream.Finalize(); err != nil {
+			nb.log.Error("Failed to write sequencer metrics", "error", err)
 		}
 	}()
 
-	benchmark := newSequencerBenchmark(nb.log, *nb.testConfig, sequencerClient, l1Chain, nb.transactionPayload)
+	var soakSampler *soak.Sampler
+	if nb.testConfig.Soak != nil {
+		soakSampler = soak.NewSampler(nb.log, *nb.testConfig.Soak, nb.sequencerOptions.DataDirPath, soak.NewTraceStateCounter(sequencerClient.Client().Client()), soakTrendMetrics)
+	}
+
+	benchmark := newSequencerBenchmark(nb.log, *nb.testConfig, sequencerClient, l1Chain, nb.transactionPayload, soakSampler)
 	payloadResult, lastBlock, err := benchmark.Run(ctx, metricsCollector)
+	if soakSampler != nil {
+		// finished here rather than in the block loop so that failed and
+		// interrupted runs keep their samples
+		soakSampler.Finish(ctx)
+		nb.collectedSoak = soakSampler.Report()
+	}
 
 	if err != nil {
 		sequencerClient.Stop()
@@ -180,6 +205,7 @@ func (nb *NetworkBenchmark) benchmarkValidator(ctx context.Context, payloadResul
 
 		flashblockServerURL = flashblockServer.URL()
 		nb.log.Info("Started flashblock replay server", "url", flashblockServerURL, "num_flashblocks", len(payloadResult.Flashblocks))
+		nb.testConfig.Timeline.Instant(timeline.TrackFlashblocks, "flashblocks", "replay server started", "url", flashblockServerURL, "blocks", len(payloadResult.Flashblocks))
 
 		defer func() {
 			if err := flashblockServer.Stop(); err != nil {
@@ -195,7 +221,7 @@ func (nb *NetworkBenchmark) benchmarkValidator(ctx context.Context, payloadResul
 		validatorNodeType = nb.testConfig.Params.NodeType
 	}
 
-	validatorClient, err := setupNode(ctx, nb.log, validatorNodeType, nb.testConfig.Params, nb.validatorOptions, nb.ports, flashblockServerURL, nb.flashblocksBlockTime, nb.flashblocksLeewayTime)
+	validatorClient, err := nb.startNode(ctx, timeline.TrackValidator, validatorNodeType, nb.validatorOptions, flashblockServerURL)
 	if err != nil {
 		sequencerClient.Stop()
 		return fmt.Errorf("failed to setup validator node: %w", err)
@@ -222,6 +248,7 @@ func (nb *NetworkBenchmark) benchmarkValidator(ctx context.Context, payloadResul
 
 	if validatorHeader.Number.Cmp(big.NewInt(int64(lastSetupBlock)-1)) < 0 {
 		nb.log.Info("Validator is behind first test block, catching up", "validator_block", validatorHeader.Number.Uint64(), "last_setup_block", lastSetupBlock)
+		catchUp := nb.testConfig.Timeline.Begin(timeline.TrackValidator, "sync", "catch up", "from", validatorHeader.Number.Uint64()+1, "to", lastSetupBlock-1)
 		// fetch all blocks the validator node is missing
 		for i := validatorHeader.Number.Uint64() + 1; i < lastSetupBlock; i++ {
 			block, err := sequencerClient.Client().BlockByNumber(ctx, big.NewInt(int64(i)))
@@ -237,7 +264,13 @@ func (nb *NetworkBenchmark) benchmarkValidator(ctx context.Context, payloadResul
 			payload.WithdrawalsRoot = block.WithdrawalsRoot()
 			root := crypto.Keccak256Hash([]byte("fake-beacon-block-root"), big.NewInt(int64(1)).Bytes())
 
-			err = validatorClient.AuthClient().CallContext(ctx, nil, "engine_newPayloadV4", payload, []common.Hash{}, root, []common.Hash{})
+			rules, err := consensus.ForkRulesAt(nb.testConfig.Genesis.Config, payload.Timestamp, nb.testConfig.Params.PayloadTimestampResolution())
+			if err != nil {
+				validatorClient.Stop()
+				return fmt.Errorf("failed to catch up block %d: %w", i, err)
+			}
+
+			err = validatorClient.AuthClient().CallContext(ctx, nil, rules.Methods.NewPayload, rules.NewPayloadParams(payload, root)...)
 			if err != nil {
 				validatorClient.Stop()
 				return fmt.Errorf("failed to send newpayload to validator node: %w", err)
@@ -249,32 +282,70 @@ func (nb *NetworkBenchmark) benchmarkValidator(ctx context.Context, payloadResul
 				FinalizedBlockHash: payload.BlockHash,
 			}
 
-			err = validatorClient.AuthClient().CallContext(ctx, nil, "engine_forkchoiceUpdatedV3", forkchoiceUpdate, nil)
+			err = validatorClient.AuthClient().CallContext(ctx, nil, rules.Methods.ForkchoiceUpdated, forkchoiceUpdate, nil)
 			if err != nil {
 				validatorClient.Stop()
 				return fmt.Errorf("failed to send forkchoice update to validator node: %w", err)
 			}
 		}
+		catchUp.End()
 	}
 	sequencerClient.Stop()
 
-	// Create metrics collector and writer
-	metricsCollector := validatorClient.MetricsCollector()
-	metricsWriter := metrics.NewFileMetricsWriter(nb.validatorOptions.MetricsPath)
+	// Create metrics collector and stream each block to disk as it is collected
+	metricsStream, err := metrics.NewStreamingMetricsWriter(nb.validatorOptions.MetricsPath)
+	if err != nil {
+		return fmt.Errorf("failed to create validator metrics stream: %w", err)
+	}
+	metricsCollector := metrics.NewStreamingCollector(
+		metrics.NewObservedCollector(validatorClient.MetricsCollector(), nb.testConfig.BlockObserver, "validator"),
+		metricsStream,
+	)
 
 	// Collect metrics in a deferred function to ensure they're always collected
 	defer func() {
 		validatorMetrics := metricsCollector.GetMetrics()
 		if validatorMetrics != nil {
 			nb.collectedValidatorMetrics = benchtypes.BlockMetricsToValidatorSummary(validatorMetrics)
-			if err := metricsWriter.Write(validatorMetrics); err != nil {
-				nb.log.Error("Failed to write validator metrics", "error", err)
-			}
+		}
+		if err := metricsStream.Finalize(); err != nil {
+			nb.log.Error("Failed to write validator metrics", "error", err)
 		}
 	}()
 
+	// the validator replays the warm-up blocks too, but only measures the
+	// blocks after them
+	firstTestBlock := max(lastSetupBlock+1, payloadResult.FirstMeasuredBlock)
+
 	benchmark := newValidatorBenchmark(nb.log, *nb.testConfig, validatorClient, l1Chain, nb.proofConfig, flashblockServer)
-	return benchmark.Run(ctx, payloads, lastSetupBlock, metricsCollector)
+	err = benchmark.Run(ctx, payloads, lastSetupBlock, firstTestBlock, metricsCollector)
+	nb.collectedProofMetrics = benchmark.proofMetrics
+	return err
+}
+
+// startNode starts the node of a role and records its start-up on the run's
+// timeline. The returned client also records when it is stopped.
+func (nb *NetworkBenchmark) startNode(ctx context.Context, role string, nodeType string, options *config.InternalClientOptions, flashblockServerURL string) (types.ExecutionClient, error) {
+	tl := nb.testConfig.Timeline
+	span := tl.Begin(role, "node", "start node", "nodeType", nodeType)
+	client, err := setupNode(ctx, nb.log, nodeType, nb.testConfig.Params, options, nb.ports, flashblockServerURL, nb.flashblocksBlockTime, nb.flashblocksLeewayTime)
+	if err != nil {
+		span.End("err", err)
+		return nil, err
+	}
+	span.End()
+	tl.Instant(role, "node", "rpc ready", "url", client.ClientURL())
+
+	client = newTimelineClient(client, tl, role)
+	if nb.testConfig.EngineTraceDir == "" {
+		return client, nil
+	}
+	traced, err := newEngineTraceClient(ctx, nb.log, client, options.JWTSecret, path.Join(nb.testConfig.EngineTraceDir, EngineTraceFileName(role)))
+	if err != nil {
+		client.Stop()
+		return nil, err
+	}
+	return traced, nil
 }
 
 func (nb *NetworkBenchmark) GetResult() (*benchmark.RunResult, error) {
@@ -285,6 +356,7 @@ func (nb *NetworkBenchmark) GetResult() (*benchmark.RunResult, error) {
 	result := &benchmark.RunResult{
 		SequencerMetrics: nb.collectedSequencerMetrics,
 		ClientVersion:    nb.collectedClientVersion,
+		Warmup:           nb.collectedWarmup,
 		Success:          true,
 		Complete:         true,
 	}
@@ -294,6 +366,7 @@ func (nb *NetworkBenchmark) GetResult() (*benchmark.RunResult, error) {
 			return nil, errors.New("validator metrics not collected")
 		}
 		result.ValidatorMetrics = nb.collectedValidatorMetrics
+		result.ProofProgramMetrics = nb.collectedProofMetrics
 	}
 
 	artifacts := make(map[string]string)
@@ -302,14 +375,34 @@ func (nb *NetworkBenchmark) GetResult() (*benchmark.RunResult, error) {
 			artifacts[benchmark.LoadTestResultArtifactKey] = benchmark.LoadTestResultFileName
 		}
 	}
+	if nb.testConfig.EngineTraceDir != "" {
+		for role, key := range map[string]string{
+			timeline.TrackSequencer: benchmark.SequencerEngineTraceArtifactKey,
+			timeline.TrackValidator: benchmark.ValidatorEngineTraceArtifactKey,
+		} {
+			if _, err := os.Stat(path.Join(nb.testConfig.EngineTraceDir, EngineTraceFileName(role))); err == nil {
+				artifacts[key] = EngineTraceFileName(role)
+			}
+		}
+	}
 	if len(artifacts) == 0 {
 		artifacts = nil
 	}
 	result.Artifacts = artifacts
 
+	if nb.collectedSoak != nil {
+		result.Soak = nb.collectedSoak.Summary()
+	}
+
 	return result, nil
 }
 
+// SoakReport returns the samples and trends of a soak run, or nil if the run
+// was not a soak run.
+func (nb *NetworkBenchmark) SoakReport() *soak.Report {
+	return nb.collectedSoak
+}
+
 func (nb *NetworkBenchmark) runsValidator() bool {
 	return nb.mode.RunValidator
 }
@@ -335,6 +428,9 @@ func setupNode(ctx context.Context, l log.Logger, nodeTypeStr string, params ben
 
 	clientLogger := l.With("nodeType", nodeTypeStr)
 	client := clients.NewClient(nodeType, clientLogger, options, portManager)
+	if params.PayloadTimestampResolution() < time.Second && !client.SupportsMillisecondTimestamps() {
+		return nil, fmt.Errorf("node type %s only supports whole-second block timestamps", nodeTypeStr)
+	}
 
 	logPath := path.Join(options.TestDirPath, ExecutionLayerLogFileName)
 	fileWriter, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
@@ -3,6 +3,7 @@ package proofprogram
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"math/big"
@@ -17,6 +18,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-service/txmgr"
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
@@ -24,6 +26,25 @@ import (
 	"github.com/pkg/errors"
 )
 
+// maxBlobsPerTx is the maximum number of blobs the batcher packs into a single
+// blob transaction (EIP-7594 per-transaction limit).
+const maxBlobsPerTx = 6
+
+// fillerBlobVersion is the leading byte of filler blobs. It is not a valid
+// derivation version, so the derivation pipeline fetches and verifies the
+// blob but drops its contents.
+const fillerBlobVersion = 0xff
+
+// BatchResult summarizes the blobs submitted to L1 for a batch.
+type BatchResult struct {
+	// DataBlobs is the number of blobs carrying batch data.
+	DataBlobs int
+	// FillerBlobs is the number of blobs added to reach the target blobs per block.
+	FillerBlobs int
+	// L1Blocks is the number of L1 blocks mined to include the batch.
+	L1Blocks int
+}
+
 // Batcher handles the creation and submission of L2 batches to L1
 type Batcher struct {
 	rollupCfg   *rollup.Config
@@ -31,16 +52,23 @@ type Batcher struct {
 	batcherAddr common.Address
 	chain       fakel1.L1Chain
 	maxL1TxSize uint64
+
+	// blobsPerBlock is the number of blobs included in each L1 block. Batch
+	// data is spread across blocks at this rate and the last block is padded
+	// with filler blobs. Zero fills each L1 block up to the L1 maximum blob
+	// count without padding.
+	blobsPerBlock uint64
 }
 
 // NewBatcher creates a new batcher instance
-func NewBatcher(rollupCfg *rollup.Config, batcherKey *ecdsa.PrivateKey, chain fakel1.L1Chain) *Batcher {
+func NewBatcher(rollupCfg *rollup.Config, batcherKey *ecdsa.PrivateKey, chain fakel1.L1Chain, blobsPerBlock uint64) *Batcher {
 	return &Batcher{
-		rollupCfg:   rollupCfg,
-		batcherKey:  batcherKey,
-		batcherAddr: crypto.PubkeyToAddress(batcherKey.PublicKey),
-		chain:       chain,
-		maxL1TxSize: 128 * 1024,
+		rollupCfg:     rollupCfg,
+		batcherKey:    batcherKey,
+		batcherAddr:   crypto.PubkeyToAddress(batcherKey.PublicKey),
+		chain:         chain,
+		maxL1TxSize:   eth.MaxBlobDataSize,
+		blobsPerBlock: blobsPerBlock,
 	}
 }
 
@@ -122,44 +150,116 @@ func (b *Batcher) CreateSpanBatches(payloads []engine.ExecutableData) ([][]byte,
 	return frames, nil
 }
 
-// CreateAndSendBatch creates a batch and prepares it for sending to L1
-func (b *Batcher) CreateAndSendBatch(payloads []engine.ExecutableData, parentHash common.Hash) error {
+// CreateAndSendBatch creates a batch and sends it to L1 as blob transactions.
+func (b *Batcher) CreateAndSendBatch(payloads []engine.ExecutableData) (*BatchResult, error) {
 	frames, err := b.CreateSpanBatches(payloads)
 	if err != nil {
-		return err
-	}
-
-	txs := make([]*types.Transaction, 0)
-
-	nonce, err := b.chain.GetNonce(b.batcherAddr)
-	if err != nil {
-		return fmt.Errorf("failed to get nonce: %w", err)
+		return nil, err
 	}
 
+	blobs := make([]*eth.Blob, 0, len(frames))
 	for _, frame := range frames {
 		var blob eth.Blob
 		if err := blob.FromData(frame); err != nil {
-			return fmt.Errorf("failed to create blob: %w", err)
+			return nil, fmt.Errorf("failed to create blob: %w", err)
 		}
+		blobs = append(blobs, &blob)
+	}
 
-		sidecar, blobHashes, err := txmgr.MakeSidecar([]*eth.Blob{&blob}, false)
-		if err != nil {
-			return fmt.Errorf("failed to create sidecar: %w", err)
-		}
+	result := &BatchResult{
+		DataBlobs: len(blobs),
+	}
 
-		pendingHeader, err := b.chain.GetBlockByHash(parentHash)
-		if err != nil {
-			return fmt.Errorf("failed to get pending header: %w", err)
+	latest, err := b.chain.GetLatestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest l1 block: %w", err)
+	}
+	maxBlobs := uint64(eip4844.MaxBlobsPerBlock(b.chain.Genesis().Config, latest.Time+1))
+
+	blocks, fillerBlobs, err := splitBlobs(blobs, b.blobsPerBlock, maxBlobs)
+	if err != nil {
+		return nil, err
+	}
+	result.FillerBlobs = fillerBlobs
+
+	for _, blockBlobs := range blocks {
+		if err := b.sendBlobs(blockBlobs); err != nil {
+			return nil, err
 		}
+		result.L1Blocks++
+	}
+
+	return result, nil
+}
+
+// splitBlobs groups the blobs of a batch into the blobs of each L1 block. With
+// a positive blobsPerBlock, every block holds blobsPerBlock blobs and the last
+// one is padded with filler blobs. Zero fills each block up to maxBlobs
+// without padding. At least one block is returned, so an empty batch still
+// mines an L1 block. It also returns the number of filler blobs added.
+func splitBlobs(blobs []*eth.Blob, blobsPerBlock uint64, maxBlobs uint64) ([][]*eth.Blob, int, error) {
+	if blobsPerBlock > maxBlobs {
+		return nil, 0, fmt.Errorf("blobs per block %d exceeds the L1 maximum of %d", blobsPerBlock, maxBlobs)
+	}
+	perBlock := blobsPerBlock
+	if perBlock == 0 {
+		perBlock = maxBlobs
+	}
 
-		if pendingHeader.ExcessBlobGas() == nil {
-			return fmt.Errorf("pending header does not have excess blob gas")
+	var blocks [][]*eth.Blob
+	fillerBlobs := 0
+	for len(blobs) > 0 || len(blocks) == 0 {
+		n := min(uint64(len(blobs)), perBlock)
+		blockBlobs := append([]*eth.Blob(nil), blobs[:n]...)
+		blobs = blobs[n:]
+
+		for uint64(len(blockBlobs)) < blobsPerBlock {
+			filler, err := makeFillerBlob()
+			if err != nil {
+				return nil, 0, err
+			}
+			blockBlobs = append(blockBlobs, filler)
+			fillerBlobs++
 		}
+		blocks = append(blocks, blockBlobs)
+	}
+	return blocks, fillerBlobs, nil
+}
+
+// sendBlobs mines a single L1 block containing the given blobs, packed into
+// as few blob transactions as possible.
+func (b *Batcher) sendBlobs(blobs []*eth.Blob) error {
+	nonce, err := b.chain.GetNonce(b.batcherAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	pendingHeader, err := b.chain.GetLatestBlock()
+	if err != nil {
+		return fmt.Errorf("failed to get pending header: %w", err)
+	}
 
-		blobBaseFee := eth.CalcBlobFeeCancun(*pendingHeader.Header().ExcessBlobGas)
-		blobFeeCap := new(uint256.Int).Mul(uint256.NewInt(2), uint256.MustFromBig(blobBaseFee))
-		if blobFeeCap.Lt(uint256.NewInt(params.GWei)) { // ensure we meet 1 gwei geth tx-pool minimum
-			blobFeeCap = uint256.NewInt(params.GWei)
+	if pendingHeader.ExcessBlobGas == nil {
+		return fmt.Errorf("pending header does not have excess blob gas")
+	}
+
+	blobBaseFee := eth.CalcBlobFeeCancun(*pendingHeader.ExcessBlobGas)
+	blobFeeCap := new(uint256.Int).Mul(uint256.NewInt(2), uint256.MustFromBig(blobBaseFee))
+	if blobFeeCap.Lt(uint256.NewInt(params.GWei)) { // ensure we meet 1 gwei geth tx-pool minimum
+		blobFeeCap = uint256.NewInt(params.GWei)
+	}
+
+	signer := types.NewPragueSigner(b.rollupCfg.L1ChainID)
+	txs := make([]*types.Transaction, 0)
+
+	for len(blobs) > 0 {
+		n := min(len(blobs), maxBlobsPerTx)
+		txBlobs := blobs[:n]
+		blobs = blobs[n:]
+
+		sidecar, blobHashes, err := txmgr.MakeSidecar(txBlobs, false)
+		if err != nil {
+			return fmt.Errorf("failed to create sidecar: %w", err)
 		}
 
 		txData := &types.BlobTx{
@@ -178,8 +278,6 @@ func (b *Batcher) CreateAndSendBatch(payloads []engine.ExecutableData, parentHas
 
 		nonce++
 
-		signer := types.NewPragueSigner(b.rollupCfg.L1ChainID)
-
 		// sign with batcher key
 		tx, err := types.SignNewTx(b.batcherKey, signer, txData)
 		if err != nil {
@@ -189,10 +287,25 @@ func (b *Batcher) CreateAndSendBatch(payloads []engine.ExecutableData, parentHas
 		txs = append(txs, tx)
 	}
 
-	err = b.chain.BuildAndMine(txs)
-	if err != nil {
+	if err := b.chain.BuildAndMine(txs); err != nil {
 		return fmt.Errorf("failed to build and mine txs: %w", err)
 	}
 
 	return nil
 }
+
+// makeFillerBlob returns a blob of incompressible data that the derivation
+// pipeline will fetch but ignore.
+func makeFillerBlob() (*eth.Blob, error) {
+	data := make([]byte, eth.MaxBlobDataSize)
+	if _, err := rand.Read(data); err != nil {
+		return nil, fmt.Errorf("failed to generate filler blob data: %w", err)
+	}
+	data[0] = fillerBlobVersion
+
+	var blob eth.Blob
+	if err := blob.FromData(data); err != nil {
+		return nil, fmt.Errorf("failed to create filler blob: %w", err)
+	}
+	return &blob, nil
+}
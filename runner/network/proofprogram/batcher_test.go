@@ -0,0 +1,103 @@
+package proofprogram
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+func makeTestBlobs(n int) []*eth.Blob {
+	blobs := make([]*eth.Blob, n)
+	for i := range blobs {
+		blobs[i] = new(eth.Blob)
+	}
+	return blobs
+}
+
+func blockSizes(blocks [][]*eth.Blob) []int {
+	sizes := make([]int, len(blocks))
+	for i, block := range blocks {
+		sizes[i] = len(block)
+	}
+	return sizes
+}
+
+func TestSplitBlobsDefaultFillsBlocksUpToMaximum(t *testing.T) {
+	blobs := makeTestBlobs(14)
+
+	blocks, fillers, err := splitBlobs(blobs, 0, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{6, 6, 2}; !slices.Equal(blockSizes(blocks), want) {
+		t.Fatalf("block sizes = %v, want %v", blockSizes(blocks), want)
+	}
+	if fillers != 0 {
+		t.Fatalf("fillers = %d, want 0", fillers)
+	}
+	if blocks[0][0] != blobs[0] || blocks[2][1] != blobs[13] {
+		t.Fatal("blobs were not kept in order")
+	}
+}
+
+func TestSplitBlobsDefaultKeepsSmallBatchInOneBlock(t *testing.T) {
+	blocks, fillers, err := splitBlobs(makeTestBlobs(3), 0, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{3}; !slices.Equal(blockSizes(blocks), want) {
+		t.Fatalf("block sizes = %v, want %v", blockSizes(blocks), want)
+	}
+	if fillers != 0 {
+		t.Fatalf("fillers = %d, want 0", fillers)
+	}
+}
+
+func TestSplitBlobsPadsLastBlockWithFillers(t *testing.T) {
+	blobs := makeTestBlobs(5)
+
+	blocks, fillers, err := splitBlobs(blobs, 2, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{2, 2, 2}; !slices.Equal(blockSizes(blocks), want) {
+		t.Fatalf("block sizes = %v, want %v", blockSizes(blocks), want)
+	}
+	if fillers != 1 {
+		t.Fatalf("fillers = %d, want 1", fillers)
+	}
+	if blocks[2][0] != blobs[4] || blocks[2][1] == nil {
+		t.Fatal("last block was not padded with a filler blob")
+	}
+}
+
+func TestSplitBlobsEmptyBatchStillMinesBlock(t *testing.T) {
+	blocks, fillers, err := splitBlobs(nil, 0, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{0}; !slices.Equal(blockSizes(blocks), want) {
+		t.Fatalf("block sizes = %v, want %v", blockSizes(blocks), want)
+	}
+	if fillers != 0 {
+		t.Fatalf("fillers = %d, want 0", fillers)
+	}
+
+	blocks, fillers, err = splitBlobs(nil, 3, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{3}; !slices.Equal(blockSizes(blocks), want) {
+		t.Fatalf("block sizes = %v, want %v", blockSizes(blocks), want)
+	}
+	if fillers != 3 {
+		t.Fatalf("fillers = %d, want 3", fillers)
+	}
+}
+
+func TestSplitBlobsRejectsExplicitCountAboveMaximum(t *testing.T) {
+	if _, _, err := splitBlobs(makeTestBlobs(3), 7, 6); err == nil {
+		t.Fatal("expected an error for blobs per block above the L1 maximum")
+	}
+}
@@ -16,6 +16,8 @@ import (
 	benchtypes "github.com/base/base-bench/runner/network/types"
 	"github.com/base/base-bench/runner/payload"
 	payloadworker "github.com/base/base-bench/runner/payload/worker"
+	"github.com/base/base-bench/runner/soak"
+	"github.com/base/base-bench/runner/timeline"
 	"github.com/ethereum-optimism/optimism/op-service/retry"
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
@@ -27,20 +29,48 @@ import (
 
 const gracefulWorkerShutdownTimeout = 90 * time.Second
 
+// benchmarkRunController decides when the benchmark blocks of a run end and
+// whether a block is part of the warm-up, whose metrics are not collected.
 type benchmarkRunController struct {
+	log        log.Logger
 	maxBlocks  int
+	duration   time.Duration
 	completion payloadworker.CompletionWorker
+
+	warmupBlocks   int
+	warmupDuration time.Duration
+	steadyState    *metrics.SteadyStateDetector
+
+	warmingUp      bool
+	steady         bool
+	warmupStart    time.Time
+	measureStart   time.Time
+	warmedUpBlocks int
+	measuredBlocks int
 }
 
-func newBenchmarkRunController(transactionWorker payloadworker.Worker, params benchtypes.RunParams) benchmarkRunController {
-	completion, ok := transactionWorker.(payloadworker.CompletionWorker)
-	if ok {
-		return benchmarkRunController{completion: completion}
+func newBenchmarkRunController(log log.Logger, transactionWorker payloadworker.Worker, params benchtypes.RunParams) *benchmarkRunController {
+	now := time.Now()
+	c := &benchmarkRunController{
+		log:            log,
+		maxBlocks:      params.NumBlocks,
+		duration:       params.Duration,
+		warmupBlocks:   params.WarmupBlocks,
+		warmupDuration: params.WarmupDuration,
+		warmingUp:      params.HasWarmup(),
+		warmupStart:    now,
+		measureStart:   now,
+	}
+	if completion, ok := transactionWorker.(payloadworker.CompletionWorker); ok {
+		c.completion = completion
+	}
+	if params.HasSteadyStateDetection() {
+		c.steadyState = metrics.NewSteadyStateDetector(params.SteadyStateWindow, params.SteadyStateTolerance)
 	}
-	return benchmarkRunController{maxBlocks: params.NumBlocks}
+	return c
 }
 
-func (c benchmarkRunController) shouldStop(nextBlockIndex uint64) (bool, error) {
+func (c *benchmarkRunController) shouldStop() (bool, error) {
 	if c.completion != nil {
 		select {
 		case <-c.completion.Done():
@@ -49,28 +79,95 @@ func (c benchmarkRunController) shouldStop(nextBlockIndex uint64) (bool, error)
 			return false, nil
 		}
 	}
-	return int(nextBlockIndex) > c.maxBlocks, nil
+	if c.warmingUp {
+		return false, nil
+	}
+	if c.duration > 0 {
+		return time.Since(c.measureStart) >= c.duration, nil
+	}
+	return c.measuredBlocks >= c.maxBlocks, nil
 }
 
-func (c benchmarkRunController) usesWorkerCompletion() bool {
+func (c *benchmarkRunController) usesWorkerCompletion() bool {
 	return c.completion != nil
 }
 
+// nextBlockIndex returns the index of the next block within its phase,
+// starting at one.
+func (c *benchmarkRunController) nextBlockIndex() uint64 {
+	if c.warmingUp {
+		return uint64(c.warmedUpBlocks + 1)
+	}
+	return uint64(c.measuredBlocks + 1)
+}
+
+// recordBlock counts a proposed block and ends the warm-up once it is long
+// enough or, with steady-state detection, once the sequencer gas/s is steady.
+// It returns true if the warm-up ended with this block.
+func (c *benchmarkRunController) recordBlock(blockMetrics *metrics.BlockMetrics) bool {
+	if !c.warmingUp {
+		c.measuredBlocks++
+		return false
+	}
+
+	c.warmedUpBlocks++
+	if c.steadyState != nil {
+		if gasPerSecond, ok := blockMetrics.GetMetricFloat(benchtypes.GasPerSecondMetric); ok {
+			c.steady = c.steadyState.Add(gasPerSecond)
+		}
+	}
+
+	boundReached := c.warmedUpBlocks >= c.warmupBlocks && time.Since(c.warmupStart) >= c.warmupDuration
+	if !boundReached && !c.steady {
+		return false
+	}
+	if c.steadyState != nil && !c.steady {
+		c.log.Warn("Sequencer gas/s did not reach a steady state during the warm-up", "blocks", c.warmedUpBlocks)
+	}
+	c.warmingUp = false
+	c.measureStart = time.Now()
+	return true
+}
+
+// warmupSummary describes the warm-up, or returns nil if the run had none.
+func (c *benchmarkRunController) warmupSummary() *benchtypes.WarmupSummary {
+	if c.warmupBlocks == 0 && c.warmupDuration == 0 {
+		return nil
+	}
+	return &benchtypes.WarmupSummary{
+		Blocks:      c.warmedUpBlocks,
+		Duration:    c.measureStart.Sub(c.warmupStart).Seconds(),
+		SteadyState: c.steady,
+	}
+}
+
+// soakTrendMetrics are the sequencer metrics whose trend a soak run reports.
+var soakTrendMetrics = []string{
+	benchtypes.UpdateForkChoiceLatencyMetric,
+	benchtypes.GetPayloadLatencyMetric,
+	benchtypes.SendTxsLatencyMetric,
+	benchtypes.GasPerSecondMetric,
+}
+
 type sequencerBenchmark struct {
 	log                log.Logger
 	sequencerClient    types.ExecutionClient
 	config             benchtypes.TestConfig
 	l1Chain            *l1Chain
 	transactionPayload payload.Definition
+
+	// soakSampler, if set, samples the state growth over the measured blocks.
+	soakSampler *soak.Sampler
 }
 
-func newSequencerBenchmark(log log.Logger, config benchtypes.TestConfig, sequencerClient types.ExecutionClient, l1Chain *l1Chain, transactionPayload payload.Definition) *sequencerBenchmark {
+func newSequencerBenchmark(log log.Logger, config benchtypes.TestConfig, sequencerClient types.ExecutionClient, l1Chain *l1Chain, transactionPayload payload.Definition, soakSampler *soak.Sampler) *sequencerBenchmark {
 	return &sequencerBenchmark{
 		log:                log,
 		config:             config,
 		sequencerClient:    sequencerClient,
 		l1Chain:            l1Chain,
 		transactionPayload: transactionPayload,
+		soakSampler:        soakSampler,
 	}
 }
 
@@ -134,6 +231,7 @@ func (nb *sequencerBenchmark) fundTestAccount(ctx context.Context, mempool mempo
 		return fmt.Errorf("failed to get transaction receipt: %w", err)
 	}
 	nb.log.Info("Included deposit tx in block", "block", receipt.BlockNumber)
+	nb.config.Timeline.Instant(timeline.TrackSequencer, "setup", "funding tx included", "block", receipt.BlockNumber.Uint64(), "tx", txHash.Hex())
 	if err != nil {
 		return fmt.Errorf("failed to get transaction receipt: %w", err)
 	}
@@ -176,7 +274,7 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 	defer benchmarkCancel()
 
 	errChan := make(chan error)
-	payloadResult := make(chan []engine.ExecutableData)
+	payloadResults := make(chan *benchtypes.PayloadResult)
 
 	setupComplete := make(chan struct{})
 	chainReady := make(chan struct{})
@@ -186,7 +284,7 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 	flashblocksClient := sequencerClient.FlashblocksClient()
 	if flashblocksClient != nil {
 		nb.log.Info("Starting flashblocks collection")
-		flashblockCollector = newFlashblockCollector(nb.log)
+		flashblockCollector = newFlashblockCollector(nb.log, nb.config.Timeline)
 		flashblocksClient.AddListener(flashblockCollector)
 
 		if err := flashblocksClient.Start(benchmarkCtx); err != nil {
@@ -205,19 +303,24 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 		// allow one block to pass before sending txs to set the gas limit
 		<-chainReady
 
+		fundSpan := nb.config.Timeline.Begin(timeline.TrackSequencer, "setup", "fund test account")
 		err := nb.fundTestAccount(benchmarkCtx, mempool)
+		fundSpan.End("err", err)
 		if err != nil {
 			nb.log.Warn("failed to fund test account", "err", err)
 			errChan <- err
 			return
 		}
 
+		setupSpan := nb.config.Timeline.Begin(timeline.TrackSequencer, "setup", "worker setup", "payload", nb.transactionPayload.Type)
 		err = transactionWorker.Setup(benchmarkCtx)
+		setupSpan.End("err", err)
 		if err != nil {
 			nb.log.Warn("failed to setup payload worker", "err", err)
 			errChan <- err
 			return
 		}
+		nb.config.Timeline.Instant(timeline.TrackSequencer, "setup", "worker setup done")
 		close(setupComplete)
 	}()
 
@@ -237,10 +340,19 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 	go func() {
 		consensusClient := consensus.NewSequencerConsensusClient(nb.log, sequencerClient.Client(), sequencerClient.AuthClient(), mempool, consensus.ConsensusClientOptions{
 			BlockTime:           params.BlockTime,
+			TimestampResolution: params.PayloadTimestampResolution(),
 			GasLimit:            params.GasLimit,
 			GasLimitSetup:       1e9, // 1G gas
 			ParallelTxBatches:   nb.config.Config.ParallelTxBatches(),
+			L1OriginInterval:    params.L1OriginInterval,
+			Deposits:            nb.transactionPayload.Deposits,
+			FeeParams:           params.FeeParams,
+			EngineTracer:        engineTracer(sequencerClient),
 			ConsensusTimingMode: params.ConsensusTimingMode,
+			BuildWindow:         params.BuildWindow,
+			TimingJitter:        params.TimingJitter,
+			Timeline:            nb.config.Timeline,
+			ChainConfig:         nb.config.Genesis.Config,
 		}, headBlockHash, headBlockNumber, l1Chain, nb.config.BatcherAddr())
 
 		payloads := make([]engine.ExecutableData, 0)
@@ -249,7 +361,9 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 	setupLoop:
 		for {
 			_blockMetrics := metrics.NewBlockMetrics()
+			blockSpan := nb.config.Timeline.Begin(timeline.TrackSequencer, "block", "setup block")
 			setupPayload, err := consensusClient.Propose(benchmarkCtx, _blockMetrics, true)
+			blockSpan.End(blockSpanArgs(setupPayload, err)...)
 			if err != nil {
 				errChan <- err
 				return
@@ -270,34 +384,42 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 		payloads = append(payloads, *lastSetupPayload)
 
 		pendingTxs := 0
-		runController := newBenchmarkRunController(transactionWorker, params)
+		runController := newBenchmarkRunController(nb.log, transactionWorker, params)
 		if runController.usesWorkerCompletion() {
 			nb.log.Info("Running benchmark blocks until payload worker completes")
 		}
+		if runController.warmingUp {
+			nb.log.Info("Warming up", "blocks", params.WarmupBlocks, "duration", params.WarmupDuration, "steady_state_window", params.SteadyStateWindow)
+			nb.config.Timeline.Instant(timeline.TrackSequencer, "block", "warm-up started")
+		}
 
-		blockIndex := uint64(1)
+		var firstMeasuredBlock uint64
 		for {
-			stop, err := runController.shouldStop(blockIndex)
+			stop, err := runController.shouldStop()
 			if err != nil {
 				errChan <- errors.Wrap(err, "payload worker failed")
 				return
 			}
 			if stop {
 				if runController.usesWorkerCompletion() {
-					nb.log.Info("Payload worker completed", "blocks", blockIndex-1)
+					nb.log.Info("Payload worker completed", "blocks", runController.measuredBlocks)
 				}
 				break
 			}
 
-			payload, updatedPendingTxs, err := nb.proposeBlock(
+			kind := blockKindMeasured
+			if runController.warmingUp {
+				kind = blockKindWarmup
+			}
+			payload, blockMetrics, updatedPendingTxs, err := nb.proposeBlock(
 				benchmarkCtx,
 				transactionWorker,
 				consensusClient,
 				metricsCollector,
-				blockIndex,
+				runController.nextBlockIndex(),
 				pendingTxs,
 				false,
-				true,
+				kind,
 			)
 			if err != nil {
 				errChan <- err
@@ -305,7 +427,18 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 			}
 			pendingTxs = updatedPendingTxs
 			payloads = append(payloads, *payload)
-			blockIndex++
+
+			if kind == blockKindMeasured && firstMeasuredBlock == 0 && params.HasWarmup() {
+				firstMeasuredBlock = payload.Number
+			}
+			if kind == blockKindMeasured && nb.soakSampler != nil {
+				nb.soakSampler.RecordBlock(payload.Number, blockMetrics)
+			}
+			if runController.recordBlock(blockMetrics) {
+				warmup := runController.warmupSummary()
+				nb.log.Info("Warm-up finished", "blocks", warmup.Blocks, "duration", warmup.Duration, "steady_state", warmup.SteadyState)
+				nb.config.Timeline.Instant(timeline.TrackSequencer, "block", "warm-up finished", "blocks", warmup.Blocks, "steadyState", warmup.SteadyState)
+			}
 		}
 
 		if !runController.usesWorkerCompletion() {
@@ -319,25 +452,48 @@ func (nb *sequencerBenchmark) Run(ctx context.Context, metricsCollector metrics.
 			nb.log.Warn("failed to stop consensus client", "err", err)
 		}
 
-		payloadResult <- payloads
+		payloadResults <- &benchtypes.PayloadResult{
+			ExecutablePayloads: payloads,
+			FirstMeasuredBlock: firstMeasuredBlock,
+			Warmup:             runController.warmupSummary(),
+		}
 	}()
 
 	select {
 	case err := <-errChan:
 		return nil, 0, err
-	case payloads := <-payloadResult:
+	case result := <-payloadResults:
 		// Collect flashblocks if available
-		var flashblocks map[uint64][]types.FlashblocksPayloadV1
 		if flashblockCollector != nil {
-			flashblocks = flashblockCollector.GetFlashblocks()
-			nb.log.Info("Collected flashblocks", "count", len(flashblocks))
+			result.Flashblocks = flashblockCollector.GetFlashblocks()
+			nb.log.Info("Collected flashblocks", "count", len(result.Flashblocks))
 		}
 
-		result := &benchtypes.PayloadResult{
-			ExecutablePayloads: payloads,
-			Flashblocks:        flashblocks,
-		}
-		return result, payloads[0].Number, nil
+		return result, result.ExecutablePayloads[0].Number, nil
+	}
+}
+
+// blockKind is the phase of the run a benchmark block is proposed in.
+type blockKind int
+
+const (
+	// blockKindMeasured is a block whose metrics are collected.
+	blockKindMeasured blockKind = iota
+	// blockKindWarmup is built before the measured blocks to warm up the client.
+	blockKindWarmup
+	// blockKindSettlement is built after the measured blocks while the payload
+	// worker shuts down.
+	blockKindSettlement
+)
+
+func (k blockKind) spanName() string {
+	switch k {
+	case blockKindWarmup:
+		return "warm-up block"
+	case blockKindSettlement:
+		return "settlement block"
+	default:
+		return "block"
 	}
 }
 
@@ -349,23 +505,30 @@ func (nb *sequencerBenchmark) proposeBlock(
 	blockIndex uint64,
 	pendingTxs int,
 	isSetupPayload bool,
-	collectMetrics bool,
-) (*engine.ExecutableData, int, error) {
+	kind blockKind,
+) (*engine.ExecutableData, *metrics.BlockMetrics, int, error) {
 	blockMetrics := metrics.NewBlockMetrics()
 	blockMetrics.SetBlockNumber(blockIndex)
 
+	blockSpan := nb.config.Timeline.Begin(timeline.TrackSequencer, "block", kind.spanName(), "index", blockIndex)
+
 	txsSent, err := transactionWorker.SendTxs(ctx, pendingTxs)
 	if err != nil {
 		nb.log.Warn("failed to send transactions", "err", err)
-		return nil, pendingTxs, err
+		blockSpan.End("err", err)
+		return nil, nil, pendingTxs, err
 	}
 
 	payload, err := consensusClient.Propose(ctx, blockMetrics, isSetupPayload)
+	blockSpan.End(blockSpanArgs(payload, err)...)
 	if err != nil {
-		return nil, pendingTxs, err
+		return nil, nil, pendingTxs, err
 	}
 	if payload == nil {
-		return nil, pendingTxs, errors.New("received nil payload from consensus client")
+		return nil, nil, pendingTxs, errors.New("received nil payload from consensus client")
+	}
+	if nb.transactionPayload.HasFixedFeeCap() && payload.BaseFeePerGas != nil && payload.BaseFeePerGas.Cmp(new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap)) >= 0 {
+		return nil, nil, pendingTxs, fmt.Errorf("base fee %s reached the payload workers' fee cap of %d wei, so their transactions cannot be included", payload.BaseFeePerGas, benchtypes.PayloadGasFeeCap)
 	}
 
 	// Track how many user txs are still pending in the node's mempool.
@@ -378,19 +541,20 @@ func (nb *sequencerBenchmark) proposeBlock(
 	if updatedPendingTxs < 0 {
 		updatedPendingTxs = 0
 	}
+	blockMetrics.AddExecutionMetric(benchtypes.PendingTransactionsMetric, float64(updatedPendingTxs))
 
-	if !nb.config.Params.UseBaseConsensusTiming() {
+	if nb.config.Params.UsePreventLateFCUTiming() {
 		log.Info("Sleeping for block time", "block_time", nb.config.Params.BlockTime)
 		time.Sleep(nb.config.Params.BlockTime)
 	}
 
-	if collectMetrics {
+	if kind == blockKindMeasured {
 		if err := metricsCollector.Collect(ctx, blockMetrics); err != nil {
 			nb.log.Error("Failed to collect metrics", "error", err)
 		}
 	}
 
-	return payload, updatedPendingTxs, nil
+	return payload, blockMetrics, updatedPendingTxs, nil
 }
 
 func (nb *sequencerBenchmark) settleGracefulWorkerShutdown(
@@ -425,7 +589,7 @@ func (nb *sequencerBenchmark) settleGracefulWorkerShutdown(
 
 		var payload *engine.ExecutableData
 		var err error
-		payload, pendingTxs, err = nb.proposeBlock(ctx, transactionWorker, consensusClient, nil, uint64(settlementBlock+1), pendingTxs, true, false)
+		payload, _, pendingTxs, err = nb.proposeBlock(ctx, transactionWorker, consensusClient, nil, uint64(settlementBlock+1), pendingTxs, true, blockKindSettlement)
 		if err != nil {
 			return errors.Wrap(err, "failed to propose settlement block")
 		}
@@ -437,19 +601,33 @@ func (nb *sequencerBenchmark) settleGracefulWorkerShutdown(
 	}
 }
 
+// blockSpanArgs returns the timeline args describing a proposed block.
+func blockSpanArgs(payload *engine.ExecutableData, err error) []interface{} {
+	if err != nil {
+		return []interface{}{"err", err}
+	}
+	if payload == nil {
+		return nil
+	}
+	return []interface{}{"number", payload.Number, "txs", len(payload.Transactions), "gasUsed", payload.GasUsed}
+}
+
 // flashblockCollector implements FlashblockListener to collect flashblocks.
 type flashblockCollector struct {
 	log              log.Logger
+	timeline         *timeline.Timeline
 	flashblocks      map[uint64][]types.FlashblocksPayloadV1
 	currentBaseBlock *uint64
 	mu               sync.Mutex
 }
 
-// newFlashblockCollector creates a new flashblock collector.
-func newFlashblockCollector(log log.Logger) *flashblockCollector {
+// newFlashblockCollector creates a new flashblock collector. Received
+// flashblocks are recorded on tl if it is set.
+func newFlashblockCollector(log log.Logger, tl *timeline.Timeline) *flashblockCollector {
 	return &flashblockCollector{
 		flashblocks: make(map[uint64][]types.FlashblocksPayloadV1),
 		log:         log,
+		timeline:    tl,
 	}
 }
 
@@ -465,6 +643,7 @@ func (c *flashblockCollector) OnFlashblock(flashblock types.FlashblocksPayloadV1
 		return
 	}
 	c.log.Info("Collected flashblock", "block_number", *c.currentBaseBlock, "index", flashblock.Index, "tx_count", len(flashblock.Diff.Transactions))
+	c.timeline.Instant(timeline.TrackFlashblocks, "flashblocks", "flashblock received", "block", *c.currentBaseBlock, "index", flashblock.Index, "txs", len(flashblock.Diff.Transactions))
 	c.flashblocks[*c.currentBaseBlock] = append(c.flashblocks[*c.currentBaseBlock], flashblock)
 }
 
@@ -0,0 +1,32 @@
+package network
+
+import (
+	"github.com/base/base-bench/runner/clients/types"
+	"github.com/base/base-bench/runner/timeline"
+)
+
+// timelineClient records when an execution client is stopped.
+type timelineClient struct {
+	types.ExecutionClient
+	timeline *timeline.Timeline
+	track    string
+}
+
+// newTimelineClient wraps client so that stopping it is recorded on the
+// timeline. The client is returned as is if there is no timeline.
+func newTimelineClient(client types.ExecutionClient, tl *timeline.Timeline, track string) types.ExecutionClient {
+	if tl == nil {
+		return client
+	}
+	return &timelineClient{
+		ExecutionClient: client,
+		timeline:        tl,
+		track:           track,
+	}
+}
+
+func (c *timelineClient) Stop() {
+	span := c.timeline.Begin(c.track, "node", "stop node")
+	c.ExecutionClient.Stop()
+	span.End()
+}
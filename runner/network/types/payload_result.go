@@ -13,6 +13,13 @@ type PayloadResult struct {
 
 	// Flashblocks are the flashblock payloads collected during the benchmark (if available)
 	Flashblocks map[uint64][]clientTypes.FlashblocksPayloadV1
+
+	// FirstMeasuredBlock is the number of the first block after the warm-up,
+	// zero if the run had no warm-up
+	FirstMeasuredBlock uint64
+
+	// Warmup describes the warm-up phase, if the run had one
+	Warmup *WarmupSummary
 }
 
 // HasFlashblocks returns true if flashblock payloads were collected.
@@ -2,12 +2,18 @@ package types
 
 import (
 	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/base/base-bench/runner/config"
 	"github.com/base/base-bench/runner/metrics"
+	"github.com/base/base-bench/runner/soak"
+	"github.com/base/base-bench/runner/timeline"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
@@ -44,6 +50,20 @@ type TestConfig struct {
 	// LoadTestOutputPath is the optional normal load-test report JSON path used
 	// by the load-test payload worker.
 	LoadTestOutputPath string
+
+	// BlockObserver, if set, is notified of every collected block's metrics.
+	BlockObserver metrics.BlockObserver
+
+	// Timeline, if set, records the run's lifecycle events.
+	Timeline *timeline.Timeline
+
+	// EngineTraceDir, if set, routes the engine API calls of each node through
+	// a tracing proxy and writes them to this directory.
+	EngineTraceDir string
+
+	// Soak, if set, samples the state growth of the sequencer while it builds
+	// the measured blocks.
+	Soak *soak.Config
 }
 
 // BatcherAddr returns the batcher address, computing it if necessary
@@ -81,15 +101,46 @@ type RunParams struct {
 	// BlockTime is the time between blocks in the benchmark run.
 	BlockTime time.Duration
 
+	// TimestampResolution is the unit of the payload timestamps, either a
+	// second or a millisecond for chains with sub-second block times. Zero
+	// means whole seconds.
+	TimestampResolution time.Duration
+
 	// ConsensusTimingMode controls how the fake consensus client schedules FCU/getPayload calls.
 	ConsensusTimingMode string
 
+	// BuildWindow is the time between the FCU and getPayload calls of the
+	// fixed-window consensus timing mode.
+	BuildWindow time.Duration
+
+	// TimingJitter is the maximum random delay of the FCU and getPayload calls
+	// of the jittered consensus timing mode.
+	TimingJitter time.Duration
+
 	// Env is the environment variables for the benchmark run.
 	Env map[string]string
 
 	// NumBlocks is the number of blocks to run in the benchmark run.
 	NumBlocks int
 
+	// Duration is how long the benchmark blocks run after the warm-up. If set,
+	// it replaces NumBlocks.
+	Duration time.Duration
+
+	// WarmupBlocks and WarmupDuration are the length of the warm-up phase
+	// before the benchmark blocks, whose blocks are excluded from the metrics.
+	// The warm-up lasts for both. With steady-state detection, they are the
+	// maximum length of the warm-up instead.
+	WarmupBlocks   int
+	WarmupDuration time.Duration
+
+	// SteadyStateWindow and SteadyStateTolerance end the warm-up once the
+	// coefficient of variation of the sequencer gas/s over the last
+	// SteadyStateWindow blocks is at most SteadyStateTolerance. Zero disables
+	// steady-state detection.
+	SteadyStateWindow    int
+	SteadyStateTolerance float64
+
 	// Tags are the tags for the benchmark run.
 	Tags map[string]string
 
@@ -102,15 +153,251 @@ type RunParams struct {
 	// LoadTestConfigOverrides are YAML fields overlaid onto native base-load-tester
 	// config files for load-test payloads.
 	LoadTestConfigOverrides map[string]interface{}
+
+	// BlobsPerBlock is the number of blobs included in each fake L1 block when
+	// batching L2 data for the proof program. Zero fills each L1 block up to
+	// the L1 maximum blob count without padding.
+	BlobsPerBlock uint64
+
+	// L1OriginInterval is the number of L2 blocks built on each simulated L1
+	// origin. Zero derives it from the block time.
+	L1OriginInterval uint64
+
+	// FeeParams are the fee parameters set by the sequencer.
+	FeeParams FeeParams
+}
+
+// Fees are the fee parameters the sequencer sets in the payload attributes and
+// the L1 info transaction.
+type Fees struct {
+	// EIP1559Denominator and EIP1559Elasticity are the Holocene EIP-1559
+	// params of the payload attributes.
+	EIP1559Denominator uint32
+	EIP1559Elasticity  uint32
+	// MinBaseFee is the Jovian minimum base fee in wei.
+	MinBaseFee uint64
+	// BaseFeeScalar and BlobBaseFeeScalar weigh the L1 base fee and blob base
+	// fee in the L1 data fee.
+	BaseFeeScalar     uint32
+	BlobBaseFeeScalar uint32
+	// OperatorFeeScalar and OperatorFeeConstant are the Isthmus operator fee
+	// params.
+	OperatorFeeScalar   uint32
+	OperatorFeeConstant uint64
+	// DAFootprintGasScalar is the Jovian DA footprint gas scalar. Zero
+	// disables the DA footprint block limit, so that benchmarks measure EL
+	// gas throughput rather than the L1 DA budget.
+	DAFootprintGasScalar uint16
+}
+
+// DefaultFees are the fees used for the parameters a run does not set.
+var DefaultFees = Fees{
+	EIP1559Denominator: 50,
+	EIP1559Elasticity:  1,
+	MinBaseFee:         1,
+	BaseFeeScalar:      1,
+	BlobBaseFeeScalar:  1,
+}
+
+// PayloadGasFeeCap is the fee cap in wei of the transactions sent by the
+// built-in payload workers. Once the base fee reaches it, they can no longer
+// be included and blocks are empty.
+const PayloadGasFeeCap = 1_000_000_000 // 1 gwei
+
+// FeeParams are the fee parameters chosen for a run. Nil fields use
+// DefaultFees.
+type FeeParams struct {
+	EIP1559Denominator   *uint32
+	EIP1559Elasticity    *uint32
+	MinBaseFee           *uint64
+	BaseFeeScalar        *uint32
+	BlobBaseFeeScalar    *uint32
+	OperatorFeeScalar    *uint32
+	OperatorFeeConstant  *uint64
+	DAFootprintGasScalar *uint16
+}
+
+// Resolve returns the fees of the run, filling in the defaults.
+func (p FeeParams) Resolve() Fees {
+	fees := DefaultFees
+	if p.EIP1559Denominator != nil {
+		fees.EIP1559Denominator = *p.EIP1559Denominator
+	}
+	if p.EIP1559Elasticity != nil {
+		fees.EIP1559Elasticity = *p.EIP1559Elasticity
+	}
+	if p.MinBaseFee != nil {
+		fees.MinBaseFee = *p.MinBaseFee
+	}
+	if p.BaseFeeScalar != nil {
+		fees.BaseFeeScalar = *p.BaseFeeScalar
+	}
+	if p.BlobBaseFeeScalar != nil {
+		fees.BlobBaseFeeScalar = *p.BlobBaseFeeScalar
+	}
+	if p.OperatorFeeScalar != nil {
+		fees.OperatorFeeScalar = *p.OperatorFeeScalar
+	}
+	if p.OperatorFeeConstant != nil {
+		fees.OperatorFeeConstant = *p.OperatorFeeConstant
+	}
+	if p.DAFootprintGasScalar != nil {
+		fees.DAFootprintGasScalar = *p.DAFootprintGasScalar
+	}
+	return fees
+}
+
+// toConfig adds the fee parameters set for the run to a test config.
+func (p FeeParams) toConfig(params map[string]interface{}) {
+	if p.EIP1559Denominator != nil {
+		params["EIP1559Denominator"] = *p.EIP1559Denominator
+	}
+	if p.EIP1559Elasticity != nil {
+		params["EIP1559Elasticity"] = *p.EIP1559Elasticity
+	}
+	if p.MinBaseFee != nil {
+		params["MinBaseFee"] = *p.MinBaseFee
+	}
+	if p.BaseFeeScalar != nil {
+		params["BaseFeeScalar"] = *p.BaseFeeScalar
+	}
+	if p.BlobBaseFeeScalar != nil {
+		params["BlobBaseFeeScalar"] = *p.BlobBaseFeeScalar
+	}
+	if p.OperatorFeeScalar != nil {
+		params["OperatorFeeScalar"] = *p.OperatorFeeScalar
+	}
+	if p.OperatorFeeConstant != nil {
+		params["OperatorFeeConstant"] = *p.OperatorFeeConstant
+	}
+	if p.DAFootprintGasScalar != nil {
+		params["DAFootprintGasScalar"] = *p.DAFootprintGasScalar
+	}
 }
 
 const (
 	ConsensusTimingModePreventLateFCU = "prevent-late-fcu"
 	ConsensusTimingModeBaseConsensus  = "base-consensus"
+	// ConsensusTimingModeFixedWindow calls getPayload a fixed build window
+	// after the FCU.
+	ConsensusTimingModeFixedWindow = "fixed-window"
+	// ConsensusTimingModeASAP calls getPayload right after the FCU to measure
+	// pure build throughput.
+	ConsensusTimingModeASAP = "asap"
+	// ConsensusTimingModeJittered follows base-consensus timing with randomly
+	// delayed FCU and getPayload calls.
+	ConsensusTimingModeJittered = "jittered"
 )
 
-func (p RunParams) UseBaseConsensusTiming() bool {
-	return p.ConsensusTimingMode == ConsensusTimingModeBaseConsensus
+// ConsensusTimingModes are the supported consensus timing modes.
+var ConsensusTimingModes = []string{
+	ConsensusTimingModePreventLateFCU,
+	ConsensusTimingModeBaseConsensus,
+	ConsensusTimingModeFixedWindow,
+	ConsensusTimingModeASAP,
+	ConsensusTimingModeJittered,
+}
+
+// UsePreventLateFCUTiming returns true if the run aligns FCUs to block time
+// boundaries and waits a block time between blocks.
+func (p RunParams) UsePreventLateFCUTiming() bool {
+	return p.ConsensusTimingMode == "" || p.ConsensusTimingMode == ConsensusTimingModePreventLateFCU
+}
+
+// PacesBlocksByBlockTime returns true if the consensus timing mode waits a
+// block time between blocks. The fixed-window and asap modes may build blocks
+// faster, so a duration does not bound the number of blocks.
+func (p RunParams) PacesBlocksByBlockTime() bool {
+	return p.ConsensusTimingMode != ConsensusTimingModeFixedWindow && p.ConsensusTimingMode != ConsensusTimingModeASAP
+}
+
+// CheckConsensusTiming returns an error if the timing parameters do not match
+// the consensus timing mode.
+func (p RunParams) CheckConsensusTiming() error {
+	if p.ConsensusTimingMode == ConsensusTimingModeFixedWindow {
+		if p.BuildWindow <= 0 {
+			return fmt.Errorf("consensus timing %s requires a positive build_window", p.ConsensusTimingMode)
+		}
+	} else if p.BuildWindow != 0 {
+		return fmt.Errorf("build_window requires consensus timing %s, got %s", ConsensusTimingModeFixedWindow, p.ConsensusTimingMode)
+	}
+
+	if p.ConsensusTimingMode == ConsensusTimingModeJittered {
+		if p.TimingJitter <= 0 {
+			return fmt.Errorf("consensus timing %s requires a positive timing_jitter", p.ConsensusTimingMode)
+		}
+		if p.TimingJitter >= p.BlockTime {
+			return fmt.Errorf("timing_jitter %s must be less than the block time %s", p.TimingJitter, p.BlockTime)
+		}
+	} else if p.TimingJitter != 0 {
+		return fmt.Errorf("timing_jitter requires consensus timing %s, got %s", ConsensusTimingModeJittered, p.ConsensusTimingMode)
+	}
+	return nil
+}
+
+// HasWarmup returns true if the run starts with a warm-up phase.
+func (p RunParams) HasWarmup() bool {
+	return p.WarmupBlocks > 0 || p.WarmupDuration > 0
+}
+
+// HasSteadyStateDetection returns true if the warm-up ends once the sequencer
+// gas/s is steady.
+func (p RunParams) HasSteadyStateDetection() bool {
+	return p.SteadyStateWindow > 0
+}
+
+// CheckRunLength returns an error if the warm-up and steady-state parameters
+// do not fit together.
+func (p RunParams) CheckRunLength() error {
+	if (p.SteadyStateWindow > 0) != (p.SteadyStateTolerance > 0) {
+		return errors.New("steady_state_window and steady_state_tolerance must be set together")
+	}
+	if p.HasSteadyStateDetection() && !p.HasWarmup() {
+		return errors.New("steady-state detection requires warmup_blocks or warmup_duration to bound the warm-up")
+	}
+	return nil
+}
+
+// PlannedBlocks returns the number of blocks the run is expected to build
+// after the setup, including the longest possible warm-up. Durations are
+// converted at the block time, so the count is only a lower bound if the run
+// does not pace blocks by block time.
+func (p RunParams) PlannedBlocks() int {
+	blocks := p.NumBlocks
+	if p.Duration > 0 && p.BlockTime > 0 {
+		blocks = int((p.Duration + p.BlockTime - 1) / p.BlockTime)
+	}
+
+	warmupBlocks := p.WarmupBlocks
+	if p.WarmupDuration > 0 && p.BlockTime > 0 {
+		warmupBlocks = max(warmupBlocks, int((p.WarmupDuration+p.BlockTime-1)/p.BlockTime))
+	}
+	return blocks + warmupBlocks
+}
+
+// PayloadTimestampResolution returns the unit of the payload timestamps.
+func (p RunParams) PayloadTimestampResolution() time.Duration {
+	if p.TimestampResolution <= 0 {
+		return time.Second
+	}
+	return p.TimestampResolution
+}
+
+// CheckBlockTime returns an error if a block every BlockTime cannot have
+// strictly increasing timestamps. Whether the nodes accept millisecond
+// timestamps is checked when they start.
+func (p RunParams) CheckBlockTime() error {
+	resolution := p.PayloadTimestampResolution()
+	if p.BlockTime <= 0 {
+		return fmt.Errorf("block time must be positive, got %s", p.BlockTime)
+	}
+	if p.BlockTime%resolution != 0 {
+		if resolution == time.Second {
+			return fmt.Errorf("block time %s is not a whole number of seconds, sub-second block times require timestamp_resolution: 1ms", p.BlockTime)
+		}
+		return fmt.Errorf("block time %s is not a multiple of the timestamp resolution %s", p.BlockTime, resolution)
+	}
+	return nil
 }
 
 func (p RunParams) ToConfig() map[string]interface{} {
@@ -131,9 +418,38 @@ func (p RunParams) ToConfig() map[string]interface{} {
 	if p.ConsensusTimingMode != "" {
 		params["ConsensusTimingMode"] = p.ConsensusTimingMode
 	}
+	if p.BuildWindow > 0 {
+		params["BuildWindowMilliseconds"] = p.BuildWindow.Milliseconds()
+	}
+	if p.TimingJitter > 0 {
+		params["TimingJitterMilliseconds"] = p.TimingJitter.Milliseconds()
+	}
 	if len(p.LoadTestConfigOverrides) > 0 {
 		params["LoadTestConfigOverrides"] = p.LoadTestConfigOverrides
 	}
+	if p.BlobsPerBlock > 0 {
+		params["BlobsPerBlock"] = p.BlobsPerBlock
+	}
+	if p.L1OriginInterval > 0 {
+		params["L1OriginInterval"] = p.L1OriginInterval
+	}
+	if resolution := p.PayloadTimestampResolution(); resolution != time.Second {
+		params["TimestampResolutionMilliseconds"] = resolution.Milliseconds()
+	}
+	if p.Duration > 0 {
+		params["DurationSeconds"] = p.Duration.Seconds()
+	}
+	if p.WarmupBlocks > 0 {
+		params["WarmupBlocks"] = p.WarmupBlocks
+	}
+	if p.WarmupDuration > 0 {
+		params["WarmupDurationSeconds"] = p.WarmupDuration.Seconds()
+	}
+	if p.HasSteadyStateDetection() {
+		params["SteadyStateWindow"] = p.SteadyStateWindow
+		params["SteadyStateTolerance"] = p.SteadyStateTolerance
+	}
+	p.FeeParams.toConfig(params)
 
 	for k, v := range p.Tags {
 		params[k] = v
@@ -160,6 +476,34 @@ func (p RunParams) ClientOptions(prevClientOptions config.ClientOptions) config.
 	return prevClientOptions
 }
 
+// latencyPercentile is the percentile of the per-block latencies recorded in
+// the key metrics.
+const latencyPercentile = 0.95
+
+// getPercentile returns the p-th percentile, by nearest rank, of the sum of
+// the named metrics of each block that records all of them.
+func getPercentile(metrics []metrics.BlockMetrics, p float64, metricNames ...string) float64 {
+	var values []float64
+blocks:
+	for _, metric := range metrics {
+		total := 0.0
+		for _, name := range metricNames {
+			value, ok := metric.GetMetricFloat(name)
+			if !ok {
+				continue blocks
+			}
+			total += value
+		}
+		values = append(values, total)
+	}
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	rank := int(math.Ceil(p * float64(len(values))))
+	return values[max(rank, 1)-1]
+}
+
 func getAverage(metrics []metrics.BlockMetrics, metricName string) float64 {
 	var total float64
 	var count int
@@ -183,6 +527,8 @@ const (
 	GasPerBlockMetric                  = "gas/per_block"
 	GasPerSecondMetric                 = "gas/per_second"
 	TransactionsPerBlockMetric         = "transactions/per_block"
+	PendingTransactionsMetric          = "transactions/pending"
+	DepositsPerBlockMetric             = "transactions/deposits_per_block"
 	FlashblockProcessingDurationMetric = "reth_flashblocks_block_processing_duration"
 	FlashblockSenderRecoveryMetric     = "reth_flashblocks_sender_recovery_duration"
 	FlashblocksInBlockMetric           = "reth_flashblocks_flashblocks_in_block"
@@ -190,11 +536,23 @@ const (
 	FlashblockBundleStateCloneDuration = "reth_flashblocks_bundle_state_clone_duration"
 )
 
+// Engine*LatencyMetric are the engine API latencies measured by the engine
+// trace proxy, without the runner's own JSON handling.
+const (
+	EngineUpdateForkChoiceLatencyMetric = "latency/engine/update_fork_choice"
+	EngineNewPayloadLatencyMetric       = "latency/engine/new_payload"
+	EngineGetPayloadLatencyMetric       = "latency/engine/get_payload"
+)
+
 type SequencerKeyMetrics struct {
 	CommonKeyMetrics
 	AverageFCULatency        float64 `json:"forkChoiceUpdated"`
 	AverageGetPayloadLatency float64 `json:"getPayload"`
 	AverageSendTxsLatency    float64 `json:"sendTxs"`
+	AverageGasPerBlock       float64 `json:"gasPerBlock"`
+	// BuildLatencyP95 is the 95th percentile of the per-block sum of the
+	// forkchoiceUpdated and getPayload latencies.
+	BuildLatencyP95 float64 `json:"buildLatencyP95,omitempty"`
 }
 
 type ValidatorKeyMetrics struct {
@@ -202,6 +560,22 @@ type ValidatorKeyMetrics struct {
 	AverageNewPayloadLatency            float64 `json:"newPayload"`
 	AverageFlashblockProcessingDuration float64 `json:"flashblockProcessingDuration,omitempty"`
 	AverageFlashblocksInBlock           float64 `json:"flashblocksInBlock,omitempty"`
+	// NewPayloadLatencyP95 is the 95th percentile of the per-block newPayload
+	// latency.
+	NewPayloadLatencyP95 float64 `json:"newPayloadP95,omitempty"`
+}
+
+// ProofProgramKeyMetrics summarizes the derivation and proof cost of a proof
+// program run over the test blocks.
+type ProofProgramKeyMetrics struct {
+	// Duration is the wall-clock time of the proof program in seconds.
+	Duration float64 `json:"duration"`
+	// DurationPerBlob is Duration divided by the number of blobs submitted.
+	DurationPerBlob float64 `json:"durationPerBlob,omitempty"`
+	L2Blocks        int     `json:"l2Blocks"`
+	L1Blocks        int     `json:"l1Blocks"`
+	DataBlobs       int     `json:"dataBlobs"`
+	FillerBlobs     int     `json:"fillerBlobs"`
 }
 
 type CommonKeyMetrics struct {
@@ -219,6 +593,7 @@ func BlockMetricsToValidatorSummary(metrics []metrics.BlockMetrics) *ValidatorKe
 		AverageNewPayloadLatency:            averageNewPayloadLatency,
 		AverageFlashblockProcessingDuration: averageFlashblockProcessingDuration,
 		AverageFlashblocksInBlock:           averageFlashblocksInBlock,
+		NewPayloadLatencyP95:                getPercentile(metrics, latencyPercentile, NewPayloadLatencyMetric),
 		CommonKeyMetrics: CommonKeyMetrics{
 			AverageGasPerSecond: averageGasPerSecond,
 		},
@@ -231,13 +606,27 @@ func BlockMetricsToSequencerSummary(metrics []metrics.BlockMetrics) *SequencerKe
 	averageSendTxsLatency := getAverage(metrics, SendTxsLatencyMetric)
 	averageGetPayloadLatency := getAverage(metrics, GetPayloadLatencyMetric)
 	averageGasPerSecond := getAverage(metrics, GasPerSecondMetric)
+	averageGasPerBlock := getAverage(metrics, GasPerBlockMetric)
 
 	return &SequencerKeyMetrics{
 		AverageFCULatency:        averageUpdateForkChoiceLatency,
 		AverageSendTxsLatency:    averageSendTxsLatency,
 		AverageGetPayloadLatency: averageGetPayloadLatency,
+		AverageGasPerBlock:       averageGasPerBlock,
+		BuildLatencyP95:          getPercentile(metrics, latencyPercentile, UpdateForkChoiceLatencyMetric, GetPayloadLatencyMetric),
 		CommonKeyMetrics: CommonKeyMetrics{
 			AverageGasPerSecond: averageGasPerSecond,
 		},
 	}
 }
+
+// WarmupSummary describes the warm-up phase of a run, whose blocks are
+// excluded from the metrics.
+type WarmupSummary struct {
+	Blocks int `json:"blocks"`
+	// Duration is the wall-clock time of the warm-up in seconds.
+	Duration float64 `json:"duration"`
+	// SteadyState is true if the warm-up ended because the sequencer gas/s was
+	// steady, rather than at its maximum length.
+	SteadyState bool `json:"steadyState"`
+}
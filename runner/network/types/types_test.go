@@ -0,0 +1,31 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/base/base-bench/runner/metrics"
+)
+
+func TestBlockMetricsToSequencerSummaryBuildLatencyP95(t *testing.T) {
+	blocks := make([]metrics.BlockMetrics, 0, 20)
+	for i := 0; i < 20; i++ {
+		m := metrics.NewBlockMetrics()
+		m.AddExecutionMetric(UpdateForkChoiceLatencyMetric, 10*time.Millisecond)
+		getPayload := 90 * time.Millisecond
+		if i < 2 {
+			// two of twenty blocks miss a 1s deadline
+			getPayload = 1500 * time.Millisecond
+		}
+		m.AddExecutionMetric(GetPayloadLatencyMetric, getPayload)
+		blocks = append(blocks, *m)
+	}
+
+	summary := BlockMetricsToSequencerSummary(blocks)
+	if summary.AverageFCULatency+summary.AverageGetPayloadLatency >= 1 {
+		t.Fatalf("expected the average build latency within 1s, got %v", summary.AverageFCULatency+summary.AverageGetPayloadLatency)
+	}
+	if summary.BuildLatencyP95 < 1.5 {
+		t.Fatalf("expected the p95 build latency to include the late blocks, got %v", summary.BuildLatencyP95)
+	}
+}
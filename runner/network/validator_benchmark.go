@@ -15,6 +15,7 @@ import (
 	"github.com/base/base-bench/runner/network/flashblocks"
 	"github.com/base/base-bench/runner/network/proofprogram/fakel1"
 	benchtypes "github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/timeline"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/log"
@@ -28,6 +29,9 @@ type validatorBenchmark struct {
 	proofConfig      *benchmark.ProofProgramOptions
 	l1Chain          *l1Chain
 	flashblockServer *flashblocks.ReplayServer
+
+	// proofMetrics is populated once the proof program benchmark completes.
+	proofMetrics *benchtypes.ProofProgramKeyMetrics
 }
 
 func newValidatorBenchmark(log log.Logger, config benchtypes.TestConfig, validatorClient types.ExecutionClient, l1Chain *l1Chain, proofConfig *benchmark.ProofProgramOptions, flashblockServer *flashblocks.ReplayServer) *validatorBenchmark {
@@ -54,12 +58,19 @@ func (vb *validatorBenchmark) benchmarkFaultProofProgram(ctx context.Context, pa
 	}
 
 	blockTimeSec := uint64(vb.config.Params.BlockTime.Seconds())
-	opProgramBenchmark := NewOPProgramBenchmark(&vb.config.Genesis, vb.log, binaryPath, vb.validatorClient.ClientURL(), l1Chain, batcherKey, blockTimeSec)
+	opProgramBenchmark := NewOPProgramBenchmark(&vb.config.Genesis, vb.log, binaryPath, vb.validatorClient.ClientURL(), l1Chain, batcherKey, blockTimeSec, vb.config.Params.BlobsPerBlock)
 
-	return opProgramBenchmark.Run(ctx, payloads, lastSetupBlock)
+	proofMetrics, err := opProgramBenchmark.Run(ctx, payloads, lastSetupBlock)
+	if err != nil {
+		return err
+	}
+	vb.proofMetrics = proofMetrics
+	return nil
 }
 
-func (vb *validatorBenchmark) Run(ctx context.Context, payloads []engine.ExecutableData, lastSetupBlock uint64, metricsCollector metrics.Collector) error {
+// Run replays the payloads on the validator and collects the metrics of the
+// blocks from firstTestBlock on.
+func (vb *validatorBenchmark) Run(ctx context.Context, payloads []engine.ExecutableData, lastSetupBlock uint64, firstTestBlock uint64, metricsCollector metrics.Collector) error {
 	headBlockHeader, err := vb.validatorClient.Client().HeaderByNumber(ctx, nil)
 	if err != nil {
 		vb.log.Warn("failed to get head block header", "err", err)
@@ -80,6 +91,7 @@ func (vb *validatorBenchmark) Run(ctx context.Context, payloads []engine.Executa
 			vb.log.Warn("Validator did not connect to flashblock server, continuing without flashblock replay", "err", err)
 		} else {
 			vb.log.Info("Validator connected to flashblock server, starting flashblock replay")
+			vb.config.Timeline.Instant(timeline.TrackFlashblocks, "flashblocks", "validator connected")
 
 			// Start replaying flashblocks in a goroutine
 			go func() {
@@ -89,7 +101,10 @@ func (vb *validatorBenchmark) Run(ctx context.Context, payloads []engine.Executa
 						return
 					case blockNumber := <-startedBlockSignal:
 						vb.log.Info("Replaying flashblocks for block", "block_number", blockNumber)
-						if err := vb.flashblockServer.ReplayFlashblock(ctx, blockNumber); err != nil {
+						span := vb.config.Timeline.Begin(timeline.TrackFlashblocks, "flashblocks", "replay flashblocks", "block", blockNumber)
+						err := vb.flashblockServer.ReplayFlashblock(ctx, blockNumber)
+						span.End("err", err)
+						if err != nil {
 							if !errors.Is(err, context.Canceled) {
 								vb.log.Warn("Error replaying flashblocks", "err", err)
 							}
@@ -101,10 +116,14 @@ func (vb *validatorBenchmark) Run(ctx context.Context, payloads []engine.Executa
 	}
 
 	consensusClient := consensus.NewSyncingConsensusClient(vb.log, vb.validatorClient.Client(), vb.validatorClient.AuthClient(), consensus.ConsensusClientOptions{
-		BlockTime: vb.config.Params.BlockTime,
+		BlockTime:           vb.config.Params.BlockTime,
+		TimestampResolution: vb.config.Params.PayloadTimestampResolution(),
+		Timeline:            vb.config.Timeline,
+		ChainConfig:         vb.config.Genesis.Config,
+		EngineTracer:        engineTracer(vb.validatorClient),
 	}, headBlockHash, headBlockNumber)
 
-	err = consensusClient.Start(ctx, payloads, metricsCollector, lastSetupBlock + 1, startedBlockSignal)
+	err = consensusClient.Start(ctx, payloads, metricsCollector, firstTestBlock, startedBlockSignal)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return err
@@ -0,0 +1,121 @@
+package composite
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// TxKind is a kind of transaction generated by the composite payload.
+type TxKind int
+
+const (
+	TxKindERC20Transfer TxKind = iota
+	TxKindSwap
+	TxKindNFTMint
+)
+
+func (k TxKind) String() string {
+	switch k {
+	case TxKindERC20Transfer:
+		return "erc20_transfer"
+	case TxKindSwap:
+		return "swap"
+	case TxKindNFTMint:
+		return "nft_mint"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(k))
+	}
+}
+
+// WorkloadMix is the relative weight of each transaction kind. Weights do not
+// need to sum to one.
+type WorkloadMix struct {
+	ERC20Transfer float64 `yaml:"erc20_transfer"`
+	Swap          float64 `yaml:"swap"`
+	NFTMint       float64 `yaml:"nft_mint"`
+}
+
+// DefaultWorkloadMix is used when no mix is configured.
+var DefaultWorkloadMix = WorkloadMix{
+	ERC20Transfer: 0.6,
+	Swap:          0.3,
+	NFTMint:       0.1,
+}
+
+func (m WorkloadMix) weights() []float64 {
+	return []float64{m.ERC20Transfer, m.Swap, m.NFTMint}
+}
+
+// Validate checks that all weights are non-negative and at least one is set.
+func (m WorkloadMix) Validate() error {
+	total := 0.0
+	for i, w := range m.weights() {
+		if w < 0 {
+			return fmt.Errorf("negative weight %f for %s", w, TxKind(i))
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("at least one workload weight must be positive")
+	}
+	return nil
+}
+
+// Has returns true if the given kind has a non-zero weight.
+func (m WorkloadMix) Has(kind TxKind) bool {
+	return m.weights()[kind] > 0
+}
+
+// kindPicker selects transaction kinds according to a WorkloadMix.
+type kindPicker struct {
+	cumulative []float64
+	rng        *rand.Rand
+}
+
+func newKindPicker(mix WorkloadMix, rng *rand.Rand) *kindPicker {
+	weights := mix.weights()
+	cumulative := make([]float64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		total += w
+		cumulative[i] = total
+	}
+	for i := range cumulative {
+		cumulative[i] /= total
+	}
+	return &kindPicker{cumulative: cumulative, rng: rng}
+}
+
+func (p *kindPicker) Next() TxKind {
+	r := p.rng.Float64()
+	for i, c := range p.cumulative {
+		if r < c {
+			return TxKind(i)
+		}
+	}
+	return TxKind(len(p.cumulative) - 1)
+}
+
+// indexSampler picks indices in [0, n). With a Zipf exponent above 1 the
+// lowest indices are picked far more often, modelling hot keys; otherwise
+// indices are picked uniformly.
+type indexSampler struct {
+	n    int
+	zipf *rand.Zipf
+	rng  *rand.Rand
+}
+
+func newIndexSampler(n int, zipfS float64, rng *rand.Rand) *indexSampler {
+	s := &indexSampler{n: n, rng: rng}
+	if zipfS > 1 && n > 1 {
+		s.zipf = rand.NewZipf(rng, zipfS, 1, uint64(n-1))
+	}
+	return s
+}
+
+func (s *indexSampler) Next() int {
+	if s.zipf != nil {
+		return int(s.zipf.Uint64())
+	}
+	return s.rng.Intn(s.n)
+}
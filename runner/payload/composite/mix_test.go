@@ -0,0 +1,58 @@
+package composite
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadMixValidate(t *testing.T) {
+	require.NoError(t, DefaultWorkloadMix.Validate())
+	require.NoError(t, WorkloadMix{Swap: 1}.Validate())
+	require.Error(t, WorkloadMix{}.Validate())
+	require.Error(t, WorkloadMix{ERC20Transfer: 1, NFTMint: -1}.Validate())
+}
+
+func TestKindPickerFollowsWeights(t *testing.T) {
+	picker := newKindPicker(WorkloadMix{ERC20Transfer: 3, NFTMint: 1}, rand.New(rand.NewSource(1)))
+
+	counts := make(map[TxKind]int)
+	const samples = 10_000
+	for i := 0; i < samples; i++ {
+		counts[picker.Next()]++
+	}
+
+	require.Zero(t, counts[TxKindSwap])
+	require.InDelta(t, 0.75, float64(counts[TxKindERC20Transfer])/samples, 0.02)
+	require.InDelta(t, 0.25, float64(counts[TxKindNFTMint])/samples, 0.02)
+}
+
+func TestIndexSamplerSkew(t *testing.T) {
+	const n = 100
+	const samples = 10_000
+
+	hotShare := func(zipfS float64) float64 {
+		sampler := newIndexSampler(n, zipfS, rand.New(rand.NewSource(1)))
+		hot := 0
+		for i := 0; i < samples; i++ {
+			idx := sampler.Next()
+			require.GreaterOrEqual(t, idx, 0)
+			require.Less(t, idx, n)
+			if idx == 0 {
+				hot++
+			}
+		}
+		return float64(hot) / samples
+	}
+
+	require.InDelta(t, 1.0/n, hotShare(0), 0.01)
+	require.Greater(t, hotShare(1.5), 0.3)
+}
+
+func TestIndexSamplerSingleKey(t *testing.T) {
+	sampler := newIndexSampler(1, 2, rand.New(rand.NewSource(1)))
+	for i := 0; i < 10; i++ {
+		require.Equal(t, 0, sampler.Next())
+	}
+}
@@ -0,0 +1,627 @@
+package composite
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/base/base-bench/runner/network/mempool"
+	benchtypes "github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/payload/contract"
+	"github.com/base/base-bench/runner/payload/worker"
+	"github.com/ethereum-optimism/optimism/op-service/retry"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// CompositePayloadDefinition configures a mix of realistic contract
+// interactions: ERC-20 transfers among many holders, swaps against
+// Uniswap-v2-style pools and NFT mints.
+type CompositePayloadDefinition struct {
+	// NumHolders is the number of funded accounts that send and receive.
+	NumHolders *int `yaml:"num_holders"`
+	// NumContracts is the number of distinct tokens, pools and NFT collections
+	// deployed for each enabled kind.
+	NumContracts *int `yaml:"num_contracts"`
+	// Mix is the relative weight of each transaction kind.
+	Mix *WorkloadMix `yaml:"mix"`
+	// ZipfS is the Zipf exponent used to pick recipients and contracts. Values
+	// above 1 concentrate traffic on a few hot keys; unset or <= 1 is uniform.
+	ZipfS *float64 `yaml:"zipf_s"`
+	// Seed seeds the random choices so runs are reproducible.
+	Seed *int64 `yaml:"seed"`
+}
+
+const (
+	defaultNumHolders   = 1000
+	defaultNumContracts = 1
+	defaultSeed         = 100
+
+	// setupTxGas is the gas limit used for deployments and setup calls.
+	setupTxGas = 8_000_000
+	// mintBatchSize is the number of holders credited per mintBatch call.
+	mintBatchSize = 100
+	// mineAndConfirmBatchSize is the number of setup transactions submitted
+	// before waiting for them to be mined.
+	mineAndConfirmBatchSize = 50
+	// gasBuffer is the gas left unused in every block.
+	gasBuffer = 100_000
+)
+
+var (
+	holderTokenBalance = new(big.Int).Mul(big.NewInt(1e6), big.NewInt(params.Ether))
+	poolTokenBalance   = new(big.Int).Mul(big.NewInt(1e8), big.NewInt(params.Ether))
+
+	// defaultGasEstimates are used when gas estimation fails during setup.
+	defaultGasEstimates = map[TxKind]uint64{
+		TxKindERC20Transfer: 65_000,
+		TxKindSwap:          130_000,
+		TxKindNFTMint:       110_000,
+	}
+)
+
+type compositePayloadWorker struct {
+	log log.Logger
+
+	params  benchtypes.RunParams
+	def     CompositePayloadDefinition
+	mix     WorkloadMix
+	chainID *big.Int
+	client  *ethclient.Client
+	signer  types.Signer
+
+	prefundedAccount *ecdsa.PrivateKey
+	prefundAmount    *big.Int
+	prefundNonce     uint64
+
+	mempool *mempool.StaticWorkloadMempool
+
+	token *contract.Artifact
+	pair  *contract.Artifact
+	nft   *contract.Artifact
+
+	holderKeys  []*ecdsa.PrivateKey
+	holderAddrs []common.Address
+	nextNonce   map[common.Address]uint64
+	currHolder  int
+
+	tokens     []common.Address
+	quoteToken common.Address
+	pools      []common.Address
+	nfts       []common.Address
+
+	gasEstimates map[TxKind]uint64
+
+	rng             *rand.Rand
+	picker          *kindPicker
+	holderSampler   *indexSampler
+	contractSampler *indexSampler
+}
+
+func NewCompositePayloadWorker(ctx context.Context, log log.Logger, elRPCURL string, params benchtypes.RunParams, prefundedPrivateKey ecdsa.PrivateKey, prefundAmount *big.Int, genesis *core.Genesis, definition any) (worker.Worker, error) {
+	if params.GasLimit <= gasBuffer {
+		return nil, fmt.Errorf("gas limit %d must be above the composite payload's %d gas buffer", params.GasLimit, gasBuffer)
+	}
+
+	mempool := mempool.NewStaticWorkloadMempool(log, genesis.Config.ChainID)
+
+	client, err := ethclient.Dial(elRPCURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var def CompositePayloadDefinition
+	if definition != nil {
+		defPtr, ok := definition.(*CompositePayloadDefinition)
+		if !ok {
+			return nil, fmt.Errorf("invalid composite transaction payload: %#v", definition)
+		}
+		def = *defPtr
+	}
+
+	mix := DefaultWorkloadMix
+	if def.Mix != nil {
+		mix = *def.Mix
+	}
+	if err := mix.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid composite payload mix")
+	}
+
+	numHolders := defaultNumHolders
+	if def.NumHolders != nil {
+		numHolders = *def.NumHolders
+	}
+	numContracts := defaultNumContracts
+	if def.NumContracts != nil {
+		numContracts = *def.NumContracts
+	}
+	if numHolders < 1 || numContracts < 1 {
+		return nil, fmt.Errorf("num_holders and num_contracts must be positive")
+	}
+
+	seed := int64(defaultSeed)
+	if def.Seed != nil {
+		seed = *def.Seed
+	}
+	zipfS := 0.0
+	if def.ZipfS != nil {
+		zipfS = *def.ZipfS
+	}
+
+	chainID := genesis.Config.ChainID
+	rng := rand.New(rand.NewSource(seed))
+
+	t := &compositePayloadWorker{
+		log:              log,
+		params:           params,
+		def:              def,
+		mix:              mix,
+		chainID:          chainID,
+		client:           client,
+		signer:           types.NewPragueSigner(chainID),
+		prefundedAccount: &prefundedPrivateKey,
+		prefundAmount:    prefundAmount,
+		mempool:          mempool,
+		nextNonce:        make(map[common.Address]uint64),
+		gasEstimates:     make(map[TxKind]uint64),
+		rng:              rng,
+		picker:           newKindPicker(mix, rng),
+		holderSampler:    newIndexSampler(numHolders, zipfS, rng),
+		contractSampler:  newIndexSampler(numContracts, zipfS, rng),
+	}
+
+	for name, dst := range map[string]**contract.Artifact{
+		"BenchToken": &t.token,
+		"BenchPair":  &t.pair,
+		"BenchNFT":   &t.nft,
+	} {
+		c, err := loadContract(name)
+		if err != nil {
+			return nil, err
+		}
+		*dst = c
+	}
+
+	if err := t.generateHolders(numHolders, seed); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// loadContract loads the artifact of a contract built in this repository.
+func loadContract(name string) (*contract.Artifact, error) {
+	artifact, err := contract.LoadArtifact(name)
+	if err != nil {
+		return nil, err
+	}
+	if artifact.ABI == nil {
+		return nil, fmt.Errorf("contract artifact of %s has no abi", name)
+	}
+	return artifact, nil
+}
+
+func (t *compositePayloadWorker) generateHolders(numHolders int, seed int64) error {
+	src := rand.New(rand.NewSource(seed))
+	t.holderKeys = make([]*ecdsa.PrivateKey, 0, numHolders)
+	t.holderAddrs = make([]common.Address, 0, numHolders)
+	for i := 0; i < numHolders; i++ {
+		key, err := ecdsa.GenerateKey(crypto.S256(), src)
+		if err != nil {
+			return err
+		}
+		t.holderKeys = append(t.holderKeys, key)
+		t.holderAddrs = append(t.holderAddrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	return nil
+}
+
+func (t *compositePayloadWorker) Mempool() mempool.FakeMempool {
+	return t.mempool
+}
+
+func (t *compositePayloadWorker) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (t *compositePayloadWorker) numContracts() int {
+	if t.def.NumContracts != nil {
+		return *t.def.NumContracts
+	}
+	return defaultNumContracts
+}
+
+func (t *compositePayloadWorker) Setup(ctx context.Context) error {
+	prefundAddr := crypto.PubkeyToAddress(t.prefundedAccount.PublicKey)
+
+	balance, err := t.client.BalanceAt(ctx, prefundAddr, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch prefunded account balance")
+	}
+	if balance.Cmp(t.prefundAmount) < 0 {
+		return fmt.Errorf("prefunded account balance %s is less than prefund amount %s", balance.String(), t.prefundAmount.String())
+	}
+
+	t.prefundNonce, err = t.client.PendingNonceAt(ctx, prefundAddr)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch prefunded account nonce")
+	}
+
+	if err := t.deployContracts(ctx); err != nil {
+		return errors.Wrap(err, "failed to deploy contracts")
+	}
+
+	if err := t.fundHolders(ctx, balance); err != nil {
+		return errors.Wrap(err, "failed to fund holders")
+	}
+
+	if err := t.distributeTokens(ctx); err != nil {
+		return errors.Wrap(err, "failed to distribute tokens")
+	}
+
+	if err := t.seedPools(ctx); err != nil {
+		return errors.Wrap(err, "failed to seed pools")
+	}
+
+	if err := t.fetchHolderNonces(ctx); err != nil {
+		return err
+	}
+
+	t.estimateGas(ctx)
+
+	t.log.Info("Composite payload ready",
+		"holders", len(t.holderAddrs), "tokens", len(t.tokens), "pools", len(t.pools), "nfts", len(t.nfts),
+		"erc20_transfer_gas", t.gasEstimates[TxKindERC20Transfer], "swap_gas", t.gasEstimates[TxKindSwap], "nft_mint_gas", t.gasEstimates[TxKindNFTMint])
+
+	return nil
+}
+
+func (t *compositePayloadWorker) deployContracts(ctx context.Context) error {
+	numContracts := t.numContracts()
+	txs := make([]*types.Transaction, 0)
+
+	deploy := func(c *contract.Artifact, args ...interface{}) (common.Address, error) {
+		ctorArgs, err := c.ABI.Pack("", args...)
+		if err != nil {
+			return common.Address{}, errors.Wrap(err, "failed to pack constructor arguments")
+		}
+		data := append(append([]byte{}, c.Bytecode...), ctorArgs...)
+		addr := crypto.CreateAddress(crypto.PubkeyToAddress(t.prefundedAccount.PublicKey), t.prefundNonce)
+		txs = append(txs, t.prefundTx(nil, data))
+		return addr, nil
+	}
+
+	if t.mix.Has(TxKindERC20Transfer) || t.mix.Has(TxKindSwap) {
+		for i := 0; i < numContracts; i++ {
+			addr, err := deploy(t.token)
+			if err != nil {
+				return err
+			}
+			t.tokens = append(t.tokens, addr)
+		}
+	}
+
+	if t.mix.Has(TxKindSwap) {
+		quote, err := deploy(t.token)
+		if err != nil {
+			return err
+		}
+		t.quoteToken = quote
+
+		// every pool pairs one of the tokens with the shared quote token
+		for i := 0; i < numContracts; i++ {
+			addr, err := deploy(t.pair, t.tokens[i], t.quoteToken)
+			if err != nil {
+				return err
+			}
+			t.pools = append(t.pools, addr)
+		}
+	}
+
+	if t.mix.Has(TxKindNFTMint) {
+		for i := 0; i < numContracts; i++ {
+			addr, err := deploy(t.nft)
+			if err != nil {
+				return err
+			}
+			t.nfts = append(t.nfts, addr)
+		}
+	}
+
+	return t.mineAndConfirm(ctx, txs)
+}
+
+func (t *compositePayloadWorker) fundHolders(ctx context.Context, balance *big.Int) error {
+	numHolders := int64(len(t.holderAddrs))
+
+	// distribute half of the balance to leave a buffer for setup gas
+	perHolder := new(big.Int).Div(new(big.Int).Div(balance, big.NewInt(2)), big.NewInt(numHolders))
+	if perHolder.Sign() == 0 {
+		return fmt.Errorf("prefunded balance %s too low to fund %d holders", balance.String(), numHolders)
+	}
+
+	txs := make([]*types.Transaction, 0, numHolders)
+	for _, addr := range t.holderAddrs {
+		to := addr
+		txs = append(txs, types.MustSignNewTx(t.prefundedAccount, t.signer, &types.DynamicFeeTx{
+			ChainID:   t.chainID,
+			Nonce:     t.prefundNonce,
+			To:        &to,
+			Gas:       params.TxGas,
+			GasFeeCap: new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap),
+			GasTipCap: big.NewInt(2),
+			Value:     perHolder,
+		}))
+		t.prefundNonce++
+	}
+
+	return t.mineAndConfirm(ctx, txs)
+}
+
+func (t *compositePayloadWorker) distributeTokens(ctx context.Context) error {
+	tokens := append([]common.Address{}, t.tokens...)
+	if t.mix.Has(TxKindSwap) {
+		tokens = append(tokens, t.quoteToken)
+	}
+
+	txs := make([]*types.Transaction, 0)
+	for _, token := range tokens {
+		for start := 0; start < len(t.holderAddrs); start += mintBatchSize {
+			end := min(start+mintBatchSize, len(t.holderAddrs))
+			data, err := t.token.ABI.Pack("mintBatch", t.holderAddrs[start:end], holderTokenBalance)
+			if err != nil {
+				return errors.Wrap(err, "failed to pack mintBatch")
+			}
+			to := token
+			txs = append(txs, t.prefundTx(&to, data))
+		}
+	}
+
+	return t.mineAndConfirm(ctx, txs)
+}
+
+func (t *compositePayloadWorker) seedPools(ctx context.Context) error {
+	txs := make([]*types.Transaction, 0)
+	for i, pool := range t.pools {
+		for _, token := range []common.Address{t.tokens[i], t.quoteToken} {
+			mintData, err := t.token.ABI.Pack("mint", pool, poolTokenBalance)
+			if err != nil {
+				return errors.Wrap(err, "failed to pack mint")
+			}
+			trustData, err := t.token.ABI.Pack("setTrustedSpender", pool, true)
+			if err != nil {
+				return errors.Wrap(err, "failed to pack setTrustedSpender")
+			}
+			to := token
+			txs = append(txs, t.prefundTx(&to, mintData), t.prefundTx(&to, trustData))
+		}
+
+		syncData, err := t.pair.ABI.Pack("sync")
+		if err != nil {
+			return errors.Wrap(err, "failed to pack sync")
+		}
+		to := pool
+		txs = append(txs, t.prefundTx(&to, syncData))
+	}
+
+	if len(txs) == 0 {
+		return nil
+	}
+	return t.mineAndConfirm(ctx, txs)
+}
+
+func (t *compositePayloadWorker) fetchHolderNonces(ctx context.Context) error {
+	batchElems := make([]rpc.BatchElem, 0, len(t.holderAddrs))
+	for _, addr := range t.holderAddrs {
+		batchElems = append(batchElems, rpc.BatchElem{
+			Method: "eth_getTransactionCount",
+			Args:   []interface{}{addr, "latest"},
+			Result: new(string),
+		})
+	}
+
+	if err := t.client.Client().BatchCallContext(ctx, batchElems); err != nil {
+		return errors.Wrap(err, "failed to fetch holder nonces")
+	}
+
+	for i, elem := range batchElems {
+		if elem.Error != nil {
+			return errors.Wrapf(elem.Error, "failed to fetch nonce for %s", t.holderAddrs[i].Hex())
+		}
+		nonce, err := hexutil.DecodeUint64(*elem.Result.(*string))
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode nonce for %s", t.holderAddrs[i].Hex())
+		}
+		t.nextNonce[t.holderAddrs[i]] = nonce
+	}
+	return nil
+}
+
+// estimateGas estimates the gas used by one call of each enabled kind, so
+// blocks can be filled by expected gas rather than gas limits.
+func (t *compositePayloadWorker) estimateGas(ctx context.Context) {
+	from := t.holderAddrs[0]
+	for _, kind := range []TxKind{TxKindERC20Transfer, TxKindSwap, TxKindNFTMint} {
+		if !t.mix.Has(kind) {
+			continue
+		}
+		t.gasEstimates[kind] = defaultGasEstimates[kind]
+
+		to, data, err := t.callFor(kind, from)
+		if err != nil {
+			t.log.Warn("Failed to build call for gas estimation", "kind", kind, "err", err)
+			continue
+		}
+		estimate, err := t.client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Data: data})
+		if err != nil {
+			t.log.Warn("Failed to estimate gas, using default", "kind", kind, "default", t.gasEstimates[kind], "err", err)
+			continue
+		}
+		t.gasEstimates[kind] = estimate
+	}
+}
+
+// callFor picks the target contract and calldata for a transaction of the
+// given kind sent by from.
+func (t *compositePayloadWorker) callFor(kind TxKind, from common.Address) (common.Address, []byte, error) {
+	switch kind {
+	case TxKindERC20Transfer:
+		token := t.tokens[t.contractSampler.Next()]
+		to := t.holderAddrs[t.holderSampler.Next()]
+		amount := big.NewInt(1 + t.rng.Int63n(1e15))
+		data, err := t.token.ABI.Pack("transfer", to, amount)
+		return token, data, err
+	case TxKindSwap:
+		pool := t.pools[t.contractSampler.Next()]
+		zeroForOne := t.rng.Intn(2) == 0
+		amountIn := big.NewInt(1e12 + t.rng.Int63n(1e15))
+		data, err := t.pair.ABI.Pack("swap", zeroForOne, amountIn, big.NewInt(0), from)
+		return pool, data, err
+	case TxKindNFTMint:
+		nft := t.nfts[t.contractSampler.Next()]
+		to := t.holderAddrs[t.holderSampler.Next()]
+		data, err := t.nft.ABI.Pack("mint", to)
+		return nft, data, err
+	default:
+		return common.Address{}, nil, fmt.Errorf("unknown transaction kind %s", kind)
+	}
+}
+
+// gasLimitFor returns the gas limit for a transaction of the given kind,
+// leaving headroom over the estimate for cold storage access.
+func (t *compositePayloadWorker) gasLimitFor(kind TxKind) uint64 {
+	return t.gasEstimates[kind] * 3 / 2
+}
+
+func (t *compositePayloadWorker) prefundTx(to *common.Address, data []byte) *types.Transaction {
+	tx := types.MustSignNewTx(t.prefundedAccount, t.signer, &types.DynamicFeeTx{
+		ChainID:   t.chainID,
+		Nonce:     t.prefundNonce,
+		To:        to,
+		Gas:       setupTxGas,
+		GasFeeCap: new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap),
+		GasTipCap: big.NewInt(2),
+		Value:     big.NewInt(0),
+		Data:      data,
+	})
+	t.prefundNonce++
+	return tx
+}
+
+func (t *compositePayloadWorker) mineAndConfirm(ctx context.Context, txs []*types.Transaction) error {
+	for len(txs) > 0 {
+		batch := txs
+		if len(batch) > mineAndConfirmBatchSize {
+			batch = txs[:mineAndConfirmBatchSize]
+		}
+		txs = txs[len(batch):]
+
+		t.mempool.AddTransactions(batch)
+
+		// Wait for the last transaction first so the rest are already mined
+		// when their receipts are checked.
+		for i := len(batch) - 1; i >= 0; i-- {
+			receipt, err := t.waitForReceipt(ctx, batch[i].Hash())
+			if err != nil {
+				return errors.Wrap(err, "failed to wait for receipt")
+			}
+
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return fmt.Errorf("receipt status not successful for tx %s: %d", batch[i].Hash(), receipt.Status)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *compositePayloadWorker) waitForReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return retry.Do(ctx, 60, retry.Fixed(1*time.Second), func() (*types.Receipt, error) {
+		receipt, err := t.client.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			return nil, err
+		}
+		return receipt, nil
+	})
+}
+
+// averageGas is the expected gas of a transaction drawn from the mix.
+func (t *compositePayloadWorker) averageGas() uint64 {
+	weights := t.mix.weights()
+	total, weighted := 0.0, 0.0
+	for kind, estimate := range t.gasEstimates {
+		total += weights[kind]
+		weighted += weights[kind] * float64(estimate)
+	}
+	if total == 0 {
+		return params.TxGas
+	}
+	return uint64(weighted / total)
+}
+
+func (t *compositePayloadWorker) sendTxs(pendingTxs int) (int, error) {
+	budget := t.params.GasLimit - gasBuffer
+
+	// Account for gas that pending transactions (still in the node mempool) will consume.
+	gasUsed := uint64(pendingTxs) * t.averageGas()
+
+	txs := make([]*types.Transaction, 0)
+	counts := make(map[TxKind]int)
+
+	for {
+		kind := t.picker.Next()
+		if gasUsed+t.gasEstimates[kind] > budget {
+			break
+		}
+
+		key := t.holderKeys[t.currHolder]
+		from := t.holderAddrs[t.currHolder]
+		t.currHolder = (t.currHolder + 1) % len(t.holderKeys)
+
+		to, data, err := t.callFor(kind, from)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to build %s call", kind)
+		}
+
+		tx := types.MustSignNewTx(key, t.signer, &types.DynamicFeeTx{
+			ChainID:   t.chainID,
+			Nonce:     t.nextNonce[from],
+			To:        &to,
+			Gas:       t.gasLimitFor(kind),
+			GasFeeCap: new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap),
+			GasTipCap: big.NewInt(2),
+			Value:     big.NewInt(0),
+			Data:      data,
+		})
+		t.nextNonce[from]++
+
+		txs = append(txs, tx)
+		gasUsed += t.gasEstimates[kind]
+		counts[kind]++
+	}
+
+	t.mempool.AddTransactions(txs)
+	t.log.Debug("Sent composite transactions", "total", len(txs),
+		"erc20_transfer", counts[TxKindERC20Transfer], "swap", counts[TxKindSwap], "nft_mint", counts[TxKindNFTMint])
+	return len(txs), nil
+}
+
+func (t *compositePayloadWorker) SendTxs(ctx context.Context, pendingTxs int) (int, error) {
+	n, err := t.sendTxs(pendingTxs)
+	if err != nil {
+		t.log.Error("Failed to send transactions", "err", err)
+		return 0, err
+	}
+	return n, nil
+}
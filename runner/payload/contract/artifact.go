@@ -0,0 +1,156 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ArtifactsDir is the directory, relative to the working directory, that
+// contracts built in this repository are read from.
+const ArtifactsDir = "contracts/out"
+
+// Artifact is a compiled contract ready to be deployed.
+type Artifact struct {
+	// ABI is nil when the contract was given as raw bytecode without an ABI.
+	ABI      *abi.ABI
+	Bytecode []byte
+}
+
+// artifactFile covers both Foundry artifacts, where bytecode is an object
+// with an "object" field, and Hardhat artifacts, where it is a hex string.
+type artifactFile struct {
+	ABI      json.RawMessage `json:"abi"`
+	Bytecode json.RawMessage `json:"bytecode"`
+}
+
+type foundryBytecode struct {
+	Object string `json:"object"`
+}
+
+// LoadArtifact resolves a contract reference to an artifact. The reference
+// may be raw hex bytecode, a path to a Foundry or Hardhat artifact JSON file
+// (absolute or relative to the working directory), or the name of a contract
+// built in ArtifactsDir.
+func LoadArtifact(ref string) (*Artifact, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("contract bytecode is not set")
+	}
+
+	if strings.HasPrefix(ref, "0x") {
+		bytecode, err := hexutil.Decode(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode contract bytecode: %w", err)
+		}
+		return &Artifact{Bytecode: bytecode}, nil
+	}
+
+	artifactPath := ref
+	if !strings.HasSuffix(ref, ".json") && !strings.ContainsRune(ref, filepath.Separator) {
+		artifactPath = filepath.Join(ArtifactsDir, ref+".sol", ref+".json")
+	}
+
+	data, err := os.ReadFile(artifactPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contract artifact %s: %w", artifactPath, err)
+	}
+
+	artifact, err := parseArtifact(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract artifact %s: %w", artifactPath, err)
+	}
+	return artifact, nil
+}
+
+func parseArtifact(data []byte) (*Artifact, error) {
+	var file artifactFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal artifact: %w", err)
+	}
+
+	var bytecodeHex string
+	if err := json.Unmarshal(file.Bytecode, &bytecodeHex); err != nil {
+		var foundry foundryBytecode
+		if err := json.Unmarshal(file.Bytecode, &foundry); err != nil {
+			return nil, fmt.Errorf("unrecognized bytecode format")
+		}
+		bytecodeHex = foundry.Object
+	}
+	if bytecodeHex == "" || bytecodeHex == "0x" {
+		return nil, fmt.Errorf("artifact has no bytecode")
+	}
+
+	bytecode, err := hexutil.Decode(bytecodeHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bytecode: %w", err)
+	}
+
+	artifact := &Artifact{Bytecode: bytecode}
+	if len(file.ABI) > 0 && string(file.ABI) != "null" {
+		parsed, err := abi.JSON(bytes.NewReader(file.ABI))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse abi: %w", err)
+		}
+		artifact.ABI = &parsed
+	}
+	return artifact, nil
+}
+
+// parseInlineABI parses an ABI given either as a JSON string or as a YAML
+// list of ABI entries.
+func parseInlineABI(value interface{}) (*abi.ABI, error) {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("failed to encode inline abi: %w", err)
+		}
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse inline abi: %w", err)
+	}
+	return &parsed, nil
+}
+
+// DeployData returns the contract creation data: the bytecode followed by
+// the ABI-encoded constructor arguments.
+func (a *Artifact) DeployData(args []string) ([]byte, error) {
+	data := append([]byte{}, a.Bytecode...)
+	if len(args) == 0 {
+		return data, nil
+	}
+	if a.ABI == nil {
+		return nil, fmt.Errorf("constructor arguments require an abi")
+	}
+
+	inputs := a.ABI.Constructor.Inputs
+	if len(inputs) != len(args) {
+		return nil, fmt.Errorf("constructor takes %d arguments but %d were given", len(inputs), len(args))
+	}
+
+	values := make([]interface{}, len(args))
+	for i, input := range inputs {
+		v, err := parseStaticValue(input.Type, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid constructor argument %d (%s): %w", i, input.Type.String(), err)
+		}
+		values[i] = v
+	}
+
+	packed, err := inputs.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack constructor arguments: %w", err)
+	}
+	return append(data, packed...), nil
+}
@@ -0,0 +1,106 @@
+package contract
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const testConstructorABI = `[{"type":"constructor","inputs":[{"name":"owner","type":"address"},{"name":"supply","type":"uint256"}],"stateMutability":"nonpayable"}]`
+
+func writeArtifact(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Artifact.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadArtifactRawHex(t *testing.T) {
+	artifact, err := LoadArtifact("0x6001600101")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x60, 0x01, 0x60, 0x01, 0x01}, artifact.Bytecode)
+	require.Nil(t, artifact.ABI)
+
+	_, err = LoadArtifact("0xzz")
+	require.Error(t, err)
+}
+
+func TestLoadArtifactFoundry(t *testing.T) {
+	path := writeArtifact(t, `{"abi":`+testConstructorABI+`,"bytecode":{"object":"0x6001"}}`)
+
+	artifact, err := LoadArtifact(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x60, 0x01}, artifact.Bytecode)
+	require.NotNil(t, artifact.ABI)
+	require.Len(t, artifact.ABI.Constructor.Inputs, 2)
+}
+
+func TestLoadArtifactHardhat(t *testing.T) {
+	path := writeArtifact(t, `{"contractName":"Test","abi":[],"bytecode":"0x6002","deployedBytecode":"0x"}`)
+
+	artifact, err := LoadArtifact(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x60, 0x02}, artifact.Bytecode)
+}
+
+func TestLoadArtifactErrors(t *testing.T) {
+	_, err := LoadArtifact("")
+	require.Error(t, err)
+
+	_, err = LoadArtifact(writeArtifact(t, `{"abi":[],"bytecode":{"object":"0x"}}`))
+	require.ErrorContains(t, err, "no bytecode")
+
+	_, err = LoadArtifact(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestParseInlineABI(t *testing.T) {
+	parsed, err := parseInlineABI(testConstructorABI)
+	require.NoError(t, err)
+	require.Len(t, parsed.Constructor.Inputs, 2)
+
+	// As decoded from a YAML list.
+	parsed, err = parseInlineABI([]interface{}{
+		map[string]interface{}{
+			"type":   "function",
+			"name":   "set",
+			"inputs": []interface{}{map[string]interface{}{"name": "v", "type": "uint256"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Contains(t, parsed.Methods, "set")
+}
+
+func TestDeployDataConstructorArgs(t *testing.T) {
+	parsed, err := parseInlineABI(testConstructorABI)
+	require.NoError(t, err)
+	artifact := &Artifact{ABI: parsed, Bytecode: []byte{0x60, 0x01}}
+
+	owner := "0x00000000000000000000000000000000000000aa"
+	data, err := artifact.DeployData([]string{owner, "1000"})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x60, 0x01}, data[:2])
+
+	values, err := parsed.Constructor.Inputs.Unpack(data[2:])
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(owner), values[0])
+	require.Equal(t, big.NewInt(1000), values[1])
+
+	// The artifact bytecode must not be modified.
+	require.Equal(t, []byte{0x60, 0x01}, artifact.Bytecode)
+
+	_, err = artifact.DeployData([]string{owner})
+	require.Error(t, err)
+
+	_, err = (&Artifact{Bytecode: []byte{0x60}}).DeployData([]string{"1"})
+	require.ErrorContains(t, err, "require an abi")
+
+	data, err = (&Artifact{ABI: &abi.ABI{}, Bytecode: []byte{0x60}}).DeployData(nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x60}, data)
+}
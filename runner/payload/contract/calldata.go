@@ -0,0 +1,358 @@
+package contract
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Calldata generator names accepted in CalldataArgDefinition.Generator.
+const (
+	GeneratorStatic      = "static"
+	GeneratorCounter     = "counter"
+	GeneratorAddressPool = "address_pool"
+	GeneratorUintRange   = "uint_range"
+	GeneratorSender      = "sender"
+)
+
+const defaultAddressPoolSize = 1000
+
+// ContractCallDefinition is one function call in a contract payload. Calls are
+// picked per transaction in proportion to their weight.
+type ContractCallDefinition struct {
+	FunctionSignature string                  `yaml:"function_signature"`
+	Weight            *float64                `yaml:"weight"`
+	GasLimit          uint64                  `yaml:"gas_limit"`
+	Args              []CalldataArgDefinition `yaml:"args"`
+}
+
+// CalldataArgDefinition describes how to produce one argument of a call.
+type CalldataArgDefinition struct {
+	// Generator is one of static, counter, address_pool, uint_range or
+	// sender. Defaults to static.
+	Generator string `yaml:"generator"`
+	// Value is the literal value for static arguments.
+	Value string `yaml:"value"`
+	// Start and Step configure counter arguments.
+	Start string `yaml:"start"`
+	Step  string `yaml:"step"`
+	// Min and Max bound uint_range arguments (inclusive).
+	Min string `yaml:"min"`
+	Max string `yaml:"max"`
+	// PoolSize is the number of distinct addresses for address_pool arguments.
+	PoolSize int `yaml:"pool_size"`
+}
+
+// callContext carries per-transaction values available to generators.
+type callContext struct {
+	sender common.Address
+}
+
+// callTemplate produces calldata for a single function.
+type callTemplate struct {
+	selector   []byte
+	arguments  abi.Arguments
+	generators []func(ctx callContext) (interface{}, error)
+	weight     float64
+	gasLimit   uint64
+}
+
+// callSelector picks call templates by weight.
+type callSelector struct {
+	templates  []*callTemplate
+	cumulative []float64
+	rng        *rand.Rand
+}
+
+// newCallSelector compiles the call definitions into templates.
+func newCallSelector(defs []ContractCallDefinition, rng *rand.Rand) (*callSelector, error) {
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("at least one call is required")
+	}
+
+	s := &callSelector{rng: rng}
+	total := 0.0
+	for i, def := range defs {
+		tmpl, err := newCallTemplate(def, rng)
+		if err != nil {
+			return nil, fmt.Errorf("invalid call %d (%s): %w", i, def.FunctionSignature, err)
+		}
+		total += tmpl.weight
+		s.templates = append(s.templates, tmpl)
+		s.cumulative = append(s.cumulative, total)
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("at least one call must have a positive weight")
+	}
+	for i := range s.cumulative {
+		s.cumulative[i] /= total
+	}
+	return s, nil
+}
+
+func (s *callSelector) next() *callTemplate {
+	r := s.rng.Float64()
+	for i, c := range s.cumulative {
+		if r < c {
+			return s.templates[i]
+		}
+	}
+	return s.templates[len(s.templates)-1]
+}
+
+func newCallTemplate(def ContractCallDefinition, rng *rand.Rand) (*callTemplate, error) {
+	argTypes, err := parseSignatureTypes(def.FunctionSignature)
+	if err != nil {
+		return nil, err
+	}
+	if len(argTypes) != len(def.Args) {
+		return nil, fmt.Errorf("signature has %d arguments but %d were configured", len(argTypes), len(def.Args))
+	}
+
+	weight := 1.0
+	if def.Weight != nil {
+		weight = *def.Weight
+	}
+	if weight < 0 {
+		return nil, fmt.Errorf("weight must not be negative")
+	}
+
+	tmpl := &callTemplate{
+		selector: crypto.Keccak256([]byte(def.FunctionSignature))[:4],
+		weight:   weight,
+		gasLimit: def.GasLimit,
+	}
+
+	for i, typeName := range argTypes {
+		typ, err := abi.NewType(typeName, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported argument type %s: %w", typeName, err)
+		}
+		gen, err := newArgGenerator(typ, def.Args[i], rng)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %w", i, typeName, err)
+		}
+		tmpl.arguments = append(tmpl.arguments, abi.Argument{Type: typ})
+		tmpl.generators = append(tmpl.generators, gen)
+	}
+
+	return tmpl, nil
+}
+
+// calldata generates the calldata for a call sent by the given sender.
+func (c *callTemplate) calldata(ctx callContext) ([]byte, error) {
+	values := make([]interface{}, len(c.generators))
+	for i, gen := range c.generators {
+		v, err := gen(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	packed, err := c.arguments.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack arguments: %w", err)
+	}
+	return append(append([]byte{}, c.selector...), packed...), nil
+}
+
+// parseSignatureTypes returns the argument types of a signature like
+// "transfer(address,uint256)". Tuple arguments are not supported.
+func parseSignatureTypes(signature string) ([]string, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return nil, fmt.Errorf("invalid function signature %q", signature)
+	}
+	inner := signature[open+1 : len(signature)-1]
+	if strings.ContainsAny(inner, "() ") {
+		return nil, fmt.Errorf("function signature %q must not contain tuples or spaces", signature)
+	}
+	if inner == "" {
+		return nil, nil
+	}
+	return strings.Split(inner, ","), nil
+}
+
+func newArgGenerator(typ abi.Type, def CalldataArgDefinition, rng *rand.Rand) (func(ctx callContext) (interface{}, error), error) {
+	switch def.Generator {
+	case "", GeneratorStatic:
+		v, err := parseStaticValue(typ, def.Value)
+		if err != nil {
+			return nil, err
+		}
+		return func(callContext) (interface{}, error) { return v, nil }, nil
+
+	case GeneratorCounter:
+		if !isIntegerType(typ) {
+			return nil, fmt.Errorf("counter requires an integer type")
+		}
+		start, err := parseBigOrDefault(def.Start, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start: %w", err)
+		}
+		step, err := parseBigOrDefault(def.Step, 1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid step: %w", err)
+		}
+		next := new(big.Int).Set(start)
+		return func(callContext) (interface{}, error) {
+			v := new(big.Int).Set(next)
+			next.Add(next, step)
+			return integerValue(typ, v)
+		}, nil
+
+	case GeneratorUintRange:
+		if !isIntegerType(typ) {
+			return nil, fmt.Errorf("uint_range requires an integer type")
+		}
+		lo, err := parseBigOrDefault(def.Min, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min: %w", err)
+		}
+		if def.Max == "" {
+			return nil, fmt.Errorf("uint_range requires max")
+		}
+		hi, err := parseBig(def.Max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max: %w", err)
+		}
+		if hi.Cmp(lo) < 0 {
+			return nil, fmt.Errorf("max %s is less than min %s", hi, lo)
+		}
+		span := new(big.Int).Add(new(big.Int).Sub(hi, lo), big.NewInt(1))
+		return func(callContext) (interface{}, error) {
+			v := new(big.Int).Rand(rng, span)
+			return integerValue(typ, v.Add(v, lo))
+		}, nil
+
+	case GeneratorAddressPool:
+		if typ.T != abi.AddressTy {
+			return nil, fmt.Errorf("address_pool requires an address type")
+		}
+		size := def.PoolSize
+		if size == 0 {
+			size = defaultAddressPoolSize
+		}
+		if size < 0 {
+			return nil, fmt.Errorf("pool_size must be positive")
+		}
+		pool := make([]common.Address, size)
+		for i := range pool {
+			rng.Read(pool[i][:])
+		}
+		return func(callContext) (interface{}, error) {
+			return pool[rng.Intn(len(pool))], nil
+		}, nil
+
+	case GeneratorSender:
+		switch {
+		case typ.T == abi.AddressTy:
+			return func(ctx callContext) (interface{}, error) { return ctx.sender, nil }, nil
+		case isIntegerType(typ):
+			return func(ctx callContext) (interface{}, error) {
+				return integerValue(typ, new(big.Int).SetBytes(ctx.sender.Bytes()))
+			}, nil
+		case typ.T == abi.FixedBytesTy && typ.Size == 32:
+			return func(ctx callContext) (interface{}, error) {
+				return common.BytesToHash(ctx.sender.Bytes()), nil
+			}, nil
+		default:
+			return nil, fmt.Errorf("sender requires an address, integer or bytes32 type")
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown generator %q", def.Generator)
+	}
+}
+
+func isIntegerType(typ abi.Type) bool {
+	return typ.T == abi.UintTy || typ.T == abi.IntTy
+}
+
+// integerValue converts v to the Go type the abi package expects for typ,
+// truncating unsigned values to the type's width.
+func integerValue(typ abi.Type, v *big.Int) (interface{}, error) {
+	if typ.T == abi.UintTy && typ.Size < 256 {
+		mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(typ.Size)), big.NewInt(1))
+		v = new(big.Int).And(v, mask)
+	}
+	goType := typ.GetType()
+	if goType == reflect.TypeOf(&big.Int{}) {
+		return v, nil
+	}
+	if typ.T == abi.UintTy {
+		return reflect.ValueOf(v.Uint64()).Convert(goType).Interface(), nil
+	}
+	return reflect.ValueOf(v.Int64()).Convert(goType).Interface(), nil
+}
+
+func parseStaticValue(typ abi.Type, value string) (interface{}, error) {
+	switch typ.T {
+	case abi.UintTy, abi.IntTy:
+		v, err := parseBigOrDefault(value, 0)
+		if err != nil {
+			return nil, err
+		}
+		return integerValue(typ, v)
+	case abi.AddressTy:
+		if value != "" && !common.IsHexAddress(value) {
+			return nil, fmt.Errorf("invalid address %q", value)
+		}
+		return common.HexToAddress(value), nil
+	case abi.BoolTy:
+		switch value {
+		case "", "false":
+			return false, nil
+		case "true":
+			return true, nil
+		default:
+			return nil, fmt.Errorf("invalid bool %q", value)
+		}
+	case abi.StringTy:
+		return value, nil
+	case abi.BytesTy:
+		if value == "" {
+			return []byte{}, nil
+		}
+		return hexutil.Decode(value)
+	case abi.FixedBytesTy:
+		b := []byte{}
+		if value != "" {
+			var err error
+			if b, err = hexutil.Decode(value); err != nil {
+				return nil, err
+			}
+		}
+		if len(b) > typ.Size {
+			return nil, fmt.Errorf("value has %d bytes, more than bytes%d", len(b), typ.Size)
+		}
+		arr := reflect.New(typ.GetType()).Elem()
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr.Interface(), nil
+	default:
+		return nil, fmt.Errorf("static values are not supported for %s", typ.String())
+	}
+}
+
+func parseBig(value string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(value, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", value)
+	}
+	return v, nil
+}
+
+func parseBigOrDefault(value string, def int64) (*big.Int, error) {
+	if value == "" {
+		return big.NewInt(def), nil
+	}
+	return parseBig(value)
+}
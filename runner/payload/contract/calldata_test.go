@@ -0,0 +1,119 @@
+package contract
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func unpackArgs(t *testing.T, signature string, data []byte) []interface{} {
+	t.Helper()
+	require.Equal(t, crypto.Keccak256([]byte(signature))[:4], data[:4])
+
+	typeNames, err := parseSignatureTypes(signature)
+	require.NoError(t, err)
+	args := make(abi.Arguments, len(typeNames))
+	for i, name := range typeNames {
+		typ, err := abi.NewType(name, "", nil)
+		require.NoError(t, err)
+		args[i] = abi.Argument{Type: typ}
+	}
+	values, err := args.Unpack(data[4:])
+	require.NoError(t, err)
+	return values
+}
+
+func TestParseSignatureTypes(t *testing.T) {
+	types, err := parseSignatureTypes("transfer(address,uint256)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"address", "uint256"}, types)
+
+	types, err = parseSignatureTypes("increment()")
+	require.NoError(t, err)
+	require.Empty(t, types)
+
+	_, err = parseSignatureTypes("transfer")
+	require.Error(t, err)
+	_, err = parseSignatureTypes("swap((address,uint256))")
+	require.Error(t, err)
+}
+
+func TestCallTemplateGenerators(t *testing.T) {
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tmpl, err := newCallTemplate(ContractCallDefinition{
+		FunctionSignature: "store(uint256,uint64,address,uint256,address,bytes)",
+		Args: []CalldataArgDefinition{
+			{Generator: GeneratorCounter, Start: "10", Step: "2"},
+			{Generator: GeneratorUintRange, Min: "5", Max: "7"},
+			{Generator: GeneratorAddressPool, PoolSize: 3},
+			{Generator: GeneratorSender},
+			{Generator: GeneratorSender},
+			{Value: "0x1234"},
+		},
+	}, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	pool := make(map[common.Address]bool)
+	for i := 0; i < 50; i++ {
+		data, err := tmpl.calldata(callContext{sender: sender})
+		require.NoError(t, err)
+		values := unpackArgs(t, "store(uint256,uint64,address,uint256,address,bytes)", data)
+
+		require.Equal(t, big.NewInt(int64(10+2*i)), values[0])
+		require.GreaterOrEqual(t, values[1].(uint64), uint64(5))
+		require.LessOrEqual(t, values[1].(uint64), uint64(7))
+		pool[values[2].(common.Address)] = true
+		require.Equal(t, new(big.Int).SetBytes(sender.Bytes()), values[3])
+		require.Equal(t, sender, values[4])
+		require.Equal(t, []byte{0x12, 0x34}, values[5])
+	}
+	require.LessOrEqual(t, len(pool), 3)
+	require.Greater(t, len(pool), 1)
+}
+
+func TestCallTemplateRejectsInvalidArgs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tests := []ContractCallDefinition{
+		{FunctionSignature: "f(uint256)"},
+		{FunctionSignature: "f(address)", Args: []CalldataArgDefinition{{Generator: GeneratorCounter}}},
+		{FunctionSignature: "f(uint256)", Args: []CalldataArgDefinition{{Generator: GeneratorAddressPool}}},
+		{FunctionSignature: "f(uint256)", Args: []CalldataArgDefinition{{Generator: GeneratorUintRange, Min: "5"}}},
+		{FunctionSignature: "f(uint256)", Args: []CalldataArgDefinition{{Generator: GeneratorUintRange, Min: "5", Max: "1"}}},
+		{FunctionSignature: "f(uint256)", Args: []CalldataArgDefinition{{Generator: "unknown"}}},
+		{FunctionSignature: "f(address)", Args: []CalldataArgDefinition{{Value: "not-an-address"}}},
+	}
+	for _, def := range tests {
+		_, err := newCallTemplate(def, rng)
+		require.Error(t, err, def)
+	}
+}
+
+func TestCallSelectorWeights(t *testing.T) {
+	heavy, light := 3.0, 1.0
+	selector, err := newCallSelector([]ContractCallDefinition{
+		{FunctionSignature: "heavy()", Weight: &heavy},
+		{FunctionSignature: "light()", Weight: &light},
+	}, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+
+	heavySelector := crypto.Keccak256([]byte("heavy()"))[:4]
+	const samples = 10_000
+	heavyCount := 0
+	for i := 0; i < samples; i++ {
+		data, err := selector.next().calldata(callContext{})
+		require.NoError(t, err)
+		if string(data[:4]) == string(heavySelector) {
+			heavyCount++
+		}
+	}
+	require.InDelta(t, 0.75, float64(heavyCount)/samples, 0.02)
+
+	zero := 0.0
+	_, err = newCallSelector([]ContractCallDefinition{{FunctionSignature: "f()", Weight: &zero}}, rand.New(rand.NewSource(1)))
+	require.Error(t, err)
+}
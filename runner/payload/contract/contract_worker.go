@@ -3,12 +3,9 @@ package contract
 import (
 	"context"
 	"crypto/ecdsa"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"math/big"
-	"os"
-	"path/filepath"
+	"math/rand"
 	"time"
 
 	"github.com/base/base-bench/runner/config"
@@ -28,20 +25,34 @@ import (
 )
 
 type ContractPayloadDefinition struct {
-	ContractBytecode  string `yaml:"contract_bytecode"`
+	// ContractBytecode is raw hex bytecode, a path to a Foundry or Hardhat
+	// artifact, or the name of a contract in contracts/out.
+	ContractBytecode string `yaml:"contract_bytecode"`
+	// ContractABI is an inline ABI, either a JSON string or a YAML list. It
+	// overrides any ABI found in the artifact.
+	ContractABI interface{} `yaml:"contract_abi"`
+	// ConstructorArgs are encoded with the constructor inputs of the ABI and
+	// appended to the bytecode on deployment.
+	ConstructorArgs []string `yaml:"constructor_args"`
+	// DeployGasLimit is the gas limit of the deployment transaction.
+	DeployGasLimit uint64 `yaml:"deploy_gas_limit"`
+
 	FunctionSignature string `yaml:"function_signature"`
 	GasPerTx          string `yaml:"gas_per_tx"`
 	Calldata          string `yaml:"calldata"`
 	CallsPerBlock     int    `yaml:"calls_per_block"`
-}
 
-type Bytecode struct {
-	Object string `json:"object"`
+	// Calls, if set, replaces FunctionSignature/GasPerTx/Calldata with one or
+	// more weighted calls whose arguments are produced by generators.
+	Calls []ContractCallDefinition `yaml:"calls"`
+	// Seed seeds the calldata generators so runs are reproducible.
+	Seed *int64 `yaml:"seed"`
 }
 
-type Contract struct {
-	Bytecode Bytecode `json:"bytecode"`
-}
+const (
+	defaultCalldataSeed   = 100
+	defaultDeployGasLimit = 2000000
+)
 
 type contractPayloadWorker struct {
 	log log.Logger
@@ -61,6 +72,9 @@ type contractPayloadWorker struct {
 
 	config   config.Config
 	bytecode []byte
+
+	// calls is set when the payload defines weighted calls with generators.
+	calls *callSelector
 }
 
 func NewContractPayloadWorker(log log.Logger, elRPCURL string, runParams benchtypes.RunParams, prefundedPrivateKey ecdsa.PrivateKey, prefundAmount *big.Int, genesis *core.Genesis, config config.Config, params interface{}) (worker.Worker, error) {
@@ -82,22 +96,34 @@ func NewContractPayloadWorker(log log.Logger, elRPCURL string, runParams benchty
 		return nil, fmt.Errorf("invalid contract transaction payload: %#v", params)
 	}
 
-	bytecodeFile := payloadConfig.ContractBytecode
-
-	bytecodePath := filepath.Join("contracts/out/" + bytecodeFile + ".sol/" + bytecodeFile + ".json")
-	data, err := os.ReadFile(bytecodePath)
+	artifact, err := LoadArtifact(payloadConfig.ContractBytecode)
 	if err != nil {
-		return nil, errors.New("failed to read bytecode file")
+		return nil, err
 	}
 
-	var c Contract
-	if err := json.Unmarshal(data, &c); err != nil {
-		return nil, errors.New("failed to unmarshal bytecode file")
+	if payloadConfig.ContractABI != nil {
+		artifact.ABI, err = parseInlineABI(payloadConfig.ContractABI)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	bytecodeHex := c.Bytecode.Object
+	bytecode, err := artifact.DeployData(payloadConfig.ConstructorArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode contract deployment: %w", err)
+	}
 
-	bytecode := common.FromHex(string(bytecodeHex))
+	var calls *callSelector
+	if len(payloadConfig.Calls) > 0 {
+		seed := int64(defaultCalldataSeed)
+		if payloadConfig.Seed != nil {
+			seed = *payloadConfig.Seed
+		}
+		calls, err = newCallSelector(payloadConfig.Calls, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract calls: %w", err)
+		}
+	}
 
 	t := &contractPayloadWorker{
 		log:              log,
@@ -110,6 +136,7 @@ func NewContractPayloadWorker(log log.Logger, elRPCURL string, runParams benchty
 		params:           *payloadConfig,
 		config:           config,
 		bytecode:         bytecode,
+		calls:            calls,
 	}
 
 	return t, nil
@@ -129,7 +156,10 @@ func (t *contractPayloadWorker) deployContract(ctx context.Context) error {
 	nonce := t.mempool.GetTransactionCount(address)
 	t.nonce = nonce
 
-	var gasLimit uint64 = 2000000
+	gasLimit := t.params.DeployGasLimit
+	if gasLimit == 0 {
+		gasLimit = defaultDeployGasLimit
+	}
 
 	gasPrice, err := t.client.SuggestGasPrice(ctx)
 	if err != nil {
@@ -223,22 +253,64 @@ func (t *contractPayloadWorker) sendContractTx(ctx context.Context) error {
 	}
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
-	nonce, err := t.client.PendingNonceAt(context.Background(), fromAddress)
-	if err != nil {
-		return fmt.Errorf("failed to get nonce: %w", err)
-	}
+	// Transactions stay in the local mempool until the next block is built, so
+	// the node's pending nonce does not account for calls queued this block.
+	nonce := t.nonce
 
 	gasLimit := new(big.Int).Mul(big.NewInt(int64(t.runParams.GasLimit)), big.NewInt(95))
 	gasLimit = gasLimit.Div(gasLimit, big.NewInt(int64(t.params.CallsPerBlock)))
 	gasLimit = gasLimit.Div(gasLimit, big.NewInt(100))
 
+	var data []byte
+	var err error
+	if t.calls != nil {
+		call := t.calls.next()
+		data, err = call.calldata(callContext{sender: fromAddress})
+		if err != nil {
+			return fmt.Errorf("failed to generate calldata: %w", err)
+		}
+		if call.gasLimit > 0 {
+			gasLimit = new(big.Int).SetUint64(call.gasLimit)
+		}
+	} else {
+		data, err = t.staticCalldata()
+		if err != nil {
+			return err
+		}
+	}
+
+	gasTipCap := big.NewInt(1)
+	gasFeeCap := new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap)
+
+	txdata := &types.DynamicFeeTx{
+		Nonce:     nonce,
+		Gas:       gasLimit.Uint64(),
+		To:        &contractAddress,
+		Value:     big.NewInt(0),
+		Data:      data,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		ChainID:   t.chainID,
+	}
+
+	signer := types.NewPragueSigner(new(big.Int).SetUint64(t.chainID.Uint64()))
+	tx := types.MustSignNewTx(privateKey, signer, txdata)
+
+	t.mempool.AddTransactions([]*types.Transaction{tx})
+	t.nonce++
+
+	return nil
+}
+
+// staticCalldata encodes the legacy single-function call with (gas_per_tx, calldata) arguments.
+func (t *contractPayloadWorker) staticCalldata() ([]byte, error) {
 	funcSelector := crypto.Keccak256([]byte(t.params.FunctionSignature))[:4]
 
 	value := new(big.Int)
 	value, success := value.SetString(t.params.GasPerTx, 10)
 
 	if !success {
-		return fmt.Errorf("failed to parse gas per tx as big.Int: %s", t.params.GasPerTx)
+		return nil, fmt.Errorf("failed to parse gas per tx as big.Int: %s", t.params.GasPerTx)
 	}
 
 	bytesHex := t.params.Calldata
@@ -247,7 +319,7 @@ func (t *contractPayloadWorker) sendContractTx(ctx context.Context) error {
 	}
 	bytesData, err := hexutil.Decode(bytesHex)
 	if err != nil {
-		return fmt.Errorf("failed to decode calldata: %w", err)
+		return nil, fmt.Errorf("failed to decode calldata: %w", err)
 	}
 
 	uint256Type, _ := abi.NewType("uint256", "", nil)
@@ -262,28 +334,7 @@ func (t *contractPayloadWorker) sendContractTx(ctx context.Context) error {
 		},
 	}
 	packedArgs, _ := arguments.Pack(value, bytesData)
-	data := append(funcSelector, packedArgs...)
-
-	gasTipCap := big.NewInt(1)
-	baseFee := big.NewInt(1e9)
-
-	txdata := &types.DynamicFeeTx{
-		Nonce:     nonce,
-		Gas:       gasLimit.Uint64(),
-		To:        &contractAddress,
-		Value:     big.NewInt(0),
-		Data:      data,
-		GasFeeCap: baseFee,
-		GasTipCap: gasTipCap,
-		ChainID:   t.chainID,
-	}
-
-	signer := types.NewPragueSigner(new(big.Int).SetUint64(t.chainID.Uint64()))
-	tx := types.MustSignNewTx(privateKey, signer, txdata)
-
-	t.mempool.AddTransactions([]*types.Transaction{tx})
-
-	return nil
+	return append(funcSelector, packedArgs...), nil
 }
 
 func (t *contractPayloadWorker) SendTxs(ctx context.Context, _ int) (int, error) {
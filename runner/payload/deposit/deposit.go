@@ -0,0 +1,115 @@
+package deposit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// depositGas is the gas limit of every user deposit. Deposits buy their
+	// gas on L1, so it is not charged to the sender on L2.
+	depositGas = 100_000
+
+	// depositsPerSender is the number of deposits sent from each sender address,
+	// like a bridge contract that emits many deposits.
+	depositsPerSender = 8
+)
+
+var (
+	// transferValue is minted and sent by each value transfer deposit.
+	transferValue = big.NewInt(1_000_000_000_000_000) // 0.001 ETH
+
+	// l1BlockBasefeeSelector is the selector of L1Block.basefee(), a call that
+	// succeeds for any sender.
+	l1BlockBasefeeSelector = crypto.Keccak256([]byte("basefee()"))[:4]
+)
+
+// Definition configures the user deposit transactions injected into the
+// first L2 block of each L1 origin, where the deposits derived from L1 logs
+// appear on real chains.
+type Definition struct {
+	// Transfers is the number of deposits minting ETH and sending it to a new
+	// account.
+	Transfers int `yaml:"transfers"`
+	// Calls is the number of deposits calling the L1Block predeploy.
+	Calls int `yaml:"calls"`
+	// FailingCalls is the number of deposits whose call reverts. They are
+	// still included and still mint their value.
+	FailingCalls int `yaml:"failing_calls"`
+}
+
+// Validate checks that the counts are non-negative and at least one deposit
+// is configured.
+func (d Definition) Validate() error {
+	if d.Transfers < 0 || d.Calls < 0 || d.FailingCalls < 0 {
+		return fmt.Errorf("deposit counts must not be negative")
+	}
+	if d.Count() == 0 {
+		return fmt.Errorf("deposits must contain at least one transfer, call or failing call")
+	}
+	return nil
+}
+
+// Count returns the number of deposits per L1 origin.
+func (d Definition) Count() int {
+	return d.Transfers + d.Calls + d.FailingCalls
+}
+
+// Transactions returns the encoded user deposits of the L1 block with the
+// given hash and number. Their source hashes are derived from the L1 block
+// hash like deposits from L1 logs, so they are unique per L1 block.
+func (d Definition) Transactions(l1BlockHash common.Hash, l1BlockNumber uint64) ([][]byte, error) {
+	txs := make([][]byte, 0, d.Count())
+	for i := 0; i < d.Count(); i++ {
+		source := derive.UserDepositSource{
+			L1BlockHash: l1BlockHash,
+			LogIndex:    uint64(i),
+		}
+		deposit := &types.DepositTx{
+			SourceHash: source.SourceHash(),
+			From:       senderAddress(i / depositsPerSender),
+			Value:      new(big.Int),
+			Gas:        depositGas,
+		}
+
+		switch {
+		case i < d.Transfers:
+			to := recipientAddress(l1BlockNumber, i)
+			deposit.To = &to
+			deposit.Mint = new(big.Int).Set(transferValue)
+			deposit.Value = new(big.Int).Set(transferValue)
+		case i < d.Transfers+d.Calls:
+			deposit.To = &derive.L1BlockAddress
+			deposit.Data = l1BlockBasefeeSelector
+		default:
+			// only the depositor account may set the L1 block values, so
+			// this reverts, keeping the minted value with the sender
+			deposit.To = &derive.L1BlockAddress
+			deposit.Mint = new(big.Int).Set(transferValue)
+			deposit.Data = derive.L1InfoFuncEcotoneBytes4
+		}
+
+		tx, err := types.NewTx(deposit).MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode deposit tx: %w", err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// senderAddress returns the L1 sender of a deposit.
+func senderAddress(index int) common.Address {
+	return common.BytesToAddress(crypto.Keccak256([]byte("deposit-sender"), big.NewInt(int64(index)).Bytes()))
+}
+
+// recipientAddress returns a new account for each transfer, so that deposits
+// grow the state like bridged funds do.
+func recipientAddress(l1BlockNumber uint64, index int) common.Address {
+	return common.BytesToAddress(crypto.Keccak256([]byte("deposit-recipient"), new(big.Int).SetUint64(l1BlockNumber).Bytes(), big.NewInt(int64(index)).Bytes()))
+}
@@ -0,0 +1,56 @@
+package deposit
+
+import (
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinitionValidate(t *testing.T) {
+	require.NoError(t, Definition{Transfers: 1}.Validate())
+	require.NoError(t, Definition{FailingCalls: 2}.Validate())
+
+	require.Error(t, Definition{}.Validate())
+	require.Error(t, Definition{Transfers: 2, Calls: -1}.Validate())
+}
+
+func TestTransactions(t *testing.T) {
+	def := Definition{Transfers: 2, Calls: 3, FailingCalls: 1}
+	l1BlockHash := common.HexToHash("0x01")
+
+	encoded, err := def.Transactions(l1BlockHash, 7)
+	require.NoError(t, err)
+	require.Len(t, encoded, 6)
+
+	sources := make(map[common.Hash]bool)
+	for i, raw := range encoded {
+		var tx types.Transaction
+		require.NoError(t, tx.UnmarshalBinary(raw))
+		require.Equal(t, uint8(types.DepositTxType), tx.Type())
+		require.False(t, tx.IsSystemTx())
+		sources[tx.SourceHash()] = true
+
+		switch {
+		case i < 2:
+			require.NotEqual(t, derive.L1BlockAddress, *tx.To())
+			require.Equal(t, transferValue, tx.Value())
+			require.Equal(t, transferValue, tx.Mint())
+		case i < 5:
+			require.Equal(t, derive.L1BlockAddress, *tx.To())
+			require.Equal(t, l1BlockBasefeeSelector, tx.Data())
+			require.Nil(t, tx.Mint())
+		default:
+			require.Equal(t, derive.L1BlockAddress, *tx.To())
+			require.Equal(t, derive.L1InfoFuncEcotoneBytes4, tx.Data())
+		}
+	}
+	require.Len(t, sources, 6, "source hashes must be unique")
+
+	// the next L1 block gets new source hashes and recipients
+	next, err := def.Transactions(common.HexToHash("0x02"), 8)
+	require.NoError(t, err)
+	require.NotEqual(t, encoded[0], next[0])
+}
@@ -6,8 +6,11 @@ import (
 
 	clienttypes "github.com/base/base-bench/runner/clients/types"
 	benchtypes "github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/payload/composite"
 	"github.com/base/base-bench/runner/payload/contract"
+	"github.com/base/base-bench/runner/payload/deposit"
 	"github.com/base/base-bench/runner/payload/loadtest"
+	"github.com/base/base-bench/runner/payload/mix"
 	"github.com/base/base-bench/runner/payload/simulator"
 	"github.com/base/base-bench/runner/payload/transferonly"
 	"github.com/base/base-bench/runner/payload/txfuzz"
@@ -48,6 +51,11 @@ func NewPayloadWorker(ctx context.Context, log log.Logger, testConfig *benchtype
 	case "simulator":
 		worker, err = simulator.NewSimulatorPayloadWorker(
 			ctx, log, sequencerClient.ClientURL(), params, privateKey, amount, &genesis, definition.Params)
+	case "composite":
+		worker, err = composite.NewCompositePayloadWorker(
+			ctx, log, sequencerClient.ClientURL(), params, privateKey, amount, &genesis, definition.Params)
+	case "mix":
+		worker, err = newMixPayloadWorker(ctx, log, testConfig, sequencerClient, definition)
 	default:
 		return nil, fmt.Errorf("invalid payload type: %s", definition.Type)
 	}
@@ -60,13 +68,28 @@ type Definition struct {
 	ID     string  `yaml:"id"`
 	Type   string  `yaml:"type"`
 	Params any     `yaml:"-"`
+
+	// Deposits, if set, injects user deposit transactions into the first
+	// block of each L1 origin alongside the payload's transactions.
+	Deposits *deposit.Definition `yaml:"deposits"`
+
+	// Components holds the payloads referenced by a mix, in the order of its
+	// payload_mix entries. It is populated by ResolveMixes.
+	Components []Definition `yaml:"-"`
+}
+
+// HasFixedFeeCap returns true if the payload's workers send their
+// transactions with a fee cap of benchtypes.PayloadGasFeeCap.
+func (t Definition) HasFixedFeeCap() bool {
+	return mixablePayloadTypes[t.Type] || t.Type == "mix"
 }
 
 func (t *Definition) UnmarshalYAML(node *yaml.Node) error {
 	type txPayloadWithoutParams struct {
-		Name string `yaml:"name"`
-		ID   string `yaml:"id"`
-		Type string `yaml:"type"`
+		Name     string              `yaml:"name"`
+		ID       string              `yaml:"id"`
+		Type     string              `yaml:"type"`
+		Deposits *deposit.Definition `yaml:"deposits"`
 	}
 
 	var txPayload txPayloadWithoutParams
@@ -78,6 +101,12 @@ func (t *Definition) UnmarshalYAML(node *yaml.Node) error {
 	t.Name = &txPayload.Name
 	t.ID = txPayload.ID
 	t.Type = txPayload.Type
+	t.Deposits = txPayload.Deposits
+	if t.Deposits != nil {
+		if err := t.Deposits.Validate(); err != nil {
+			return fmt.Errorf("invalid deposits for payload %s: %w", t.ID, err)
+		}
+	}
 
 	params := interface{}(nil)
 	switch t.Type {
@@ -91,6 +120,10 @@ func (t *Definition) UnmarshalYAML(node *yaml.Node) error {
 		params = &contract.ContractPayloadDefinition{}
 	case "simulator":
 		params = &simulator.SimulatorPayloadDefinition{}
+	case "composite":
+		params = &composite.CompositePayloadDefinition{}
+	case "mix":
+		params = &mix.PayloadMixDefinition{}
 	}
 
 	err = node.Decode(params)
@@ -0,0 +1,112 @@
+package payload
+
+import (
+	"context"
+	"fmt"
+
+	clienttypes "github.com/base/base-bench/runner/clients/types"
+	benchtypes "github.com/base/base-bench/runner/network/types"
+	"github.com/base/base-bench/runner/payload/mix"
+	"github.com/base/base-bench/runner/payload/worker"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// mixablePayloadTypes are the payload types that can be part of a mix. Load
+// tests and tx-fuzz drive external tools and cannot share a block budget.
+var mixablePayloadTypes = map[string]bool{
+	"transfer-only": true,
+	"contract":      true,
+	"simulator":     true,
+	"composite":     true,
+}
+
+// ResolveMixes validates every mix payload and attaches the definitions it
+// references.
+func ResolveMixes(payloads map[string]Definition) error {
+	for id, def := range payloads {
+		if def.Type != "mix" {
+			continue
+		}
+		mixDef, ok := def.Params.(*mix.PayloadMixDefinition)
+		if !ok {
+			return fmt.Errorf("invalid payload mix %s: %#v", id, def.Params)
+		}
+		if err := mixDef.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid payload mix %s", id)
+		}
+
+		def.Components = make([]Definition, 0, len(mixDef.Payloads))
+		for _, entry := range mixDef.Payloads {
+			component, ok := payloads[entry.Payload]
+			if !ok {
+				return fmt.Errorf("payload mix %s references unknown payload %s", id, entry.Payload)
+			}
+			if !mixablePayloadTypes[component.Type] {
+				return fmt.Errorf("payload mix %s cannot include payload %s of type %s", id, entry.Payload, component.Type)
+			}
+			if component.Deposits != nil {
+				return fmt.Errorf("payload mix %s cannot include payload %s with deposits; set deposits on the mix instead", id, entry.Payload)
+			}
+			def.Components = append(def.Components, component)
+		}
+		payloads[id] = def
+	}
+	return nil
+}
+
+func newMixPayloadWorker(ctx context.Context, log log.Logger, testConfig *benchtypes.TestConfig, sequencerClient clienttypes.ExecutionClient, definition Definition) (worker.Worker, error) {
+	mixDef, ok := definition.Params.(*mix.PayloadMixDefinition)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload mix: %#v", definition.Params)
+	}
+	if len(definition.Components) != len(mixDef.Payloads) {
+		return nil, fmt.Errorf("payload mix %s has not been resolved", definition.ID)
+	}
+
+	shares := mixDef.Shares()
+	budgets, err := mixDef.GasBudgets(testConfig.Params.GasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]mix.Component, 0, len(definition.Components))
+	stopCreated := func() {
+		for _, c := range components {
+			if err := c.Worker.Stop(ctx); err != nil {
+				log.Warn("failed to stop payload worker", "payload", c.ID, "err", err)
+			}
+		}
+	}
+
+	for i, sub := range definition.Components {
+		key, err := mix.DeriveKey(&testConfig.PrefundPrivateKey, i)
+		if err != nil {
+			stopCreated()
+			return nil, errors.Wrap(err, "failed to derive payload mix key")
+		}
+
+		subConfig := *testConfig
+		subConfig.PrefundPrivateKey = *key
+		subConfig.Params.GasLimit = budgets[i]
+
+		w, err := NewPayloadWorker(ctx, log.New("payload", sub.ID), &subConfig, sequencerClient, sub)
+		if err != nil {
+			stopCreated()
+			return nil, errors.Wrapf(err, "failed to create payload %s", sub.ID)
+		}
+		components = append(components, mix.Component{
+			ID:     sub.ID,
+			Worker: w,
+			Key:    key,
+			Share:  shares[i],
+		})
+	}
+
+	w, err := mix.NewMixPayloadWorker(log, sequencerClient.ClientURL(), testConfig.Genesis.Config.ChainID, &testConfig.PrefundAmount, components)
+	if err != nil {
+		stopCreated()
+		return nil, err
+	}
+	return w, nil
+}
@@ -0,0 +1,276 @@
+package mix
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/base/base-bench/runner/network/mempool"
+	"github.com/base/base-bench/runner/payload/worker"
+	"github.com/ethereum-optimism/optimism/op-service/retry"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// PayloadMixEntry references another payload definition by ID.
+type PayloadMixEntry struct {
+	Payload string  `yaml:"payload"`
+	Weight  float64 `yaml:"weight"`
+}
+
+// PayloadMixDefinition combines several payload definitions into a single
+// block stream. Each payload gets a share of the block gas limit in
+// proportion to its weight.
+type PayloadMixDefinition struct {
+	Payloads []PayloadMixEntry `yaml:"payload_mix"`
+}
+
+// Validate checks that the mix references at least one payload and that all
+// weights are positive.
+func (d PayloadMixDefinition) Validate() error {
+	if len(d.Payloads) == 0 {
+		return fmt.Errorf("payload_mix must contain at least one payload")
+	}
+	seen := make(map[string]struct{}, len(d.Payloads))
+	for _, p := range d.Payloads {
+		if p.Payload == "" {
+			return fmt.Errorf("payload_mix entry is missing a payload id")
+		}
+		if _, ok := seen[p.Payload]; ok {
+			return fmt.Errorf("payload %s appears more than once in payload_mix", p.Payload)
+		}
+		seen[p.Payload] = struct{}{}
+		if p.Weight <= 0 || math.IsNaN(p.Weight) || math.IsInf(p.Weight, 0) {
+			return fmt.Errorf("weight %f for payload %s must be positive", p.Weight, p.Payload)
+		}
+	}
+	return nil
+}
+
+// Shares returns each entry's fraction of the total weight.
+func (d PayloadMixDefinition) Shares() []float64 {
+	total := 0.0
+	for _, p := range d.Payloads {
+		total += p.Weight
+	}
+	shares := make([]float64, len(d.Payloads))
+	for i, p := range d.Payloads {
+		shares[i] = p.Weight / total
+	}
+	return shares
+}
+
+const (
+	// componentGasBuffer is the gas the payload workers leave unused at the
+	// end of each block.
+	componentGasBuffer = 100_000
+
+	// MinComponentGasLimit is the smallest gas budget a mixed payload can be
+	// given: the workers' gas buffer plus room for one transfer.
+	MinComponentGasLimit = componentGasBuffer + 21_000
+)
+
+// GasBudget returns the part of the block gas limit given to a payload with
+// the given share.
+func GasBudget(gasLimit uint64, share float64) uint64 {
+	return uint64(math.Floor(float64(gasLimit) * share))
+}
+
+// GasBudgets returns the gas budget of each payload in the mix for the given
+// block gas limit. It fails if any payload would get less than
+// MinComponentGasLimit, since its worker could not fit a transaction.
+func (d PayloadMixDefinition) GasBudgets(gasLimit uint64) ([]uint64, error) {
+	shares := d.Shares()
+	budgets := make([]uint64, len(shares))
+	for i, share := range shares {
+		budgets[i] = GasBudget(gasLimit, share)
+		if budgets[i] < MinComponentGasLimit {
+			return nil, fmt.Errorf("payload %s gets %d gas of the %d gas limit, below the minimum of %d", d.Payloads[i].Payload, budgets[i], gasLimit, MinComponentGasLimit)
+		}
+	}
+	return budgets, nil
+}
+
+// DeriveKey returns the account used by the index-th payload of a mix. Each
+// payload sends from its own account so their nonces never collide.
+func DeriveKey(base *ecdsa.PrivateKey, index int) (*ecdsa.PrivateKey, error) {
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], uint64(index))
+	return crypto.ToECDSA(crypto.Keccak256(crypto.FromECDSA(base), []byte("payload_mix"), idx[:]))
+}
+
+// Component is a payload worker that is part of a mix.
+type Component struct {
+	ID     string
+	Worker worker.Worker
+	// Key is the account the worker was created with. It is funded by the
+	// mix worker before the component's Setup runs.
+	Key   *ecdsa.PrivateKey
+	Share float64
+}
+
+type mixPayloadWorker struct {
+	log log.Logger
+
+	client        *ethclient.Client
+	prefundAmount *big.Int
+	components    []Component
+
+	mempool *mixMempool
+}
+
+// NewMixPayloadWorker creates a worker that drives several payload workers
+// as a single workload.
+func NewMixPayloadWorker(log log.Logger, elRPCURL string, chainID *big.Int, prefundAmount *big.Int, components []Component) (worker.Worker, error) {
+	if len(components) == 0 {
+		return nil, fmt.Errorf("payload mix has no components")
+	}
+
+	client, err := ethclient.Dial(elRPCURL)
+	if err != nil {
+		return nil, err
+	}
+
+	subMempools := make([]mempool.FakeMempool, len(components))
+	for i, c := range components {
+		subMempools[i] = c.Worker.Mempool()
+	}
+
+	return &mixPayloadWorker{
+		log:           log,
+		client:        client,
+		prefundAmount: prefundAmount,
+		components:    components,
+		mempool:       newMixMempool(mempool.NewStaticWorkloadMempool(log, chainID), subMempools),
+	}, nil
+}
+
+func (w *mixPayloadWorker) Mempool() mempool.FakeMempool {
+	return w.mempool
+}
+
+func (w *mixPayloadWorker) Setup(ctx context.Context) error {
+	if err := w.fundComponents(ctx); err != nil {
+		return errors.Wrap(err, "failed to fund payload mix accounts")
+	}
+
+	for _, c := range w.components {
+		w.log.Info("Setting up mixed payload", "payload", c.ID, "share", c.Share)
+		if err := c.Worker.Setup(ctx); err != nil {
+			return errors.Wrapf(err, "failed to setup payload %s", c.ID)
+		}
+	}
+	return nil
+}
+
+// fundComponents mints the prefund amount to each component account with a
+// deposit transaction, the same way the benchmark funds the prefunded account.
+func (w *mixPayloadWorker) fundComponents(ctx context.Context) error {
+	var deposits []*types.Transaction
+	for _, c := range w.components {
+		addr := crypto.PubkeyToAddress(c.Key.PublicKey)
+		balance, err := w.client.BalanceAt(ctx, addr, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to get balance")
+		}
+		if balance.Cmp(w.prefundAmount) >= 0 {
+			continue
+		}
+
+		deposits = append(deposits, types.NewTx(&types.DepositTx{
+			From:       common.Address{1},
+			To:         &addr,
+			SourceHash: crypto.Keccak256Hash(addr.Bytes(), []byte("payload_mix")),
+			Mint:       new(big.Int).Set(w.prefundAmount),
+			Value:      new(big.Int).Set(w.prefundAmount),
+			Gas:        210000,
+		}))
+	}
+	if len(deposits) == 0 {
+		return nil
+	}
+
+	w.mempool.AddTransactions(deposits)
+
+	for _, tx := range deposits {
+		receipt, err := retry.Do(ctx, 60, retry.Fixed(1*time.Second), func() (*types.Receipt, error) {
+			return w.client.TransactionReceipt(ctx, tx.Hash())
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to get deposit receipt")
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return fmt.Errorf("deposit to %s failed with status %d", tx.To().Hex(), receipt.Status)
+		}
+	}
+
+	w.log.Info("Funded payload mix accounts", "accounts", len(deposits))
+	return nil
+}
+
+func (w *mixPayloadWorker) SendTxs(ctx context.Context, pendingTxs int) (int, error) {
+	sent := 0
+	for _, c := range w.components {
+		n, err := c.Worker.SendTxs(ctx, splitPending(pendingTxs, c.Share))
+		if err != nil {
+			return sent, errors.Wrapf(err, "payload %s failed to send transactions", c.ID)
+		}
+		sent += n
+	}
+	return sent, nil
+}
+
+func (w *mixPayloadWorker) Stop(ctx context.Context) error {
+	var errs []error
+	for _, c := range w.components {
+		if err := c.Worker.Stop(ctx); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to stop payload %s", c.ID))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to stop payload mix: %v", errs)
+	}
+	return nil
+}
+
+// splitPending attributes a share of the node's pending transactions to one
+// component so that each component backs off in proportion to its budget.
+func splitPending(pendingTxs int, share float64) int {
+	return int(math.Round(float64(pendingTxs) * share))
+}
+
+// mixMempool collects the transactions of all components into one block.
+type mixMempool struct {
+	own  *mempool.StaticWorkloadMempool
+	subs []mempool.FakeMempool
+}
+
+func newMixMempool(own *mempool.StaticWorkloadMempool, subs []mempool.FakeMempool) *mixMempool {
+	return &mixMempool{own: own, subs: subs}
+}
+
+// AddTransactions queues transactions owned by the mix itself, such as the
+// deposits funding the component accounts.
+func (m *mixMempool) AddTransactions(transactions []*types.Transaction) {
+	m.own.AddTransactions(transactions)
+}
+
+func (m *mixMempool) NextBlock() ([][]byte, [][]byte) {
+	sendTxs, sequencerTxs := m.own.NextBlock()
+	for _, sub := range m.subs {
+		s, seq := sub.NextBlock()
+		sendTxs = append(sendTxs, s...)
+		sequencerTxs = append(sequencerTxs, seq...)
+	}
+	return sendTxs, sequencerTxs
+}
+
+var _ mempool.FakeMempool = &mixMempool{}
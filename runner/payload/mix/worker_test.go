@@ -0,0 +1,123 @@
+package mix
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/base/base-bench/runner/network/mempool"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadMixDefinitionValidate(t *testing.T) {
+	valid := PayloadMixDefinition{Payloads: []PayloadMixEntry{
+		{Payload: "transfer-only", Weight: 60},
+		{Payload: "simulator", Weight: 30},
+		{Payload: "contract", Weight: 10},
+	}}
+	require.NoError(t, valid.Validate())
+
+	require.Error(t, PayloadMixDefinition{}.Validate())
+	require.Error(t, PayloadMixDefinition{Payloads: []PayloadMixEntry{{Weight: 1}}}.Validate())
+	require.Error(t, PayloadMixDefinition{Payloads: []PayloadMixEntry{{Payload: "a", Weight: -1}, {Payload: "b", Weight: 2}}}.Validate())
+	require.Error(t, PayloadMixDefinition{Payloads: []PayloadMixEntry{{Payload: "a"}, {Payload: "b"}}}.Validate())
+	require.Error(t, PayloadMixDefinition{Payloads: []PayloadMixEntry{{Payload: "a", Weight: 0}, {Payload: "b", Weight: 2}}}.Validate())
+	require.Error(t, PayloadMixDefinition{Payloads: []PayloadMixEntry{{Payload: "a", Weight: 1}, {Payload: "a", Weight: 1}}}.Validate())
+}
+
+func TestSharesAndGasBudget(t *testing.T) {
+	def := PayloadMixDefinition{Payloads: []PayloadMixEntry{
+		{Payload: "a", Weight: 6},
+		{Payload: "b", Weight: 3},
+		{Payload: "c", Weight: 1},
+	}}
+	shares := def.Shares()
+	require.InDeltaSlice(t, []float64{0.6, 0.3, 0.1}, shares, 1e-9)
+
+	var total uint64
+	for _, s := range shares {
+		total += GasBudget(100_000_000, s)
+	}
+	require.LessOrEqual(t, total, uint64(100_000_000))
+	require.Equal(t, uint64(60_000_000), GasBudget(100_000_000, shares[0]))
+}
+
+func TestGasBudgetsRejectsTinyShares(t *testing.T) {
+	def := PayloadMixDefinition{Payloads: []PayloadMixEntry{
+		{Payload: "a", Weight: 999},
+		{Payload: "b", Weight: 1},
+	}}
+
+	budgets, err := def.GasBudgets(1_000_000_000)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{999_000_000, 1_000_000}, budgets)
+
+	_, err = def.GasBudgets(100_000_000)
+	require.Error(t, err)
+
+	budgets, err = PayloadMixDefinition{Payloads: []PayloadMixEntry{{Payload: "a", Weight: 1}}}.GasBudgets(MinComponentGasLimit)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{MinComponentGasLimit}, budgets)
+}
+
+func TestSplitPending(t *testing.T) {
+	require.Equal(t, 0, splitPending(0, 0.5))
+	require.Equal(t, 60, splitPending(100, 0.6))
+	require.Equal(t, 100, splitPending(100, 1))
+}
+
+func TestDeriveKey(t *testing.T) {
+	base, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	k0, err := DeriveKey(base, 0)
+	require.NoError(t, err)
+	k0Again, err := DeriveKey(base, 0)
+	require.NoError(t, err)
+	k1, err := DeriveKey(base, 1)
+	require.NoError(t, err)
+
+	require.Equal(t, crypto.FromECDSA(k0), crypto.FromECDSA(k0Again))
+	require.NotEqual(t, crypto.FromECDSA(k0), crypto.FromECDSA(k1))
+	require.NotEqual(t, crypto.FromECDSA(base), crypto.FromECDSA(k0))
+}
+
+func TestMixMempoolCombinesComponents(t *testing.T) {
+	chainID := big.NewInt(901)
+	signer := types.NewIsthmusSigner(chainID)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	newTx := func(nonce uint64) *types.Transaction {
+		to := common.Address{0x42}
+		return types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       21000,
+			GasFeeCap: big.NewInt(1e9),
+			GasTipCap: big.NewInt(1),
+		})
+	}
+
+	own := mempool.NewStaticWorkloadMempool(log.New(), chainID)
+	a := mempool.NewStaticWorkloadMempool(log.New(), chainID)
+	b := mempool.NewStaticWorkloadMempool(log.New(), chainID)
+	m := newMixMempool(own, []mempool.FakeMempool{a, b})
+
+	deposit := types.NewTx(&types.DepositTx{From: common.Address{1}, To: &common.Address{2}, Mint: big.NewInt(1), Value: big.NewInt(1), Gas: 21000})
+	m.AddTransactions([]*types.Transaction{deposit})
+	a.AddTransactions([]*types.Transaction{newTx(0), newTx(1)})
+	b.AddTransactions([]*types.Transaction{newTx(2)})
+
+	sendTxs, sequencerTxs := m.NextBlock()
+	require.Len(t, sendTxs, 3)
+	require.Len(t, sequencerTxs, 1)
+
+	sendTxs, sequencerTxs = m.NextBlock()
+	require.Empty(t, sendTxs)
+	require.Empty(t, sequencerTxs)
+}
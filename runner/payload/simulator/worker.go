@@ -130,6 +130,12 @@ func NewSimulatorPayloadWorker(ctx context.Context, log log.Logger, elRPCURL str
 		return nil, errors.New("Simulator payload params are not valid")
 	}
 
+	// storage and calls are allocated up front for the planned blocks, which
+	// are unknown for a duration if blocks are built faster than the block time
+	if !params.PacesBlocksByBlockTime() && (params.Duration > 0 || params.WarmupDuration > 0) {
+		return nil, fmt.Errorf("simulator payload cannot run for a duration with consensus timing %s; use num_blocks and warmup_blocks instead", params.ConsensusTimingMode)
+	}
+
 	numCallers := defaultNumCallers
 	if simulatorParams.NumCallers != nil && *simulatorParams.NumCallers > 0 {
 		numCallers = *simulatorParams.NumCallers
@@ -153,7 +159,7 @@ func NewSimulatorPayloadWorker(ctx context.Context, log log.Logger, elRPCURL str
 		}
 		transactor.Nonce = big.NewInt(int64(nonce))
 
-		transactor.GasFeeCap = new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1))
+		transactor.GasFeeCap = new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap)
 		transactor.GasTipCap = big.NewInt(1)
 
 		transactors[i] = transactor
@@ -300,7 +306,7 @@ func (t *simulatorPayloadWorker) testForBlocks(ctx context.Context, simulator *a
 	prefundAddr := crypto.PubkeyToAddress(t.prefundedAccount.PublicKey)
 
 	// estimate storage slot usage
-	contractConfig, err := t.payloadParams.Mul(float64(t.params.NumBlocks)).ToConfig()
+	contractConfig, err := t.payloadParams.Mul(float64(t.params.PlannedBlocks())).ToConfig()
 	if err != nil {
 		return errors.Wrap(err, "failed to convert payload params to config")
 	}
@@ -389,7 +395,7 @@ func (t *simulatorPayloadWorker) testForBlocks(ctx context.Context, simulator *a
 
 	t.log.Info("Calculated num calls per block", "numCalls", t.numCallsPerBlock, "gas", gas, "gasLimit", t.params.GasLimit, "buffer", buffer)
 
-	configForAllBlocks, err := t.payloadParams.Mul(float64(t.numCallsPerBlock) * float64(t.params.NumBlocks) * t.scaleFactor * 1.05).ToConfig()
+	configForAllBlocks, err := t.payloadParams.Mul(float64(t.numCallsPerBlock) * float64(t.params.PlannedBlocks()) * t.scaleFactor * 1.05).ToConfig()
 	if err != nil {
 		return errors.Wrap(err, "failed to convert payload params to config")
 	}
@@ -541,7 +547,7 @@ func (t *simulatorPayloadWorker) fundCallerAccounts(ctx context.Context) error {
 			Nonce:     nonce,
 			To:        &callerAddr,
 			Gas:       21000,
-			GasFeeCap: new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1)), // 1 gwei
+			GasFeeCap: new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap),
 			GasTipCap: big.NewInt(2),
 			Value:     perCallerAmount,
 		}
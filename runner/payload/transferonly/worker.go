@@ -296,7 +296,7 @@ func (t *transferOnlyPayloadWorker) createTransferTx(fromPriv *ecdsa.PrivateKey,
 		Nonce:     nonce,
 		To:        &toAddr,
 		Gas:       21000,
-		GasFeeCap: new(big.Int).Mul(big.NewInt(params.GWei), big.NewInt(1)),
+		GasFeeCap: new(big.Int).SetUint64(benchtypes.PayloadGasFeeCap),
 		GasTipCap: big.NewInt(2),
 		Value:     amount,
 	}
@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"os"
 	"path"
+	"path/filepath"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -23,10 +24,14 @@ import (
 	"github.com/base/base-bench/runner/benchmark/portmanager"
 	"github.com/base/base-bench/runner/config"
 	"github.com/base/base-bench/runner/metrics"
+	"github.com/base/base-bench/runner/metrics/exporter"
 	"github.com/base/base-bench/runner/network"
 	"github.com/base/base-bench/runner/network/types"
 	"github.com/base/base-bench/runner/payload"
 	"github.com/base/base-bench/runner/payload/loadtest"
+	"github.com/base/base-bench/runner/sink"
+	"github.com/base/base-bench/runner/soak"
+	"github.com/base/base-bench/runner/timeline"
 	"github.com/base/base-bench/runner/utils"
 	"github.com/ethereum/go-ethereum/core"
 	ethparams "github.com/ethereum/go-ethereum/params"
@@ -43,18 +48,25 @@ type service struct {
 	// this is used to avoid copying the datadirs for each test
 	dataDirState benchmark.SnapshotManager
 	portState    portmanager.PortManager
-	metadataPath string
+	metadataFile *benchmark.MetadataFile
 
 	config  config.Config
 	version string
 	log     log.Logger
+
+	// exporter publishes live progress when a metrics port is configured.
+	exporter *exporter.Exporter
+
+	// sink receives the output of each finished run when a result sink is
+	// configured.
+	sink sink.ResultSink
 }
 
 func NewService(version string, cfg config.Config, log log.Logger) Service {
 	metadataPath := path.Join(cfg.OutputDir(), "metadata.json")
 
 	return &service{
-		metadataPath: metadataPath,
+		metadataFile: benchmark.NewMetadataFile(metadataPath),
 		portState:    portmanager.NewPortManager(),
 		dataDirState: benchmark.NewSnapshotManager(path.Join(cfg.DataDir(), "snapshots")),
 		config:       cfg,
@@ -218,17 +230,47 @@ func (s *service) exportOutput(testName string, returnedError error, testDirs *c
 	//  │   ├── result-<node_type>.json
 	//  │   ├── logs-<node_type>.gz
 	//  │   ├── metrics-<node_type>.json
+	//  │   ├── metrics-<node_type>.jsonl (per-block metrics stream)
+	//  │   ├── prometheus-<node_type>.jsonl.gz (if scrape archiving is enabled)
+	//  │   ├── metadata.json (this run's metadata, written once all roles finish)
+	//  │   ├── timeline.json (lifecycle events of the run as a Chrome trace)
+	//  │   ├── engine-trace-<node_type>.jsonl (if --engine-trace is set)
 
 	// create output directory
 
-	// copy metrics.json to output dir
+	// copy metrics.json to output dir, assembling it from the metrics stream
+	// if the benchmark stopped before writing it
 	metricsPath := path.Join(testDirs.MetricsPath, metrics.MetricsFileName)
+	if _, err := os.Stat(metricsPath); os.IsNotExist(err) {
+		if err := metrics.AssembleMetricsFile(testDirs.MetricsPath); err != nil {
+			return errors.Wrap(err, "failed to assemble metrics file")
+		}
+	}
 	metricsOutputPath := path.Join(testOutputDir, fmt.Sprintf("metrics-%s.json", nodeType))
 	err := os.Rename(metricsPath, metricsOutputPath)
 	if err != nil {
 		return errors.Wrap(err, "failed to move metrics file")
 	}
 
+	// keep the raw metrics stream alongside, since the metrics directory is
+	// removed after the run
+	streamPath := path.Join(testDirs.MetricsPath, metrics.MetricsStreamFileName)
+	if _, err := os.Stat(streamPath); err == nil {
+		streamOutputPath := path.Join(testOutputDir, fmt.Sprintf("metrics-%s.jsonl", nodeType))
+		if err := os.Rename(streamPath, streamOutputPath); err != nil {
+			return errors.Wrap(err, "failed to move metrics stream")
+		}
+	}
+
+	// move the prometheus scrape archive, if archiving was enabled
+	archivePath := path.Join(testDirs.MetricsPath, metrics.ScrapeArchiveFileName)
+	if _, err := os.Stat(archivePath); err == nil {
+		archiveOutputPath := path.Join(testOutputDir, fmt.Sprintf("prometheus-%s.jsonl.gz", nodeType))
+		if err := os.Rename(archivePath, archiveOutputPath); err != nil {
+			return errors.Wrap(err, "failed to move prometheus scrape archive")
+		}
+	}
+
 	// copy logs to output dir gzipped
 	logsPath := path.Join(testDirs.TestDirPath, network.ExecutionLayerLogFileName)
 	logsOutputPath := path.Join(testOutputDir, fmt.Sprintf("logs-%s.gz", nodeType))
@@ -422,7 +464,7 @@ func (s *service) loadTestOutputPath(genesis *core.Genesis, transactionPayload p
 	}
 }
 
-func (s *service) runTest(ctx context.Context, params types.RunParams, workingDir string, outputDir string, snapshotConfig *benchmark.SnapshotDefinition, proofConfig *benchmark.ProofProgramOptions, transactionPayload payload.Definition, datadirsConfig *benchmark.DatadirConfig, mode benchmark.BenchmarkExecutionMode, flashblocksBlockTime string, flashblocksLeewayTime string) (*benchmark.RunResult, error) {
+func (s *service) runTest(ctx context.Context, params types.RunParams, workingDir string, outputDir string, snapshotConfig *benchmark.SnapshotDefinition, proofConfig *benchmark.ProofProgramOptions, transactionPayload payload.Definition, datadirsConfig *benchmark.DatadirConfig, mode benchmark.BenchmarkExecutionMode, scrapeConfig *metrics.ScrapeConfig, soakConfig *soak.Config, flashblocksBlockTime string, flashblocksLeewayTime string) (*benchmark.RunResult, error) {
 
 	s.log.Info(fmt.Sprintf("Running benchmark with params: %+v", params))
 
@@ -443,6 +485,11 @@ func (s *service) runTest(ctx context.Context, params types.RunParams, workingDi
 		return nil, errors.Wrap(err, "failed to setup data dirs")
 	}
 
+	sequencerOptions.Scrape = scrapeConfig
+	if validatorOptions != nil {
+		validatorOptions.Scrape = scrapeConfig
+	}
+
 	if proofConfig != nil {
 		if err := s.setupBlobsDir(workingDir); err != nil {
 			return nil, errors.Wrap(err, "failed to setup blobs directory")
@@ -485,24 +532,54 @@ func (s *service) runTest(ctx context.Context, params types.RunParams, workingDi
 		PrefundAmount:      *prefundAmount,
 		LoadTestOutputPath: s.loadTestOutputPath(genesis, transactionPayload),
 	}
+	if s.exporter != nil {
+		config.BlockObserver = s.exporter
+	}
+	tl := timeline.New(params.Name)
+	config.Timeline = tl
+	if s.config.EngineTrace() {
+		config.EngineTraceDir = outputDir
+	}
+	config.Soak = soakConfig
 
 	// Run benchmark
 	benchmark, err := network.NewNetworkBenchmark(config, s.log, sequencerOptions, validatorOptions, proofConfig, transactionPayload, s.portState, mode, flashblocksBlockTime, flashblocksLeewayTime)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create network benchmark")
 	}
+	runSpan := tl.Begin(timeline.TrackRunner, "run", "benchmark", "nodeType", params.NodeType, "roles", mode.RolesString())
 	runErr := benchmark.Run(ctx)
+	runSpan.End("err", runErr)
 
 	// Always export output, even if the benchmark failed or the node crashed.
 	// This ensures log files are preserved for debugging.
+	exportSpan := tl.Begin(timeline.TrackRunner, "run", "export sequencer output")
 	if exportErr := s.exportOutput(testName, runErr, sequencerOptions, outputDir, "sequencer"); exportErr != nil {
 		s.log.Error("failed to export sequencer output", "err", exportErr)
 	}
+	exportSpan.End()
 
 	if validatorOptions != nil {
+		exportSpan := tl.Begin(timeline.TrackRunner, "run", "export validator output")
 		if exportErr := s.exportOutput(testName, runErr, validatorOptions, outputDir, "validator"); exportErr != nil {
 			s.log.Error("failed to export validator output", "err", exportErr)
 		}
+		exportSpan.End()
+	}
+
+	// Written even if the run failed or was interrupted so that the samples
+	// of a long soak run are kept.
+	soakReport := benchmark.SoakReport()
+	if soakReport != nil {
+		if err := writeSoakReport(outputDir, soakReport); err != nil {
+			s.log.Error("failed to write soak report", "err", err)
+			soakReport = nil
+		}
+	}
+
+	// written last so that it covers the export of the other files
+	if err := tl.WriteFile(path.Join(outputDir, timeline.FileName)); err != nil {
+		s.log.Error("failed to write run timeline", "err", err)
 	}
 
 	if runErr != nil {
@@ -518,57 +595,155 @@ func (s *service) runTest(ctx context.Context, params types.RunParams, workingDi
 		return nil, errors.Wrap(err, "failed to get metrics")
 	}
 
+	if soakReport != nil {
+		if result.Artifacts == nil {
+			result.Artifacts = make(map[string]string)
+		}
+		result.Artifacts[soak.ReportArtifactKey] = soak.ReportFileName
+	}
+
 	return result, nil
 }
 
-func (s *service) readTestMetadata() ([]benchmark.Run, error) {
-	existingMetadataFile, err := os.ReadFile(s.metadataPath)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read existing test metadata")
-	}
-
-	var existingMetadata benchmark.RunGroup
-	err = json.Unmarshal(existingMetadataFile, &existingMetadata)
-	if err != nil {
-		return nil, err
-	}
+// runSearch runs a saturation search in place of a single run. Each step of
+// the search runs in its own subdirectory of outputDir. The returned result
+// holds the metrics of the highest sustained gas limit and the convergence
+// history, which is also written to outputDir. If no gas limit was sustained,
+// the result is returned together with an error.
+func (s *service) runSearch(ctx context.Context, testPlan benchmark.TestPlan, params types.RunParams, outputDir string, transactionPayload payload.Definition, flashblocksBlockTime string, flashblocksLeewayTime string) (*benchmark.RunResult, error) {
+	search := benchmark.NewSaturationSearch(*testPlan.Search, params.BlockTime, testPlan.Mode.RunValidator)
+	stepResults := make(map[string]*benchmark.RunResult)
+
+	for i := 0; ctx.Err() == nil; i++ {
+		gasLimit, ok := search.Next()
+		if !ok {
+			break
+		}
 
-	return existingMetadata.Runs, nil
-}
+		stepDir := fmt.Sprintf("step-%02d", i)
+		stepOutputDir := path.Join(outputDir, stepDir)
+		if err := os.MkdirAll(stepOutputDir, 0755); err != nil {
+			return nil, errors.Wrap(err, "failed to create search step output directory")
+		}
 
-func (s *service) writeTestMetadata(testPlan benchmark.RunGroup) error {
-	var runs []benchmark.Run
+		stepParams := params
+		stepParams.GasLimit = gasLimit
+		s.log.Info("Running search step", "step", i, "gasLimit", gasLimit)
+		result, runErr := s.runTest(ctx, stepParams, s.config.DataDir(), stepOutputDir, testPlan.Snapshot, testPlan.ProofProgram, transactionPayload, testPlan.Datadir, testPlan.Mode, testPlan.Scrape, testPlan.Soak, flashblocksBlockTime, flashblocksLeewayTime)
+		if runErr == nil {
+			stepResults[stepDir] = result
+		}
 
-	existingRuns, _ := s.readTestMetadata()
+		step := search.Record(gasLimit, stepDir, result, runErr)
+		s.log.Info("Finished search step", "step", i, "gasLimit", gasLimit, "sustained", step.Sustained, "gasPerSecond", step.GasPerSecond, "reason", step.Reason)
+	}
 
-	metadataFile, err := os.OpenFile(s.metadataPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	searchResult := search.Result()
+	historyJSON, err := json.MarshalIndent(searchResult, "", "  ")
 	if err != nil {
-		return errors.Wrap(err, "failed to open test metadata file")
+		return nil, errors.Wrap(err, "failed to marshal search result")
+	}
+	if err := os.WriteFile(path.Join(outputDir, benchmark.SearchResultFileName), historyJSON, 0644); err != nil {
+		return nil, errors.Wrap(err, "failed to write search result")
 	}
 
-	// remove all runs that have the same benchmark run id
-	for _, run := range existingRuns {
-		if run.TestConfig["BenchmarkRun"] != testPlan.Runs[0].TestConfig["BenchmarkRun"] {
-			runs = append(runs, run)
-		}
+	result := &benchmark.RunResult{
+		Complete: true,
+		Search:   &searchResult,
+	}
+	best, ok := search.Best()
+	if ok {
+		stepResult := stepResults[best.OutputDir]
+		result.Success = true
+		result.SequencerMetrics = stepResult.SequencerMetrics
+		result.ValidatorMetrics = stepResult.ValidatorMetrics
+		result.ProofProgramMetrics = stepResult.ProofProgramMetrics
+		result.ClientVersion = stepResult.ClientVersion
+		result.Warmup = stepResult.Warmup
+		result.Soak = stepResult.Soak
 	}
 
-	runs = append(runs, testPlan.Runs...)
+	// artifacts are relative to the output directory of the search
+	result.Artifacts = map[string]string{
+		benchmark.SearchResultArtifactKey: benchmark.SearchResultFileName,
+	}
+	if ok {
+		for key, file := range stepResults[best.OutputDir].Artifacts {
+			result.Artifacts[key] = path.Join(best.OutputDir, file)
+		}
+		if err := copySearchStepMetrics(path.Join(outputDir, best.OutputDir), outputDir); err != nil {
+			return nil, err
+		}
+	}
 
-	newMetadata := benchmark.RunGroup{
-		Runs: runs,
+	s.log.Info("Finished saturation search", "maxGasLimit", searchResult.MaxGasLimit, "maxGasPerSecond", searchResult.MaxGasPerSecond, "steps", len(searchResult.Steps))
+	if !ok {
+		return result, fmt.Errorf("no gas limit between %d and %d was sustained", testPlan.Search.MinGasLimit, testPlan.Search.MaxGasLimit)
 	}
+	return result, nil
+}
 
-	jsonEncoder := json.NewEncoder(metadataFile)
-	jsonEncoder.SetIndent("", "  ")
-	err = jsonEncoder.Encode(newMetadata)
+// copySearchStepMetrics copies the per-block metrics of each role from the
+// output directory of a search step to that of the search, where the report
+// and the export look for them.
+func copySearchStepMetrics(stepOutputDir string, outputDir string) error {
+	matches, err := filepath.Glob(path.Join(stepOutputDir, "metrics-*.json"))
 	if err != nil {
-		return errors.Wrap(err, "failed to encode test metadata")
+		return errors.Wrap(err, "failed to list search step metrics")
 	}
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			return errors.Wrap(err, "failed to read search step metrics")
+		}
+		if err := os.WriteFile(path.Join(outputDir, filepath.Base(match)), data, 0644); err != nil {
+			return errors.Wrap(err, "failed to copy search step metrics")
+		}
+	}
+	return nil
+}
 
+// writeSoakReport writes the samples and trends of a soak run to outputDir.
+func writeSoakReport(outputDir string, report *soak.Report) error {
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal soak report")
+	}
+	if err := os.WriteFile(path.Join(outputDir, soak.ReportFileName), reportJSON, 0644); err != nil {
+		return errors.Wrap(err, "failed to write soak report")
+	}
 	return nil
 }
 
+// writeTestMetadata replaces the runs of this benchmark run in the aggregate
+// metadata file, keeping the runs of every other benchmark run.
+func (s *service) writeTestMetadata(testPlan benchmark.RunGroup) error {
+	return s.metadataFile.Update(func(metadata *benchmark.RunGroup) error {
+		var runs []benchmark.Run
+
+		// remove all runs that have the same benchmark run id
+		for _, run := range metadata.Runs {
+			if run.TestConfig["BenchmarkRun"] != testPlan.Runs[0].TestConfig["BenchmarkRun"] {
+				runs = append(runs, run)
+			}
+		}
+
+		runs = append(runs, testPlan.Runs...)
+
+		*metadata = benchmark.RunGroup{
+			Runs: runs,
+		}
+		return nil
+	})
+}
+
+// writeRunMetadata writes the one-run <outputDir>/metadata.json of the report
+// data contract next to the output of a finished run. It is replaced
+// atomically, so readers see either the previous or the new document.
+func (s *service) writeRunMetadata(run benchmark.Run) error {
+	return sink.WriteRunMetadata(context.Background(), sink.NewLocalSink(s.config.OutputDir()), run)
+}
+
 func (s *service) Run(ctx context.Context) error {
 	s.log.Info("Starting")
 
@@ -579,6 +754,7 @@ func (s *service) Run(ctx context.Context) error {
 
 	numSuccess := 0
 	numFailure := 0
+	numPublishFailure := 0
 
 	var testPlans []benchmark.TestPlan
 
@@ -598,6 +774,26 @@ func (s *service) Run(ctx context.Context) error {
 		return errors.Wrap(err, "failed to create output directory")
 	}
 
+	if port := s.config.MetricsPort(); port > 0 {
+		s.exporter = exporter.NewExporter(s.log)
+		if err := s.exporter.Start(fmt.Sprintf(":%d", port)); err != nil {
+			return errors.Wrap(err, "failed to start metrics exporter")
+		}
+		defer func() {
+			if err := s.exporter.Stop(context.Background()); err != nil {
+				s.log.Warn("failed to stop metrics exporter", "err", err)
+			}
+		}()
+	}
+
+	if uri := s.config.ResultSink(); uri != "" {
+		s.sink, err = s.newResultSink(uri)
+		if err != nil {
+			return errors.Wrap(err, "failed to create result sink")
+		}
+		s.log.Info("Publishing results", "sink", uri)
+	}
+
 	// Generate or use provided BenchmarkRunID for this session
 	var benchmarkRunID string
 	if s.config.BenchmarkRunID() != "" {
@@ -660,6 +856,10 @@ func (s *service) Run(ctx context.Context) error {
 		transactionPayloads[w.ID] = w
 	}
 
+	if err := payload.ResolveMixes(transactionPayloads); err != nil {
+		return errors.Wrap(err, "failed to resolve payload mixes")
+	}
+
 outerLoop:
 	for _, testPlan := range testPlans {
 		err = s.writeTestMetadata(metadata)
@@ -676,18 +876,36 @@ outerLoop:
 				return errors.Wrap(err, "failed to create output directory")
 			}
 
-			metricSummary, err := s.runTest(ctx, c.Params, s.config.DataDir(), outputDir, testPlan.Snapshot, testPlan.ProofProgram, transactionPayloads[c.Params.PayloadID], testPlan.Datadir, testPlan.Mode, config.FlashblocksBlockTime(), config.FlashblocksLeewayTime())
+			if s.exporter != nil {
+				s.exporter.SetRun(exporter.RunInfo{
+					RunID:          c.ID,
+					BenchmarkRunID: benchmarkRunID,
+					NodeType:       c.Params.NodeType,
+					Payload:        c.Params.PayloadID,
+				})
+			}
+
+			var metricSummary *benchmark.RunResult
+			if testPlan.Search != nil {
+				metricSummary, err = s.runSearch(ctx, testPlan, c.Params, outputDir, transactionPayloads[c.Params.PayloadID], config.FlashblocksBlockTime(), config.FlashblocksLeewayTime())
+			} else {
+				metricSummary, err = s.runTest(ctx, c.Params, s.config.DataDir(), outputDir, testPlan.Snapshot, testPlan.ProofProgram, transactionPayloads[c.Params.PayloadID], testPlan.Datadir, testPlan.Mode, testPlan.Scrape, testPlan.Soak, config.FlashblocksBlockTime(), config.FlashblocksLeewayTime())
+			}
 			if err != nil {
 				log.Error("Failed to run test", "err", err)
-				metricSummary = &benchmark.RunResult{
-					Success:  false,
-					Complete: true,
+				// a failed search still reports its convergence history
+				if metricSummary == nil {
+					metricSummary = &benchmark.RunResult{
+						Success:  false,
+						Complete: true,
+					}
 				}
 				numFailure++
 			} else {
 				numSuccess++
 			}
 			applyClientVersion(&metadata.Runs[runIdx], metricSummary, os.Getenv("BASE_BENCH_CLIENT_VERSION"))
+			applySearchGasLimit(&metadata.Runs[runIdx], metricSummary)
 			metadata.AddResult(runIdx, *metricSummary)
 
 			err = s.writeTestMetadata(metadata)
@@ -695,6 +913,21 @@ outerLoop:
 				return errors.Wrap(err, "failed to write test metadata")
 			}
 
+			err = s.writeRunMetadata(metadata.Runs[runIdx])
+			if err != nil {
+				return errors.Wrap(err, "failed to write run metadata")
+			}
+
+			if s.sink != nil {
+				// publish even if the benchmark was interrupted so the runs
+				// that did finish are not lost
+				err = sink.PublishRun(context.WithoutCancel(ctx), s.sink, s.config.OutputDir(), metadata.Runs[runIdx])
+				if err != nil {
+					s.log.Error("Failed to publish run", "run", c.ID, "err", err)
+					numPublishFailure++
+				}
+			}
+
 			runIdx++
 
 			select {
@@ -728,10 +961,41 @@ outerLoop:
 	if numFailure > 0 {
 		return fmt.Errorf("failed to run %d tests", numFailure)
 	}
+	if numPublishFailure > 0 {
+		return fmt.Errorf("failed to publish %d runs", numPublishFailure)
+	}
 
 	return nil
 }
 
+// newResultSink creates the configured result sink. A local sink must not be
+// the output directory itself, since runs are published from there.
+func (s *service) newResultSink(uri string) (sink.ResultSink, error) {
+	resultSink, err := sink.NewResultSink(uri, sink.Options{
+		S3Endpoint: s.config.S3Endpoint(),
+		S3Region:   s.config.S3Region(),
+		HTTPToken:  s.config.ResultSinkToken(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if local, ok := resultSink.(*sink.LocalSink); ok {
+		root, err := filepath.Abs(local.Root())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve result sink")
+		}
+		outputDir, err := filepath.Abs(s.config.OutputDir())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve output directory")
+		}
+		if root == outputDir {
+			return nil, errors.New("result sink must not be the output directory")
+		}
+	}
+	return resultSink, nil
+}
+
 // applyClientVersion stamps the client version onto a single run's
 // result + TestConfig before it is recorded into the metadata. The
 // envOverride parameter, when non-empty, takes precedence over the
@@ -754,3 +1018,16 @@ func applyClientVersion(run *benchmark.Run, result *benchmark.RunResult, envOver
 	}
 	run.TestConfig["ClientVersion"] = result.ClientVersion
 }
+
+// applySearchGasLimit records the highest sustained gas limit of a saturation
+// search as the gas limit of its run, since the metrics of the run were
+// measured at that gas limit.
+func applySearchGasLimit(run *benchmark.Run, result *benchmark.RunResult) {
+	if result.Search == nil || result.Search.MaxGasLimit == 0 {
+		return
+	}
+	if run.TestConfig == nil {
+		run.TestConfig = make(map[string]interface{})
+	}
+	run.TestConfig["GasLimit"] = result.Search.MaxGasLimit
+}
@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"os"
+	"path"
 	"testing"
 
 	"github.com/base/base-bench/runner/benchmark"
@@ -70,3 +72,53 @@ func TestApplyClientVersion_PreservesOtherTestConfigKeys(t *testing.T) {
 		t.Errorf("ClientVersion not stamped, got %v", got)
 	}
 }
+
+func TestApplySearchGasLimit(t *testing.T) {
+	run := &benchmark.Run{}
+	result := &benchmark.RunResult{Search: &benchmark.SearchResult{MaxGasLimit: 120_000_000}}
+	applySearchGasLimit(run, result)
+
+	if got := run.TestConfig["GasLimit"]; got != uint64(120_000_000) {
+		t.Fatalf("TestConfig[GasLimit]=%v want %d", got, 120_000_000)
+	}
+}
+
+func TestApplySearchGasLimit_NoneSustained(t *testing.T) {
+	run := &benchmark.Run{}
+	applySearchGasLimit(run, &benchmark.RunResult{Search: &benchmark.SearchResult{}})
+	applySearchGasLimit(run, &benchmark.RunResult{})
+
+	if _, ok := run.TestConfig["GasLimit"]; ok {
+		t.Fatalf("TestConfig[GasLimit] should not be set, got %v", run.TestConfig["GasLimit"])
+	}
+}
+
+func TestCopySearchStepMetrics(t *testing.T) {
+	outputDir := t.TempDir()
+	stepDir := path.Join(outputDir, "step-01")
+	if err := os.MkdirAll(stepDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"metrics-sequencer.json", "metrics-validator.json", "logs-sequencer.gz"} {
+		if err := os.WriteFile(path.Join(stepDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := copySearchStepMetrics(stepDir, outputDir); err != nil {
+		t.Fatalf("copySearchStepMetrics: %v", err)
+	}
+
+	for _, name := range []string{"metrics-sequencer.json", "metrics-validator.json"} {
+		data, err := os.ReadFile(path.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("%s was not copied: %v", name, err)
+		}
+		if string(data) != name {
+			t.Fatalf("%s=%q want %q", name, data, name)
+		}
+	}
+	if _, err := os.Stat(path.Join(outputDir, "logs-sequencer.gz")); !os.IsNotExist(err) {
+		t.Fatalf("logs-sequencer.gz should not be copied, stat err=%v", err)
+	}
+}
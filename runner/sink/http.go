@@ -0,0 +1,61 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPSink uploads each result with a PUT request to <baseURL>/<key>. It
+// works with upload services and with pre-authorized bucket endpoints that
+// accept plain PUTs.
+type HTTPSink struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewHTTPSink creates a sink that uploads below baseURL. When token is set it
+// is sent as a bearer token.
+func NewHTTPSink(baseURL string, token string) *HTTPSink {
+	return &HTTPSink{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  http.DefaultClient,
+	}
+}
+
+func (s *HTTPSink) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	target := s.baseURL + "/" + strings.TrimLeft(key, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create upload request for %s", key)
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.token))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload %s", key)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("failed to upload %s: %s: %s", key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LocalSink writes results to a local directory, such as a mounted network
+// share. Files are written to a temporary file and renamed into place so a
+// reader never sees a partial file.
+type LocalSink struct {
+	root string
+}
+
+// NewLocalSink creates a sink that writes below root.
+func NewLocalSink(root string) *LocalSink {
+	return &LocalSink{
+		root: root,
+	}
+}
+
+// Root returns the directory the sink writes to.
+func (s *LocalSink) Root() string {
+	return s.root
+}
+
+func (s *LocalSink) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(s.root, filepath.FromSlash(key))
+	rel, err := filepath.Rel(s.root, filename)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("key %q is outside of the result sink", key)
+	}
+
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create result directory")
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary result file")
+	}
+	defer func() {
+		// no-op once the file has been renamed into place
+		_ = os.Remove(tmp.Name())
+	}()
+
+	written, err := io.Copy(tmp, body)
+	if err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "failed to write %s", key)
+	}
+	if size >= 0 && written != size {
+		_ = tmp.Close()
+		return errors.Errorf("wrote %d bytes of %s, expected %d", written, key, size)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "failed to sync %s", key)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close %s", key)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return errors.Wrapf(err, "failed to set permissions of %s", key)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return errors.Wrapf(err, "failed to move %s into place", key)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/base/base-bench/runner/benchmark"
+	"github.com/pkg/errors"
+)
+
+// PublishRun uploads the output directory of a finished run and then its
+// metadata.json. The metadata document is the commit signal of the report
+// data contract, so it is only written once every other file of the run has
+// been stored, and not at all if any upload fails.
+func PublishRun(ctx context.Context, sink ResultSink, outputDir string, run benchmark.Run) error {
+	if err := run.Validate(); err != nil {
+		return errors.Wrap(err, "run does not match the report data contract")
+	}
+
+	runDir := filepath.Join(outputDir, run.OutputDir)
+	files, err := listRunFiles(runDir)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range files {
+		if err := putFile(ctx, sink, path.Join(run.OutputDir, rel), filepath.Join(runDir, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+
+	return WriteRunMetadata(ctx, sink, run)
+}
+
+// WriteRunMetadata stores the one-run metadata.json of a run under its output
+// directory.
+func WriteRunMetadata(ctx context.Context, sink ResultSink, run benchmark.Run) error {
+	data, err := json.MarshalIndent(benchmark.NewRunMetadata(run), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode run metadata")
+	}
+	data = append(data, '\n')
+
+	key := path.Join(run.OutputDir, benchmark.RunMetadataFileName)
+	if err := sink.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+		return errors.Wrap(err, "failed to write run metadata")
+	}
+	return nil
+}
+
+// listRunFiles returns the slash-separated paths, relative to runDir, of the
+// files to upload before the run metadata. Hidden files, such as partially
+// written temporary files, and any local metadata.json are skipped.
+func listRunFiles(runDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(runDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != runDir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(runDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == benchmark.RunMetadataFileName {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list run output")
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func putFile(ctx context.Context, sink ResultSink, key string, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrap(err, "failed to open run output")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to stat run output")
+	}
+
+	return sink.Put(ctx, key, f, info.Size(), contentType(key))
+}
+
+// contentType returns the content type a file is served with.
+func contentType(key string) string {
+	switch {
+	case strings.HasSuffix(key, ".gz"):
+		return "application/gzip"
+	case strings.HasSuffix(key, ".jsonl"):
+		return "application/x-ndjson"
+	case strings.HasSuffix(key, ".json"):
+		return "application/json"
+	}
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,86 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+)
+
+// S3Sink uploads results to a bucket of AWS S3 or an S3-compatible store
+// such as MinIO. Credentials are read, in order, from the AWS_* and MINIO_*
+// environment variables, the shared AWS credentials file and the instance
+// role.
+type S3Sink struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Sink creates a sink that uploads below prefix in bucket.
+func NewS3Sink(endpoint string, region string, bucket string, prefix string) (*S3Sink, error) {
+	host, secure, err := parseS3Endpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := minio.New(host, &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		}),
+		Secure: secure,
+		Region: region,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create S3 client")
+	}
+
+	return &S3Sink{
+		client: client,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}, nil
+}
+
+// parseS3Endpoint splits an endpoint like http://localhost:9000 into the host
+// the S3 client expects and whether to use TLS.
+func parseS3Endpoint(endpoint string) (string, bool, error) {
+	if endpoint == "" {
+		return "s3.amazonaws.com", true, nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		return strings.TrimRight(endpoint, "/"), true, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false, errors.Wrap(err, "invalid S3 endpoint")
+	}
+	switch u.Scheme {
+	case "http":
+		return u.Host, false, nil
+	case "https":
+		return u.Host, true, nil
+	default:
+		return "", false, errors.Errorf("unsupported S3 endpoint scheme %q", u.Scheme)
+	}
+}
+
+func (s *S3Sink) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	objectKey := joinKey(s.prefix, key)
+	_, err := s.client.PutObject(ctx, s.bucket, objectKey, body, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload s3://%s/%s", s.bucket, objectKey)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/base/base-bench/runner/benchmark"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/require"
+)
+
+// TestS3SinkPublishRun runs against an S3-compatible store such as a local
+// MinIO server:
+//
+//	docker run -p 9000:9000 -e MINIO_ROOT_USER=minioadmin -e MINIO_ROOT_PASSWORD=minioadmin minio/minio server /data
+//	MINIO_ACCESS_KEY=minioadmin MINIO_SECRET_KEY=minioadmin BASE_BENCH_TEST_S3_ENDPOINT=http://localhost:9000 go test ./runner/sink/
+func TestS3SinkPublishRun(t *testing.T) {
+	endpoint := os.Getenv("BASE_BENCH_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("BASE_BENCH_TEST_S3_ENDPOINT is not set")
+	}
+	bucket := os.Getenv("BASE_BENCH_TEST_S3_BUCKET")
+	if bucket == "" {
+		bucket = "base-bench-test"
+	}
+	prefix := "sink-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	sink, err := NewS3Sink(endpoint, "", bucket, prefix)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	exists, err := sink.client.BucketExists(ctx, bucket)
+	require.NoError(t, err)
+	if !exists {
+		require.NoError(t, sink.client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}))
+	}
+
+	outputDir := t.TempDir()
+	run := testRun()
+	writeRunOutput(t, outputDir, run)
+	require.NoError(t, PublishRun(ctx, sink, outputDir, run))
+
+	metrics, err := sink.client.StatObject(ctx, bucket, path.Join(prefix, run.OutputDir, "metrics-sequencer.json"), minio.StatObjectOptions{})
+	require.NoError(t, err)
+	metadata, err := sink.client.StatObject(ctx, bucket, path.Join(prefix, run.OutputDir, benchmark.RunMetadataFileName), minio.StatObjectOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "application/json", metadata.ContentType)
+	require.False(t, metadata.LastModified.Before(metrics.LastModified), "metadata.json must be uploaded last")
+
+	obj, err := sink.client.GetObject(ctx, bucket, path.Join(prefix, run.OutputDir, benchmark.RunMetadataFileName), minio.GetObjectOptions{})
+	require.NoError(t, err)
+	defer func() {
+		_ = obj.Close()
+	}()
+	data, err := io.ReadAll(obj)
+	require.NoError(t, err)
+
+	var group benchmark.RunGroup
+	require.NoError(t, json.Unmarshal(data, &group))
+	require.Len(t, group.Runs, 1)
+	require.Equal(t, run.ID, group.Runs[0].ID)
+}
@@ -0,0 +1,74 @@
+// Package sink publishes the output of finished benchmark runs to where the
+// report reads it from, following the layout and upload order described in
+// docs/report-data-contract.md.
+package sink
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ResultSink stores benchmark output files. Keys are slash-separated paths
+// relative to the root of the sink, e.g. "<outputDir>/metrics-sequencer.json".
+type ResultSink interface {
+	// Put stores size bytes read from body under key. A key that already
+	// exists is replaced.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+}
+
+// Options configures the sinks that talk to remote services.
+type Options struct {
+	// S3Endpoint is the endpoint of s3:// sinks. A URL without a scheme is
+	// assumed to use https.
+	S3Endpoint string
+	// S3Region is the bucket region of s3:// sinks. It is discovered from the
+	// bucket when empty.
+	S3Region string
+	// HTTPToken is sent as a bearer token with uploads to http(s) sinks.
+	HTTPToken string
+}
+
+// NewResultSink creates the sink described by uri:
+//
+//   - s3://bucket/prefix uploads to an S3-compatible bucket
+//   - http://host/path and https://host/path upload with HTTP PUT requests
+//   - file:///path or a plain path writes to a local directory
+func NewResultSink(uri string, opts Options) (ResultSink, error) {
+	if uri == "" {
+		return nil, errors.New("result sink is empty")
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// not a URL (or a Windows drive letter), so treat it as a local path
+		return NewLocalSink(uri), nil
+	}
+
+	switch u.Scheme {
+	case "file":
+		return NewLocalSink(u.Path), nil
+	case "s3":
+		if u.Host == "" {
+			return nil, errors.Errorf("result sink %s has no bucket", uri)
+		}
+		return NewS3Sink(opts.S3Endpoint, opts.S3Region, u.Host, strings.Trim(u.Path, "/"))
+	case "http", "https":
+		return NewHTTPSink(uri, opts.HTTPToken), nil
+	default:
+		return nil, errors.Errorf("unsupported result sink scheme %q", u.Scheme)
+	}
+}
+
+// joinKey joins a key prefix and key with a single slash.
+func joinKey(prefix string, key string) string {
+	prefix = strings.Trim(prefix, "/")
+	key = strings.TrimLeft(key, "/")
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
@@ -0,0 +1,287 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/base/base-bench/runner/benchmark"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedPut struct {
+	key         string
+	body        []byte
+	contentType string
+}
+
+// recordingSink records puts in order and fails puts of failKey.
+type recordingSink struct {
+	mu      sync.Mutex
+	puts    []recordedPut
+	failKey string
+}
+
+func (s *recordingSink) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if key == s.failKey {
+		return errors.New("upload failed")
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return errors.Errorf("read %d bytes, expected %d", len(data), size)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.puts = append(s.puts, recordedPut{key: key, body: data, contentType: contentType})
+	return nil
+}
+
+func (s *recordingSink) keys() []string {
+	keys := make([]string, 0, len(s.puts))
+	for _, put := range s.puts {
+		keys = append(keys, put.key)
+	}
+	return keys
+}
+
+func testRun() benchmark.Run {
+	createdAt := time.Date(2026, 2, 24, 20, 43, 54, 0, time.UTC)
+	return benchmark.Run{
+		ID:         "test-1",
+		SourceFile: "./mainnet-config.yml",
+		OutputDir:  "test-1-1",
+		TestName:   "Mainnet",
+		TestConfig: map[string]interface{}{
+			benchmark.BenchmarkRunTag: "test-1",
+			"NodeType":                "reth",
+		},
+		Result: &benchmark.RunResult{
+			Success:  true,
+			Complete: true,
+		},
+		CreatedAt: &createdAt,
+	}
+}
+
+func writeRunOutput(t *testing.T, outputDir string, run benchmark.Run) {
+	runDir := filepath.Join(outputDir, run.OutputDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(runDir, benchmark.LoadTestResultsDir), 0755))
+	files := map[string]string{
+		"metrics-sequencer.json": `[{"BlockNumber":1}]`,
+		"metrics-validator.json": `[{"BlockNumber":1}]`,
+		"logs-sequencer.gz":      "logs",
+		"result-sequencer.json":  `{"success":true}`,
+		filepath.Join(benchmark.LoadTestResultsDir, benchmark.LoadTestResultFileName): `{}`,
+		benchmark.RunMetadataFileName:   `{"runs":[]}`,
+		".metrics-sequencer.json.tmp-1": "partial",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(runDir, name), []byte(content), 0644))
+	}
+}
+
+func TestNewResultSink(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    interface{}
+		wantErr string
+	}{
+		{uri: "/mnt/results", want: &LocalSink{}},
+		{uri: "./results", want: &LocalSink{}},
+		{uri: "file:///mnt/results", want: &LocalSink{}},
+		{uri: "s3://bench-results/base", want: &S3Sink{}},
+		{uri: "https://uploads.example.com/results", want: &HTTPSink{}},
+		{uri: "", wantErr: "result sink is empty"},
+		{uri: "s3:///base", wantErr: "has no bucket"},
+		{uri: "ftp://example.com/results", wantErr: "unsupported result sink scheme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			sink, err := NewResultSink(tt.uri, Options{S3Endpoint: "http://localhost:9000"})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, tt.want, sink)
+		})
+	}
+
+	sink, err := NewResultSink("file:///mnt/results", Options{})
+	require.NoError(t, err)
+	require.Equal(t, "/mnt/results", sink.(*LocalSink).Root())
+
+	sink, err = NewResultSink("s3://bench-results/base/", Options{})
+	require.NoError(t, err)
+	require.Equal(t, "bench-results", sink.(*S3Sink).bucket)
+	require.Equal(t, "base", sink.(*S3Sink).prefix)
+}
+
+func TestParseS3Endpoint(t *testing.T) {
+	host, secure, err := parseS3Endpoint("http://localhost:9000")
+	require.NoError(t, err)
+	require.Equal(t, "localhost:9000", host)
+	require.False(t, secure)
+
+	host, secure, err = parseS3Endpoint("storage.googleapis.com")
+	require.NoError(t, err)
+	require.Equal(t, "storage.googleapis.com", host)
+	require.True(t, secure)
+
+	host, secure, err = parseS3Endpoint("")
+	require.NoError(t, err)
+	require.Equal(t, "s3.amazonaws.com", host)
+	require.True(t, secure)
+
+	_, _, err = parseS3Endpoint("ftp://localhost:9000")
+	require.Error(t, err)
+}
+
+func TestLocalSinkPut(t *testing.T) {
+	root := t.TempDir()
+	sink := NewLocalSink(root)
+
+	body := []byte(`{"runs":[]}`)
+	err := sink.Put(context.Background(), "test-1-1/metadata.json", bytes.NewReader(body), int64(len(body)), "application/json")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(root, "test-1-1", "metadata.json"))
+	require.NoError(t, err)
+	require.Equal(t, body, data)
+
+	entries, err := os.ReadDir(filepath.Join(root, "test-1-1"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files should be renamed into place")
+
+	err = sink.Put(context.Background(), "../escape.json", bytes.NewReader(body), int64(len(body)), "application/json")
+	require.ErrorContains(t, err, "outside of the result sink")
+
+	err = sink.Put(context.Background(), "test-1-1/short.json", bytes.NewReader(body), int64(len(body))+1, "application/json")
+	require.Error(t, err)
+	_, err = os.Stat(filepath.Join(root, "test-1-1", "short.json"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestHTTPSinkPut(t *testing.T) {
+	var gotMethod, gotPath, gotType, gotAuth string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "forbidden.json") {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		gotType = r.Header.Get("Content-Type")
+		gotAuth = r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL+"/results/", "secret")
+	body := []byte(`[{"BlockNumber":1}]`)
+	err := sink.Put(context.Background(), "test-1-1/metrics-sequencer.json", bytes.NewReader(body), int64(len(body)), "application/json")
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPut, gotMethod)
+	require.Equal(t, "/results/test-1-1/metrics-sequencer.json", gotPath)
+	require.Equal(t, "application/json", gotType)
+	require.Equal(t, "Bearer secret", gotAuth)
+	require.Equal(t, body, gotBody)
+
+	err = sink.Put(context.Background(), "test-1-1/forbidden.json", bytes.NewReader(body), int64(len(body)), "application/json")
+	require.ErrorContains(t, err, "403")
+	require.ErrorContains(t, err, "denied")
+}
+
+func TestPublishRunUploadsMetadataLast(t *testing.T) {
+	outputDir := t.TempDir()
+	run := testRun()
+	writeRunOutput(t, outputDir, run)
+
+	sink := &recordingSink{}
+	require.NoError(t, PublishRun(context.Background(), sink, outputDir, run))
+
+	require.Equal(t, []string{
+		"test-1-1/load-tests/load-test-result.json",
+		"test-1-1/logs-sequencer.gz",
+		"test-1-1/metrics-sequencer.json",
+		"test-1-1/metrics-validator.json",
+		"test-1-1/result-sequencer.json",
+		"test-1-1/metadata.json",
+	}, sink.keys())
+
+	require.Equal(t, "application/gzip", sink.puts[1].contentType)
+	require.Equal(t, "application/json", sink.puts[2].contentType)
+
+	last := sink.puts[len(sink.puts)-1]
+	require.Equal(t, "application/json", last.contentType)
+
+	var metadata benchmark.RunGroup
+	require.NoError(t, json.Unmarshal(last.body, &metadata))
+	require.Len(t, metadata.Runs, 1)
+	require.Equal(t, run.ID, metadata.Runs[0].ID)
+	require.Equal(t, run.OutputDir, metadata.Runs[0].OutputDir)
+	require.Equal(t, "test-1", metadata.Runs[0].TestConfig[benchmark.BenchmarkRunTag])
+	require.True(t, metadata.Runs[0].Result.Complete)
+}
+
+func TestPublishRunSkipsMetadataOnFailure(t *testing.T) {
+	outputDir := t.TempDir()
+	run := testRun()
+	writeRunOutput(t, outputDir, run)
+
+	sink := &recordingSink{failKey: "test-1-1/metrics-validator.json"}
+	err := PublishRun(context.Background(), sink, outputDir, run)
+	require.ErrorContains(t, err, "upload failed")
+	require.NotContains(t, sink.keys(), "test-1-1/metadata.json")
+}
+
+func TestPublishRunRejectsIncompleteMetadata(t *testing.T) {
+	outputDir := t.TempDir()
+	run := testRun()
+	writeRunOutput(t, outputDir, run)
+	delete(run.TestConfig, benchmark.BenchmarkRunTag)
+
+	sink := &recordingSink{}
+	err := PublishRun(context.Background(), sink, outputDir, run)
+	require.ErrorContains(t, err, "testConfig.BenchmarkRun")
+	require.Empty(t, sink.puts)
+}
+
+func TestPublishRunToLocalSink(t *testing.T) {
+	outputDir := t.TempDir()
+	run := testRun()
+	writeRunOutput(t, outputDir, run)
+
+	root := t.TempDir()
+	require.NoError(t, PublishRun(context.Background(), NewLocalSink(root), outputDir, run))
+
+	data, err := os.ReadFile(filepath.Join(root, "test-1-1", "metrics-sequencer.json"))
+	require.NoError(t, err)
+	require.Equal(t, `[{"BlockNumber":1}]`, string(data))
+
+	data, err = os.ReadFile(filepath.Join(root, "test-1-1", benchmark.RunMetadataFileName))
+	require.NoError(t, err)
+	var metadata benchmark.RunGroup
+	require.NoError(t, json.Unmarshal(data, &metadata))
+	require.Len(t, metadata.Runs, 1)
+
+	_, err = os.Stat(filepath.Join(root, "test-1-1", ".metrics-sequencer.json.tmp-1"))
+	require.True(t, os.IsNotExist(err))
+}
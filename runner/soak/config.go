@@ -0,0 +1,38 @@
+package soak
+
+import "fmt"
+
+const (
+	// DefaultSampleInterval is the number of blocks between samples if not
+	// configured.
+	DefaultSampleInterval = 100
+
+	// ReportArtifactKey and ReportFileName are the artifact key and file name
+	// of the samples and trends of a soak run.
+	ReportArtifactKey = "soak"
+	ReportFileName    = "soak.json"
+)
+
+// Config is the user-facing YAML configuration of a soak run, which samples
+// the state growth of the sequencer while it builds blocks.
+type Config struct {
+	// SampleInterval is the number of blocks between samples.
+	SampleInterval *int `yaml:"sample_interval"`
+	// CountState counts the accounts and storage slots created by tracing
+	// every measured block once the measured blocks are done.
+	CountState bool `yaml:"count_state"`
+}
+
+func (c *Config) Check() error {
+	if c.SampleInterval != nil && *c.SampleInterval <= 0 {
+		return fmt.Errorf("sample_interval must be positive, got %d", *c.SampleInterval)
+	}
+	return nil
+}
+
+func (c *Config) sampleInterval() int {
+	if c.SampleInterval == nil {
+		return DefaultSampleInterval
+	}
+	return *c.SampleInterval
+}
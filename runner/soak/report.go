@@ -0,0 +1,126 @@
+package soak
+
+import "sort"
+
+// Report holds the samples of a soak run and how each sampled metric trends
+// as the state grows.
+type Report struct {
+	Samples []Sample `json:"samples"`
+	Trends  []Trend  `json:"trends"`
+}
+
+// Trend is the least-squares slope of a metric over the samples of a soak run
+// against the datadir size and, if the state was counted, the storage slots
+// created.
+type Trend struct {
+	Metric string `json:"metric"`
+	// First and Last are the averages of the metric in the first and last
+	// sample.
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+	// PerGB is the change of the metric per GB of datadir growth.
+	PerGB float64 `json:"perGB"`
+	// PerMillionSlots is the change of the metric per million storage slots
+	// created.
+	PerMillionSlots float64 `json:"perMillionSlots,omitempty"`
+}
+
+// NewReport computes the trend of every metric in the samples.
+func NewReport(samples []Sample, countState bool) *Report {
+	report := &Report{
+		Samples: samples,
+		Trends:  []Trend{},
+	}
+	if len(samples) == 0 {
+		return report
+	}
+
+	seen := make(map[string]bool)
+	for _, sample := range samples {
+		for name := range sample.Metrics {
+			seen[name] = true
+		}
+	}
+
+	for _, name := range sortedKeys(seen) {
+		var gb, slots, values []float64
+		for _, sample := range samples {
+			value, ok := sample.Metrics[name]
+			if !ok {
+				continue
+			}
+			gb = append(gb, float64(sample.DataDirBytes)/1e9)
+			slots = append(slots, float64(sample.SlotsCreated)/1e6)
+			values = append(values, value)
+		}
+
+		trend := Trend{
+			Metric: name,
+			First:  values[0],
+			Last:   values[len(values)-1],
+			PerGB:  slope(gb, values),
+		}
+		if countState {
+			trend.PerMillionSlots = slope(slots, values)
+		}
+		report.Trends = append(report.Trends, trend)
+	}
+	return report
+}
+
+// slope returns the least-squares slope of y over x, or zero if x does not
+// vary.
+func slope(x []float64, y []float64) float64 {
+	n := float64(len(x))
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var covariance, variance float64
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		variance += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Summary is the outcome of a soak run recorded in its result.
+type Summary struct {
+	Samples int `json:"samples"`
+	// DataDirBytes, AccountsCreated and SlotsCreated are from the last sample.
+	DataDirBytes    int64   `json:"dataDirBytes"`
+	AccountsCreated uint64  `json:"accountsCreated,omitempty"`
+	SlotsCreated    uint64  `json:"slotsCreated,omitempty"`
+	Trends          []Trend `json:"trends"`
+}
+
+// Summary summarizes the report.
+func (r *Report) Summary() *Summary {
+	summary := &Summary{
+		Samples: len(r.Samples),
+		Trends:  r.Trends,
+	}
+	if len(r.Samples) > 0 {
+		last := r.Samples[len(r.Samples)-1]
+		summary.DataDirBytes = last.DataDirBytes
+		summary.AccountsCreated = last.AccountsCreated
+		summary.SlotsCreated = last.SlotsCreated
+	}
+	return summary
+}
@@ -0,0 +1,234 @@
+package soak
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/base/base-bench/runner/metrics"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Sample is the state of the sequencer after a block of a soak run.
+type Sample struct {
+	// Block is the index of the last measured block of the sample.
+	Block uint64 `json:"block"`
+	// Elapsed is the time since the first measured block in seconds.
+	Elapsed float64 `json:"elapsed"`
+	// DataDirBytes is the size of the sequencer datadir on disk.
+	DataDirBytes int64 `json:"dataDirBytes"`
+	// AccountsCreated and SlotsCreated are the number of accounts and storage
+	// slots created since the first measured block. They are only set if the
+	// state is counted.
+	AccountsCreated uint64 `json:"accountsCreated,omitempty"`
+	SlotsCreated    uint64 `json:"slotsCreated,omitempty"`
+	// Metrics are the averages of the trend metrics over the blocks since the
+	// previous sample.
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// StateCounter counts the accounts and storage slots created by a range of
+// blocks.
+type StateCounter interface {
+	CountCreated(ctx context.Context, fromBlock uint64, toBlock uint64) (accounts uint64, slots uint64, err error)
+}
+
+// Sampler samples the datadir size, created state and the averages of a set
+// of per-block metrics every few blocks of a soak run. The created state is
+// counted by tracing the sampled blocks in Finish, and the datadir is walked
+// in the background, so that neither pauses the measured blocks.
+type Sampler struct {
+	log          log.Logger
+	config       Config
+	dataDir      string
+	stateCounter StateCounter
+	metricNames  []string
+
+	// sizing serializes the datadir walks and sizes waits for them.
+	sizing sync.Mutex
+	sizes  sync.WaitGroup
+
+	mu            sync.Mutex
+	start         time.Time
+	blocks        int
+	nextToCount   uint64
+	sums          map[string]float64
+	counts        map[string]int
+	samples       []Sample
+	sampleRanges  []blockRange
+	started       bool
+	finished      bool
+	lastBlockSeen uint64
+}
+
+// blockRange is the inclusive range of chain blocks covered by a sample.
+type blockRange struct {
+	from uint64
+	to   uint64
+}
+
+// NewSampler creates a sampler of the datadir at dataDir that averages the
+// metrics named metricNames. stateCounter may be nil if the config does not
+// count the state.
+func NewSampler(log log.Logger, config Config, dataDir string, stateCounter StateCounter, metricNames []string) *Sampler {
+	return &Sampler{
+		log:          log,
+		config:       config,
+		dataDir:      dataDir,
+		stateCounter: stateCounter,
+		metricNames:  metricNames,
+		sums:         make(map[string]float64),
+		counts:       make(map[string]int),
+	}
+}
+
+// RecordBlock adds the metrics of a measured block, the blockNumber-th block
+// of the chain, and takes a sample every sample interval.
+func (s *Sampler) RecordBlock(blockNumber uint64, blockMetrics *metrics.BlockMetrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return
+	}
+	if !s.started {
+		s.started = true
+		s.start = time.Now()
+		s.nextToCount = blockNumber
+	}
+	s.blocks++
+	s.lastBlockSeen = blockNumber
+
+	for _, name := range s.metricNames {
+		if value, ok := blockMetrics.GetMetricFloat(name); ok {
+			s.sums[name] += value
+			s.counts[name]++
+		}
+	}
+
+	if s.blocks%s.config.sampleInterval() == 0 {
+		s.sample()
+	}
+}
+
+// Finish takes a last sample of the blocks since the previous one and, if
+// the config counts the state, counts the state created by the blocks of
+// each sample. It is called once the measured blocks are done, whether or not
+// the run succeeded.
+func (s *Sampler) Finish(ctx context.Context) {
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+
+	if s.started && s.blocks%s.config.sampleInterval() != 0 {
+		s.sample()
+	}
+	s.mu.Unlock()
+
+	s.sizes.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config.CountState && s.stateCounter != nil {
+		s.countState(ctx)
+	}
+}
+
+func (s *Sampler) sample() {
+	sample := Sample{
+		Block:   uint64(s.blocks),
+		Elapsed: time.Since(s.start).Seconds(),
+		Metrics: make(map[string]float64),
+	}
+
+	for name, sum := range s.sums {
+		sample.Metrics[name] = sum / float64(s.counts[name])
+	}
+	s.sums = make(map[string]float64)
+	s.counts = make(map[string]int)
+
+	s.samples = append(s.samples, sample)
+	s.sampleRanges = append(s.sampleRanges, blockRange{from: s.nextToCount, to: s.lastBlockSeen})
+	s.nextToCount = s.lastBlockSeen + 1
+
+	s.sizes.Add(1)
+	go s.measureDataDir(len(s.samples) - 1)
+}
+
+// measureDataDir sets the datadir size of the i-th sample. Walking a large
+// datadir takes a while, so it runs off the block loop; the size is that of
+// the datadir when the walk starts, shortly after the sampled block.
+func (s *Sampler) measureDataDir(i int) {
+	defer s.sizes.Done()
+
+	s.sizing.Lock()
+	size, err := DirSize(s.dataDir)
+	s.sizing.Unlock()
+	if err != nil {
+		s.log.Warn("Failed to measure datadir size", "dir", s.dataDir, "err", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples[i].DataDirBytes = size
+	s.log.Info("Soak sample", "block", s.samples[i].Block, "datadir_bytes", size)
+}
+
+// countState sets the accounts and storage slots created up to each sample.
+func (s *Sampler) countState(ctx context.Context) {
+	var accounts, slots uint64
+	for i, r := range s.sampleRanges {
+		if r.from <= r.to {
+			created, createdSlots, err := s.stateCounter.CountCreated(ctx, r.from, r.to)
+			if err != nil {
+				s.log.Warn("Failed to count created state", "from", r.from, "to", r.to, "err", err)
+			} else {
+				accounts += created
+				slots += createdSlots
+			}
+		}
+		s.samples[i].AccountsCreated = accounts
+		s.samples[i].SlotsCreated = slots
+	}
+	s.log.Info("Counted soak state", "accounts_created", accounts, "slots_created", slots)
+}
+
+// Report returns the samples and the trends of the metrics over them.
+func (s *Sampler) Report() *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return NewReport(s.samples, s.config.CountState)
+}
+
+// DirSize returns the total size of the regular files under dir.
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// files may be removed by the client while walking
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
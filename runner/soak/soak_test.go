@@ -0,0 +1,144 @@
+package soak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/base/base-bench/runner/metrics"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+type stubStateCounter struct {
+	ranges [][2]uint64
+}
+
+func (c *stubStateCounter) CountCreated(_ context.Context, fromBlock uint64, toBlock uint64) (uint64, uint64, error) {
+	c.ranges = append(c.ranges, [2]uint64{fromBlock, toBlock})
+	blocks := toBlock - fromBlock + 1
+	return blocks, 10 * blocks, nil
+}
+
+func TestSamplerSamplesEveryInterval(t *testing.T) {
+	dir := t.TempDir()
+	interval := 2
+	counter := &stubStateCounter{}
+	sampler := NewSampler(log.New(), Config{SampleInterval: &interval, CountState: true}, dir, counter, []string{"latency/get_payload"})
+
+	for i := uint64(0); i < 5; i++ {
+		require.NoError(t, os.WriteFile(path.Join(dir, fmt.Sprintf("block-%d", i)), make([]byte, 100), 0644))
+
+		m := metrics.NewBlockMetrics()
+		m.AddExecutionMetric("latency/get_payload", time.Duration(i+1)*time.Millisecond)
+		sampler.RecordBlock(100+i, m)
+	}
+	require.Empty(t, counter.ranges, "state should only be counted once the run is done")
+
+	sampler.Finish(context.Background())
+
+	report := sampler.Report()
+	require.Len(t, report.Samples, 3)
+	require.Equal(t, [][2]uint64{{100, 101}, {102, 103}, {104, 104}}, counter.ranges)
+
+	require.Equal(t, uint64(2), report.Samples[0].Block)
+	// the datadir is measured in the background, so later blocks may already
+	// have grown it
+	require.GreaterOrEqual(t, report.Samples[0].DataDirBytes, int64(200))
+	require.LessOrEqual(t, report.Samples[0].DataDirBytes, int64(500))
+	require.Equal(t, uint64(2), report.Samples[0].AccountsCreated)
+	require.Equal(t, uint64(20), report.Samples[0].SlotsCreated)
+	require.InDelta(t, 0.0015, report.Samples[0].Metrics["latency/get_payload"], 1e-9)
+
+	last := report.Samples[2]
+	require.Equal(t, uint64(5), last.Block)
+	require.Equal(t, int64(500), last.DataDirBytes)
+	require.Equal(t, uint64(50), last.SlotsCreated)
+	require.InDelta(t, 0.005, last.Metrics["latency/get_payload"], 1e-9)
+
+	summary := report.Summary()
+	require.Equal(t, 3, summary.Samples)
+	require.Equal(t, uint64(5), summary.AccountsCreated)
+	require.Len(t, summary.Trends, 1)
+}
+
+func TestReportTrends(t *testing.T) {
+	samples := []Sample{
+		{DataDirBytes: 1e9, SlotsCreated: 1e6, Metrics: map[string]float64{"latency": 0.010, "gas": 100}},
+		{DataDirBytes: 2e9, SlotsCreated: 2e6, Metrics: map[string]float64{"latency": 0.012, "gas": 100}},
+		{DataDirBytes: 3e9, SlotsCreated: 3e6, Metrics: map[string]float64{"latency": 0.014}},
+	}
+
+	report := NewReport(samples, true)
+	require.Len(t, report.Trends, 2)
+
+	gas := report.Trends[0]
+	require.Equal(t, "gas", gas.Metric)
+	require.Zero(t, gas.PerGB)
+
+	latency := report.Trends[1]
+	require.Equal(t, "latency", latency.Metric)
+	require.Equal(t, 0.010, latency.First)
+	require.Equal(t, 0.014, latency.Last)
+	require.InDelta(t, 0.002, latency.PerGB, 1e-12)
+	require.InDelta(t, 0.002, latency.PerMillionSlots, 1e-12)
+
+	require.Zero(t, NewReport(samples, false).Trends[1].PerMillionSlots)
+	require.Empty(t, NewReport(nil, false).Trends)
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "db"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "a"), make([]byte, 10), 0644))
+	require.NoError(t, os.WriteFile(path.Join(dir, "db", "b"), make([]byte, 32), 0644))
+
+	size, err := DirSize(dir)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), size)
+}
+
+type debugAPI struct {
+	traces map[uint64]string
+}
+
+func (api *debugAPI) TraceBlockByNumber(number hexutil.Uint64, _ map[string]interface{}) (json.RawMessage, error) {
+	return json.RawMessage(api.traces[uint64(number)]), nil
+}
+
+func TestTraceStateCounter(t *testing.T) {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("debug", &debugAPI{traces: map[uint64]string{
+		// a transfer to a new account
+		1: `[{"txHash": "0x01", "result": {
+			"pre": {"0x00000000000000000000000000000000000000aa": {"balance": "0x10", "nonce": 1}},
+			"post": {
+				"0x00000000000000000000000000000000000000aa": {"balance": "0x8", "nonce": 2},
+				"0x00000000000000000000000000000000000000bb": {"balance": "0x8"}
+			}}}]`,
+		// a contract call writing one new and one existing slot, and
+		// clearing another
+		2: `[{"txHash": "0x02", "result": {
+			"pre": {"0x00000000000000000000000000000000000000cc": {"balance": "0x0", "nonce": 1, "code": "0x00", "storage": {
+				"0x0000000000000000000000000000000000000000000000000000000000000002": "0x0000000000000000000000000000000000000000000000000000000000000005",
+				"0x0000000000000000000000000000000000000000000000000000000000000003": "0x0000000000000000000000000000000000000000000000000000000000000001"
+			}}},
+			"post": {"0x00000000000000000000000000000000000000cc": {"storage": {
+				"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000001",
+				"0x0000000000000000000000000000000000000000000000000000000000000002": "0x0000000000000000000000000000000000000000000000000000000000000006"
+			}}}}}]`,
+	}}))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	accounts, slots, err := NewTraceStateCounter(client).CountCreated(context.Background(), 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), accounts)
+	require.Equal(t, uint64(1), slots)
+}
@@ -0,0 +1,89 @@
+package soak
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TraceStateCounter counts created state with the prestate tracer in diff
+// mode, which both geth and reth support.
+type TraceStateCounter struct {
+	client *rpc.Client
+}
+
+// NewTraceStateCounter creates a state counter tracing blocks over client.
+func NewTraceStateCounter(client *rpc.Client) *TraceStateCounter {
+	return &TraceStateCounter{client: client}
+}
+
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+func (a *prestateAccount) empty() bool {
+	return a.Nonce == 0 && len(a.Code) == 0 && (a.Balance == nil || (*big.Int)(a.Balance).Sign() == 0)
+}
+
+type prestateDiff struct {
+	Pre  map[common.Address]*prestateAccount `json:"pre"`
+	Post map[common.Address]*prestateAccount `json:"post"`
+}
+
+type prestateTxResult struct {
+	Result prestateDiff `json:"result"`
+}
+
+// CountCreated implements StateCounter. An account is created if it was empty
+// or absent before a transaction, and a storage slot if it was zero.
+func (c *TraceStateCounter) CountCreated(ctx context.Context, fromBlock uint64, toBlock uint64) (uint64, uint64, error) {
+	var accounts, slots uint64
+	for number := fromBlock; number <= toBlock; number++ {
+		var txs []prestateTxResult
+		err := c.client.CallContext(ctx, &txs, "debug_traceBlockByNumber", hexutil.Uint64(number), map[string]interface{}{
+			"tracer": "prestateTracer",
+			"tracerConfig": map[string]interface{}{
+				"diffMode": true,
+			},
+		})
+		if err != nil {
+			return accounts, slots, fmt.Errorf("failed to trace block %d: %w", number, err)
+		}
+
+		for _, tx := range txs {
+			a, s := countCreated(tx.Result)
+			accounts += a
+			slots += s
+		}
+	}
+	return accounts, slots, nil
+}
+
+func countCreated(diff prestateDiff) (uint64, uint64) {
+	var accounts, slots uint64
+	for addr, post := range diff.Post {
+		pre, existed := diff.Pre[addr]
+		if !existed || pre.empty() {
+			if !post.empty() {
+				accounts++
+			}
+		}
+		for key, value := range post.Storage {
+			if value == (common.Hash{}) {
+				continue
+			}
+			if existed && pre.Storage[key] != (common.Hash{}) {
+				continue
+			}
+			slots++
+		}
+	}
+	return accounts, slots
+}
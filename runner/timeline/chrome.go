@@ -0,0 +1,151 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// traceEvent is an event of the Chrome trace event format, see
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU.
+// Ts and Dur are in microseconds, Ts relative to the start of the timeline.
+type traceEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	Ts    float64                `json:"ts"`
+	Dur   *float64               `json:"dur,omitempty"`
+	Pid   int                    `json:"pid"`
+	Tid   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// chromeTrace is the JSON object format of a Chrome trace.
+type chromeTrace struct {
+	TraceEvents     []traceEvent           `json:"traceEvents"`
+	DisplayTimeUnit string                 `json:"displayTimeUnit"`
+	OtherData       map[string]interface{} `json:"otherData,omitempty"`
+}
+
+// tracePid is the process of every event; tracks are threads of it.
+const tracePid = 1
+
+// trackOrder fixes the position of the well-known tracks. Other tracks follow
+// in the order they first appear.
+var trackOrder = []string{TrackRunner, TrackSequencer, TrackFlashblocks, TrackValidator}
+
+// ChromeTrace returns the timeline in the Chrome trace event format.
+func (t *Timeline) ChromeTrace() ([]byte, error) {
+	if t == nil {
+		return nil, fmt.Errorf("timeline is nil")
+	}
+
+	events := t.Events()
+
+	tids := make(map[string]int)
+	var tracks []string
+	addTrack := func(track string) {
+		if _, ok := tids[track]; !ok {
+			tids[track] = len(tids) + 1
+			tracks = append(tracks, track)
+		}
+	}
+	for _, track := range trackOrder {
+		for _, event := range events {
+			if event.Track == track {
+				addTrack(track)
+				break
+			}
+		}
+	}
+	for _, event := range events {
+		addTrack(event.Track)
+	}
+
+	trace := chromeTrace{
+		TraceEvents:     make([]traceEvent, 0, len(events)+len(tracks)+1),
+		DisplayTimeUnit: "ms",
+		OtherData: map[string]interface{}{
+			"name":      t.name,
+			"startTime": t.start.UTC().Format(time.RFC3339Nano),
+		},
+	}
+
+	trace.TraceEvents = append(trace.TraceEvents, traceEvent{
+		Name:  "process_name",
+		Phase: "M",
+		Pid:   tracePid,
+		Args:  map[string]interface{}{"name": t.name},
+	})
+	for _, track := range tracks {
+		trace.TraceEvents = append(trace.TraceEvents,
+			traceEvent{
+				Name:  "thread_name",
+				Phase: "M",
+				Pid:   tracePid,
+				Tid:   tids[track],
+				Args:  map[string]interface{}{"name": track},
+			},
+			traceEvent{
+				Name:  "thread_sort_index",
+				Phase: "M",
+				Pid:   tracePid,
+				Tid:   tids[track],
+				Args:  map[string]interface{}{"sort_index": tids[track]},
+			},
+		)
+	}
+
+	for _, event := range events {
+		te := traceEvent{
+			Name: event.Name,
+			Cat:  event.Category,
+			Ts:   microseconds(event.Start.Sub(t.start)),
+			Pid:  tracePid,
+			Tid:  tids[event.Track],
+			Args: event.Args,
+		}
+		if event.Instant {
+			te.Phase = "i"
+			te.Scope = "t"
+		} else {
+			te.Phase = "X"
+			dur := microseconds(event.Duration)
+			te.Dur = &dur
+		}
+		if event.Unfinished {
+			args := make(map[string]interface{}, len(event.Args)+1)
+			for k, v := range event.Args {
+				args[k] = v
+			}
+			args["unfinished"] = true
+			te.Args = args
+		}
+		trace.TraceEvents = append(trace.TraceEvents, te)
+	}
+
+	return json.Marshal(trace)
+}
+
+// WriteFile writes the timeline as a Chrome trace. It does nothing for a nil
+// timeline.
+func (t *Timeline) WriteFile(filename string) error {
+	if t == nil {
+		return nil
+	}
+
+	data, err := t.ChromeTrace()
+	if err != nil {
+		return fmt.Errorf("failed to encode timeline: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write timeline: %w", err)
+	}
+	return nil
+}
+
+func microseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1e3
+}
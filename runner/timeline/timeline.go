@@ -0,0 +1,181 @@
+// Package timeline records the lifecycle events of a benchmark run, such as
+// node start-up, funding, engine API calls and flashblock replay, and writes
+// them as a Chrome trace that can be opened in chrome://tracing or
+// https://ui.perfetto.dev.
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the timeline file in a run's output directory.
+const FileName = "timeline.json"
+
+// Tracks group the events of a run into rows of the trace.
+const (
+	TrackRunner      = "runner"
+	TrackSequencer   = "sequencer"
+	TrackValidator   = "validator"
+	TrackFlashblocks = "flashblocks"
+)
+
+// Event is a span, or an instant if Instant is set, on one track of the
+// timeline.
+type Event struct {
+	Track    string
+	Category string
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+	Instant  bool
+	// Unfinished is set for spans that had not ended when the timeline was
+	// read, e.g. a call that hung until the run was stopped.
+	Unfinished bool
+	Args       map[string]interface{}
+}
+
+// Timeline collects the events of one run. It is safe for concurrent use. All
+// methods are no-ops on a nil timeline, so components can record events
+// without checking whether a timeline is configured.
+type Timeline struct {
+	mu     sync.Mutex
+	name   string
+	start  time.Time
+	events []Event
+	open   map[*Span]struct{}
+	now    func() time.Time
+}
+
+// New creates an empty timeline. The name is shown as the process name of the
+// trace.
+func New(name string) *Timeline {
+	return &Timeline{
+		name:  name,
+		start: time.Now(),
+		open:  make(map[*Span]struct{}),
+		now:   time.Now,
+	}
+}
+
+// Instant records a point-in-time event. Args are alternating keys and values,
+// as for a logger.
+func (t *Timeline) Instant(track string, category string, name string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, Event{
+		Track:    track,
+		Category: category,
+		Name:     name,
+		Start:    t.now(),
+		Instant:  true,
+		Args:     argsMap(nil, args),
+	})
+}
+
+// Begin starts a span that is recorded once End is called. Args are
+// alternating keys and values, as for a logger.
+func (t *Timeline) Begin(track string, category string, name string, args ...interface{}) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &Span{
+		timeline: t,
+		event: Event{
+			Track:    track,
+			Category: category,
+			Name:     name,
+			Start:    t.now(),
+			Args:     argsMap(nil, args),
+		},
+	}
+	t.open[span] = struct{}{}
+	return span
+}
+
+// Events returns the recorded events ordered by start time, followed by the
+// spans that have not ended yet.
+func (t *Timeline) Events() []Event {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make([]Event, len(t.events), len(t.events)+len(t.open))
+	copy(events, t.events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+
+	now := t.now()
+	unfinished := make([]Event, 0, len(t.open))
+	for span := range t.open {
+		event := span.event
+		event.Duration = now.Sub(event.Start)
+		event.Unfinished = true
+		unfinished = append(unfinished, event)
+	}
+	sort.Slice(unfinished, func(i, j int) bool {
+		return unfinished[i].Start.Before(unfinished[j].Start)
+	})
+
+	return append(events, unfinished...)
+}
+
+// Span is an event with a duration that has been started but not yet ended.
+type Span struct {
+	timeline *Timeline
+	event    Event
+}
+
+// End records the span, adding args to those given to Begin. Only the first
+// call has an effect.
+func (s *Span) End(args ...interface{}) {
+	if s == nil {
+		return
+	}
+
+	t := s.timeline
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.open[s]; !ok {
+		return
+	}
+	delete(t.open, s)
+
+	event := s.event
+	event.Duration = t.now().Sub(event.Start)
+	event.Args = argsMap(event.Args, args)
+	t.events = append(t.events, event)
+}
+
+// argsMap adds alternating keys and values to m. Values that do not encode
+// well as JSON, such as durations and errors, are stored as strings.
+func argsMap(m map[string]interface{}, args []interface{}) map[string]interface{} {
+	for i := 0; i+1 < len(args); i += 2 {
+		if m == nil {
+			m = make(map[string]interface{}, len(args)/2)
+		}
+		key := fmt.Sprint(args[i])
+		switch v := args[i+1].(type) {
+		case nil, string, bool, int, int64, uint64, uint32, int32, float64:
+			m[key] = v
+		case error:
+			m[key] = v.Error()
+		default:
+			m[key] = fmt.Sprint(v)
+		}
+	}
+	return m
+}
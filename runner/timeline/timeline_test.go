@@ -0,0 +1,125 @@
+package timeline
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTimeline returns a timeline whose clock advances by one millisecond
+// on every read.
+func newTestTimeline() *Timeline {
+	tl := New("test-run")
+	start := tl.start
+	ticks := 0
+	tl.now = func() time.Time {
+		ticks++
+		return start.Add(time.Duration(ticks) * time.Millisecond)
+	}
+	return tl
+}
+
+func TestNilTimeline(t *testing.T) {
+	var tl *Timeline
+	tl.Instant(TrackRunner, "run", "start")
+	span := tl.Begin(TrackSequencer, "engine", "engine_getPayloadV4")
+	require.Nil(t, span)
+	span.End("number", 1)
+	require.Nil(t, tl.Events())
+	require.NoError(t, tl.WriteFile(path.Join(t.TempDir(), FileName)))
+}
+
+func TestTimelineEvents(t *testing.T) {
+	tl := newTestTimeline()
+
+	block := tl.Begin(TrackSequencer, "block", "block 1", "index", 1)
+	tl.Instant(TrackSequencer, "funding", "funding tx included", "block", uint64(3))
+	fcu := tl.Begin(TrackSequencer, "engine", "engine_forkchoiceUpdatedV3")
+	fcu.End("err", errors.New("timeout"), "latency", 5*time.Millisecond)
+	fcu.End("ignored", true)
+	block.End("txs", 10)
+	tl.Begin(TrackValidator, "engine", "engine_newPayloadV4")
+
+	events := tl.Events()
+	require.Len(t, events, 4)
+
+	require.Equal(t, "block 1", events[0].Name)
+	require.Equal(t, 4*time.Millisecond, events[0].Duration)
+	require.Equal(t, map[string]interface{}{"index": 1, "txs": 10}, events[0].Args)
+
+	require.Equal(t, "funding tx included", events[1].Name)
+	require.True(t, events[1].Instant)
+	require.Equal(t, uint64(3), events[1].Args["block"])
+
+	require.Equal(t, "engine_forkchoiceUpdatedV3", events[2].Name)
+	require.Equal(t, time.Millisecond, events[2].Duration)
+	require.Equal(t, map[string]interface{}{"err": "timeout", "latency": "5ms"}, events[2].Args)
+
+	require.Equal(t, "engine_newPayloadV4", events[3].Name)
+	require.True(t, events[3].Unfinished)
+}
+
+func TestChromeTrace(t *testing.T) {
+	tl := newTestTimeline()
+	tl.Instant(TrackValidator, "flashblocks", "validator connected")
+	span := tl.Begin(TrackSequencer, "engine", "engine_getPayloadV4")
+	span.End("number", 7)
+	tl.Begin(TrackRunner, "node", "stop sequencer")
+
+	filename := path.Join(t.TempDir(), FileName)
+	require.NoError(t, tl.WriteFile(filename))
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	var trace struct {
+		TraceEvents []struct {
+			Name  string                 `json:"name"`
+			Cat   string                 `json:"cat"`
+			Phase string                 `json:"ph"`
+			Ts    float64                `json:"ts"`
+			Dur   *float64               `json:"dur"`
+			Tid   int                    `json:"tid"`
+			Scope string                 `json:"s"`
+			Args  map[string]interface{} `json:"args"`
+		} `json:"traceEvents"`
+		DisplayTimeUnit string                 `json:"displayTimeUnit"`
+		OtherData       map[string]interface{} `json:"otherData"`
+	}
+	require.NoError(t, json.Unmarshal(data, &trace))
+	require.Equal(t, "ms", trace.DisplayTimeUnit)
+	require.Equal(t, "test-run", trace.OtherData["name"])
+
+	threads := make(map[string]int)
+	for _, event := range trace.TraceEvents {
+		if event.Phase == "M" && event.Name == "thread_name" {
+			threads[event.Args["name"].(string)] = event.Tid
+		}
+	}
+	// well-known tracks are ordered runner, sequencer, flashblocks, validator
+	require.Equal(t, map[string]int{TrackRunner: 1, TrackSequencer: 2, TrackValidator: 3}, threads)
+
+	events := trace.TraceEvents[len(trace.TraceEvents)-3:]
+
+	require.Equal(t, "validator connected", events[0].Name)
+	require.Equal(t, "i", events[0].Phase)
+	require.Equal(t, "t", events[0].Scope)
+	require.Equal(t, threads[TrackValidator], events[0].Tid)
+	require.Equal(t, 1000.0, events[0].Ts)
+
+	require.Equal(t, "engine_getPayloadV4", events[1].Name)
+	require.Equal(t, "X", events[1].Phase)
+	require.Equal(t, "engine", events[1].Cat)
+	require.Equal(t, 2000.0, events[1].Ts)
+	require.NotNil(t, events[1].Dur)
+	require.Equal(t, 1000.0, *events[1].Dur)
+	require.Equal(t, 7.0, events[1].Args["number"])
+
+	require.Equal(t, "stop sequencer", events[2].Name)
+	require.Equal(t, true, events[2].Args["unfinished"])
+}